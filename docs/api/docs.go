@@ -15,9 +15,50 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/login": {
+        "/admin/admins": {
+            "get": {
+                "description": "Get every admin with their role, disabled ones included. Owner only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List admins",
+                "responses": {
+                    "200": {
+                        "description": "Admins",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/aroma-hub_internal_models.Admin"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/admins/invites": {
             "post": {
-                "description": "Admin login with OTP code",
+                "description": "Create a one-time Telegram link, valid for 24 hours, that makes whoever opens it an admin with the given role. Owner only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,23 +68,23 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Admin login",
+                "summary": "Invite admin",
                 "parameters": [
                     {
-                        "description": "Admin login information",
+                        "description": "Role of the new admin",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminLoginRequest"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateAdminInviteRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Admin login response",
+                    "201": {
+                        "description": "Invite link",
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminLoginResponse"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminInviteResponse"
                         }
                     },
                     "400": {
@@ -58,6 +99,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -67,84 +114,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/products": {
-            "get": {
-                "description": "Get a list of products with optional filtering (invisible included)",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/admins/{id}": {
+            "delete": {
+                "description": "Delete an admin and their sessions. The last active owner cannot be removed. Owner only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "List products",
+                "summary": "Remove admin",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
+                        "description": "Admin ID",
                         "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Category ID",
-                        "name": "categoryId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Category name",
-                        "name": "categoryName",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Brand name",
-                        "name": "brand",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Product name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Minimum price",
-                        "name": "priceFrom",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum price",
-                        "name": "priceTo",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Minimum stock amount",
-                        "name": "stockAmountFrom",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum stock amount",
-                        "name": "stockAmountTo",
-                        "in": "query"
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "List of products",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/aroma-hub_internal_models.Product"
-                            }
-                        }
+                    "204": {
+                        "description": "Admin removed"
                     },
                     "400": {
                         "description": "Bad request",
@@ -152,6 +143,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Admin not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -161,35 +170,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/refresh": {
-            "get": {
-                "description": "Refresh admin access token",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/admins/{id}/disable": {
+            "put": {
+                "description": "Stop an admin from logging in and end their sessions. The last active owner cannot be disabled. Owner only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Admin refresh token",
+                "summary": "Disable admin",
                 "parameters": [
                     {
-                        "description": "Admin refresh token information",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminRefreshTokenRequest"
-                        }
+                        "type": "string",
+                        "description": "Admin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Admin refresh token response",
+                        "description": "Disabled admin",
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminRefreshTokenResponse"
+                            "$ref": "#/definitions/aroma-hub_internal_models.Admin"
                         }
                     },
                     "400": {
@@ -204,6 +208,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Admin not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -213,53 +229,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/categories": {
-            "get": {
-                "description": "Get a list of categories with optional filtering",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/admins/{id}/enable": {
+            "put": {
+                "description": "Let a disabled admin log in again. Owner only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "admin"
                 ],
-                "summary": "List categories",
+                "summary": "Enable admin",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Category ID",
+                        "description": "Admin ID",
                         "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Category name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit number of results",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of categories",
+                        "description": "Enabled admin",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/aroma-hub_internal_models.Category"
-                            }
+                            "$ref": "#/definitions/aroma-hub_internal_models.Admin"
                         }
                     },
                     "400": {
@@ -268,6 +261,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Admin not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -275,9 +286,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new category",
+            }
+        },
+        "/admin/admins/{id}/role": {
+            "put": {
+                "description": "Change what an admin may do: owner, manager, warehouse or marketing. Owner only. The new role applies once the admin's access token is refreshed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -285,25 +298,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "admin"
                 ],
-                "summary": "Create category",
+                "summary": "Assign admin role",
                 "parameters": [
                     {
-                        "description": "Category information",
+                        "type": "string",
+                        "description": "Admin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateCategoryRequest"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AssignAdminRoleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created category",
+                    "200": {
+                        "description": "Updated admin",
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateCategoryRequest"
+                            "$ref": "#/definitions/aroma-hub_internal_models.Admin"
                         }
                     },
                     "400": {
@@ -312,8 +332,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
-                    "409": {
-                        "description": "Category already exists",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Admin not found",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -327,9 +359,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/categories/{id}": {
-            "delete": {
-                "description": "Delete a category by ID",
+        "/admin/inventory/movements": {
+            "get": {
+                "description": "Get the stock ledger, newest first, optionally for a single product or variant",
                 "consumes": [
                     "application/json"
                 ],
@@ -337,97 +369,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "inventory"
                 ],
-                "summary": "Delete category",
+                "summary": "List inventory movements",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No content"
-                    },
-                    "400": {
-                        "description": "Bad request",
-                        "schema": {
-                            "$ref": "#/definitions/errx.Error"
-                        }
-                    },
-                    "404": {
-                        "description": "Not found",
-                        "schema": {
-                            "$ref": "#/definitions/errx.Error"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/errx.Error"
-                        }
-                    }
-                }
-            }
-        },
-        "/orders": {
-            "get": {
-                "description": "Get a list of orders with optional filtering",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "orders"
-                ],
-                "summary": "List orders",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Payment method (IBAN, сash_on_delivery)",
-                        "name": "paymentMethod",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Contact type (telegram, phone)",
-                        "name": "contactType",
+                        "description": "Product ID",
+                        "name": "productId",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Order status (pending, processing, completed, cancelled)",
-                        "name": "status",
+                        "description": "Variant ID",
+                        "name": "variantId",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start date for filtering (format: YYYY-MM-DD)",
-                        "name": "fromDate",
+                        "description": "Reason (sale, cancellation, adjustment, restock, return)",
+                        "name": "reason",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date for filtering (format: YYYY-MM-DD)",
-                        "name": "toDate",
+                        "description": "Reference ID, e.g. an order ID",
+                        "name": "referenceId",
                         "in": "query"
                     },
                     {
@@ -445,9 +412,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of orders",
+                        "description": "List of inventory movements",
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderResponse"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.ListInventoryMovementsResponse"
                         }
                     },
                     "400": {
@@ -457,7 +424,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "No orders found",
+                        "description": "Not found",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -469,9 +436,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new order",
+            }
+        },
+        "/admin/inventory/reconciliation": {
+            "get": {
+                "description": "Recompute stock from the ledger and report variants whose stock has drifted",
                 "consumes": [
                     "application/json"
                 ],
@@ -479,28 +448,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
-                ],
-                "summary": "Create order",
-                "parameters": [
-                    {
-                        "description": "Order information",
-                        "name": "order",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateOrderRequest"
-                        }
-                    }
+                    "inventory"
                 ],
+                "summary": "Reconcile inventory",
                 "responses": {
-                    "201": {
-                        "description": "Created"
-                    },
-                    "400": {
-                        "description": "Bad request",
+                    "200": {
+                        "description": "Reconciliation report",
                         "schema": {
-                            "$ref": "#/definitions/errx.Error"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.InventoryReconciliationResponse"
                         }
                     },
                     "500": {
@@ -512,9 +467,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}": {
-            "put": {
-                "description": "Update an existing order",
+        "/admin/login": {
+            "post": {
+                "description": "Log in with the Telegram ID and one-time code the bot sends on /login. A code works once; repeated wrong codes lock the admin out for a while.",
                 "consumes": [
                     "application/json"
                 ],
@@ -522,30 +477,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Update order",
+                "summary": "Admin login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Order information",
-                        "name": "order",
+                        "description": "Admin login information",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.UpdateOrderRequest"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminLoginRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Updated"
+                        "description": "Admin login response",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminLoginResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad request",
@@ -553,8 +504,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
-                    "404": {
-                        "description": "Order not found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Locked out after too many failed attempts",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -566,40 +523,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete an order by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/logout": {
+            "post": {
+                "description": "End the session the request is made with. Its refresh token stops working.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
-                ],
-                "summary": "Delete order",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Admin logout",
                 "responses": {
                     "204": {
-                        "description": "No Content"
+                        "description": "Logged out"
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
                     "404": {
-                        "description": "Order not found",
+                        "description": "Session not found",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -613,40 +560,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}/cancel": {
-            "put": {
-                "description": "Cancel an order by ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/logout-all": {
+            "post": {
+                "description": "End every session of the admin, so all refresh tokens stop working",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
-                ],
-                "summary": "Cancel order",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Admin logout from all devices",
                 "responses": {
                     "200": {
-                        "description": "Order cancelled"
-                    },
-                    "400": {
-                        "description": "Bad request",
+                        "description": "Revoked sessions",
                         "schema": {
-                            "$ref": "#/definitions/errx.Error"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminLogoutAllResponse"
                         }
                     },
-                    "404": {
-                        "description": "Order not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -660,9 +592,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
+        "/admin/products": {
             "get": {
-                "description": "Get a list of products with optional filtering",
+                "description": "Get a list of products with optional filtering (invisible included)",
                 "consumes": [
                     "application/json"
                 ],
@@ -670,7 +602,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
                 "summary": "List products",
                 "parameters": [
@@ -752,39 +684,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/refresh": {
             "post": {
-                "description": "Add a new product to the inventory",
+                "description": "Exchange a refresh token for a new access and refresh token pair. Every refresh token works once; using one again revokes its session.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Create product",
+                "summary": "Admin refresh token",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Product image file",
-                        "name": "image",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Product information in JSON format",
-                        "name": "data",
-                        "in": "formData",
-                        "required": true
+                        "description": "Admin refresh token information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminRefreshTokenRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created successfully",
+                    "200": {
+                        "description": "Admin refresh token response",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.AdminRefreshTokenResponse"
                         }
                     },
                     "400": {
@@ -793,6 +723,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -802,9 +738,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/best-sellers": {
+        "/categories": {
             "get": {
-                "description": "Get a list of best-selling products",
+                "description": "Get a list of categories with optional filtering",
                 "consumes": [
                     "application/json"
                 ],
@@ -812,10 +748,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
                 ],
-                "summary": "Get best sellers",
+                "summary": "List categories",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category name",
+                        "name": "name",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Limit number of results",
@@ -831,11 +779,11 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of best-selling products",
+                        "description": "List of categories",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/aroma-hub_internal_models.Product"
+                                "$ref": "#/definitions/aroma-hub_internal_models.Category"
                             }
                         }
                     },
@@ -852,11 +800,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/products/brands": {
-            "get": {
-                "description": "Get a list of product brands",
+            },
+            "post": {
+                "description": "Create a new category",
                 "consumes": [
                     "application/json"
                 ],
@@ -864,14 +810,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
+                ],
+                "summary": "Create category",
+                "parameters": [
+                    {
+                        "description": "Category information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateCategoryRequest"
+                        }
+                    }
                 ],
-                "summary": "List brands",
                 "responses": {
-                    "200": {
-                        "description": "List of brands",
+                    "201": {
+                        "description": "Created category",
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.BrandResponse"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateCategoryRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Category already exists",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
                         }
                     },
                     "500": {
@@ -883,9 +852,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}": {
+        "/categories/{id}": {
             "delete": {
-                "description": "Remove a product from the inventory",
+                "description": "Delete a category by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -893,13 +862,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
                 ],
-                "summary": "Delete product",
+                "summary": "Delete category",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -907,7 +876,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content"
+                        "description": "No content"
                     },
                     "400": {
                         "description": "Bad request",
@@ -928,40 +897,33 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update a product in the inventory",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/delivery/cities": {
+            "get": {
+                "description": "Find cities the carrier delivers to, for picking a delivery point at checkout",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "delivery"
                 ],
-                "summary": "Update product",
+                "summary": "Search delivery cities",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Part of the city name, at least 2 characters",
+                        "name": "query",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "description": "Product information",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.UpdateProductRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Matching cities",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.DeliveryCitiesResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad request",
@@ -969,8 +931,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
-                    "404": {
-                        "description": "Not found",
+                    "429": {
+                        "description": "Too many requests",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -984,38 +946,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}/set-image": {
-            "patch": {
-                "description": "Set the image of a product in the inventory",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/delivery/warehouses": {
+            "get": {
+                "description": "List the carrier's branches in a city, optionally filtered by number or address",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "delivery"
                 ],
-                "summary": "Set product image",
+                "summary": "List delivery warehouses",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "City reference from the city search",
+                        "name": "cityRef",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Product image file",
-                        "name": "image",
-                        "in": "formData",
-                        "required": true
+                        "type": "string",
+                        "description": "Branch number or part of its address",
+                        "name": "query",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Branches",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.DeliveryWarehousesResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad request",
@@ -1023,8 +984,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
-                    "404": {
-                        "description": "Not found",
+                    "429": {
+                        "description": "Too many requests",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -1038,9 +999,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/promocodes": {
+        "/orders": {
             "get": {
-                "description": "Get a list of promocodes with optional filtering",
+                "description": "Get a list of orders with optional filtering",
                 "consumes": [
                     "application/json"
                 ],
@@ -1048,38 +1009,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "promocodes"
+                    "orders"
                 ],
-                "summary": "List promocodes",
+                "summary": "List orders",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Promocode ID",
+                        "description": "Order ID",
                         "name": "id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Promocode code",
-                        "name": "code",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Minimum discount percentage",
-                        "name": "discountFrom",
+                        "type": "string",
+                        "description": "Payment method (IBAN, сash_on_delivery, card)",
+                        "name": "paymentMethod",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Maximum discount percentage",
-                        "name": "discountTo",
+                        "type": "string",
+                        "description": "Payment status (unpaid, paid, refunded, failed)",
+                        "name": "paymentStatus",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "Filter for active promocodes (not expired)",
-                        "name": "active",
+                        "type": "string",
+                        "description": "Contact type (telegram, phone)",
+                        "name": "contactType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order status (pending, confirmed, processing, shipped, delivered, completed, cancelled, returned, partially_returned)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for filtering (format: YYYY-MM-DD)",
+                        "name": "fromDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for filtering (format: YYYY-MM-DD)",
+                        "name": "toDate",
                         "in": "query"
                     },
                     {
@@ -1097,9 +1076,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of promocodes",
+                        "description": "List of orders",
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.ListPromocodesResponse"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderResponse"
                         }
                     },
                     "400": {
@@ -1109,7 +1088,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "No promocodes found",
+                        "description": "No orders found",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -1123,7 +1102,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new promocode with discount",
+                "description": "Create a new order. Retries that send the same Idempotency-Key and body get the original response without placing a second order. Card orders come back with a paymentUrl to send the customer to.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1131,32 +1110,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "promocodes"
+                    "orders"
                 ],
-                "summary": "Create promocode",
+                "summary": "Create order",
                 "parameters": [
                     {
-                        "description": "Promocode information",
-                        "name": "promocode",
+                        "type": "string",
+                        "description": "Client-generated key that deduplicates retries for 24 hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Order information",
+                        "name": "order",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreatePromocodeRequest"
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateOrderRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created successfully"
+                        "description": "Created order",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.Order"
+                        }
                     },
                     "400": {
-                        "description": "Validation error",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
                     },
                     "409": {
-                        "description": "Promocode already exists",
+                        "description": "Idempotency-Key reused with a different body",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -1170,9 +1158,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/promocodes/{id}": {
-            "delete": {
-                "description": "Delete a promocode by its ID",
+        "/orders/lookup": {
+            "post": {
+                "description": "Get an order by its ID and the phone number it was placed with",
                 "consumes": [
                     "application/json"
                 ],
@@ -1180,22 +1168,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "promocodes"
+                    "orders"
                 ],
-                "summary": "Delete promocode",
+                "summary": "Look up order",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Promocode ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Order ID and phone number",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.LookupOrderRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Order",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.Order"
+                        }
                     },
                     "400": {
                         "description": "Bad request",
@@ -1204,7 +1196,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Promocode not found",
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
                         "schema": {
                             "$ref": "#/definitions/errx.Error"
                         }
@@ -1217,369 +1215,3267 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "aroma-hub_internal_application_dto.AdminLoginRequest": {
-            "type": "object",
-            "required": [
-                "otp"
-            ],
-            "properties": {
-                "otp": {
-                    "type": "string"
+        },
+        "/orders/track/{token}": {
+            "get": {
+                "description": "Follow an order by the tracking token handed out when it was placed. Shows the status history, items and shipment tracking number, without the customer's contact details.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Track order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tracking token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order progress",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderTrackingResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tracking token is invalid or has been revoked",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
                 }
             }
         },
-        "aroma-hub_internal_application_dto.AdminLoginResponse": {
-            "type": "object",
-            "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
-                "refreshToken": {
-                    "type": "string"
+        "/orders/track/{token}/invoice": {
+            "get": {
+                "description": "Let a customer download the PDF invoice of an IBAN order using its tracking token",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Download invoice by tracking token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tracking token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF invoice",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Order is not paid by IBAN",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Tracking token is invalid or has been revoked",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "put": {
+                "description": "Update an existing order. A status change must follow the allowed transitions and is recorded in the order history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order information",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.UpdateOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an order by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Delete order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/cancel": {
+            "put": {
+                "description": "Cancel an order that has not been shipped yet, returning its stock and promocode",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional comment",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CancelOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order cancelled"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/history": {
+            "get": {
+                "description": "Get every status change of an order with its actor, time and comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Order status history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status history",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderStatusHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice": {
+            "get": {
+                "description": "Get the bank transfer invoice of an IBAN order: merchant requisites, items, totals and the payment purpose",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Order invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invoice",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderInvoice"
+                        }
+                    },
+                    "400": {
+                        "description": "Order is not paid by IBAN",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice/paid": {
+            "post": {
+                "description": "Record that the bank transfer for an IBAN order has arrived. A pending order is confirmed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Mark invoice paid",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional comment",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.MarkOrderPaidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Recorded payment",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Order is already paid",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice/pdf": {
+            "get": {
+                "description": "Download the bank transfer invoice of an IBAN order as PDF",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Download order invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF invoice",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Order is not paid by IBAN",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice/send": {
+            "post": {
+                "description": "Post the payment instructions of an IBAN order to the admins' Telegram chat to forward to the customer",
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Send order invoice to Telegram",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Order is not paid by IBAN",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/items": {
+            "put": {
+                "description": "Replace the items of an unpaid order that has not shipped yet. Items already in the order keep their price, stock is adjusted by the difference and the promocode discount and amount to pay are recalculated. An open card checkout page is voided and the response carries a new one as paymentUrl.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New order items",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.UpdateOrderItemsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated order",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order or product not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/payment": {
+            "post": {
+                "description": "Create a new checkout page for an unpaid order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Issue payment link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "New payment",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Order is already paid",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/refund": {
+            "post": {
+                "description": "Return the paid amount of an order to the customer's card",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Refund order payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Refunded payment",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Order has no completed payment",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/returns": {
+            "get": {
+                "description": "Get every return recorded for an order with its items and refund",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List order returns",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.ListOrderReturnsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Record items a customer sent back from a shipped order. Items can be restocked, and the refund is sent through the payment provider when the order was paid online. The order becomes partially_returned or returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create order return",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Returned items and refund",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateOrderReturnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Recorded return",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderReturnResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/tracking-token": {
+            "post": {
+                "description": "Give an order a new tracking token. The previous token stops working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Reissue tracking token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New tracking token",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderTrackingTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Disable tracking for an order until a new token is issued",
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Revoke tracking token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/waybill": {
+            "post": {
+                "description": "Register the order's parcel with the carrier. The waybill number is stored on the order and its tracking moves the order to shipped and delivered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create waybill",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parcel details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateWaybillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created waybill",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.WaybillResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Order already has a waybill",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/webhook": {
+            "post": {
+                "description": "Receives payment status notifications from the payment provider. The body must be signed in the X-Sign header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Payment webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider signature of the body",
+                        "name": "X-Sign",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Get a list of products with optional filtering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "categoryId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category name",
+                        "name": "categoryName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Brand name",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price",
+                        "name": "priceFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price",
+                        "name": "priceTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum stock amount",
+                        "name": "stockAmountFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum stock amount",
+                        "name": "stockAmountTo",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of products",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/aroma-hub_internal_models.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new product to the inventory",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create product",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Product image file",
+                        "name": "image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product information in JSON format",
+                        "name": "data",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/best-sellers": {
+            "get": {
+                "description": "Get a list of best-selling products",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get best sellers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of best-selling products",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/aroma-hub_internal_models.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/brands": {
+            "get": {
+                "description": "Get a list of product brands",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List brands",
+                "responses": {
+                    "200": {
+                        "description": "List of brands",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.BrandResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "delete": {
+                "description": "Remove a product from the inventory",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a product in the inventory",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product information",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.UpdateProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/set-image": {
+            "patch": {
+                "description": "Set the image of a product in the inventory",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Set product image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Product image file",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants": {
+            "post": {
+                "description": "Add a sellable variant (a bottle size or a pre-filled decant) to a product. Volume 0 creates the decant poured to order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create product variant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variant information",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreateProductVariantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU already exists",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants/{variantId}": {
+            "delete": {
+                "description": "Remove a variant that has never been ordered. The default decant variant cannot be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete product variant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Variant ID",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Variant has orders",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update the SKU, price, stock or visibility of a product variant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update product variant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Variant ID",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variant information",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.UpdateProductVariantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU already exists",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/promocodes": {
+            "get": {
+                "description": "Get a list of promocodes with optional filtering, including used and remaining redemption counts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promocodes"
+                ],
+                "summary": "List promocodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promocode ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Promocode code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Campaign name",
+                        "name": "campaign",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Discount type (percent, fixed)",
+                        "name": "discountType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum discount value",
+                        "name": "discountFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum discount value",
+                        "name": "discountTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter for active promocodes (not expired)",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of promocodes",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.ListPromocodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "No promocodes found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new percent or fixed-amount promocode, optionally limited to categories, brands or products",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promocodes"
+                ],
+                "summary": "Create promocode",
+                "parameters": [
+                    {
+                        "description": "Promocode information",
+                        "name": "promocode",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.CreatePromocodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created successfully"
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Promocode already exists",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/promocodes/batch": {
+            "post": {
+                "description": "Generate a batch of unique single-use promocodes that share a campaign, discount and expiry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promocodes"
+                ],
+                "summary": "Generate promocode batch",
+                "parameters": [
+                    {
+                        "description": "Batch parameters",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.GeneratePromocodeBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Generated codes",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.PromocodeBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/promocodes/export": {
+            "get": {
+                "description": "Download the promocodes of a campaign as CSV",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "promocodes"
+                ],
+                "summary": "Export promocodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign name",
+                        "name": "campaign",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "No promocodes found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/promocodes/preview": {
+            "post": {
+                "description": "Check a promocode against a cart and get the discounted total without placing an order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promocodes"
+                ],
+                "summary": "Preview promocode",
+                "parameters": [
+                    {
+                        "description": "Promocode and cart",
+                        "name": "preview",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.PreviewPromocodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preview result",
+                        "schema": {
+                            "$ref": "#/definitions/aroma-hub_internal_application_dto.PromocodePreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/promocodes/{id}": {
+            "delete": {
+                "description": "Delete a promocode by its ID. It can no longer be used, but orders placed with it keep their discount",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promocodes"
+                ],
+                "summary": "Delete promocode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Promocode ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Promocode not found",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errx.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "aroma-hub_internal_application_dto.AdminInviteResponse": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.AdminRole"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.AdminLoginRequest": {
+            "type": "object",
+            "required": [
+                "otp",
+                "vendorId"
+            ],
+            "properties": {
+                "otp": {
+                    "type": "string"
+                },
+                "vendorId": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.AdminLoginResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.AdminLogoutAllResponse": {
+            "type": "object",
+            "properties": {
+                "revokedSessions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.AdminRefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.AdminRefreshTokenResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.AssignAdminRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.AdminRole"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.BrandResponse": {
+            "type": "object",
+            "properties": {
+                "brands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CancelOrderRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreateAdminInviteRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.AdminRole"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreateCategoryRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreateOrderRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "contactType",
+                "fullName",
+                "paymentMethod",
+                "phoneNumber",
+                "productItems"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "contactType": {
+                    "enum": [
+                        "telegram",
+                        "phone"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/aroma-hub_internal_models.ContactType"
+                        }
+                    ]
+                },
+                "deliveryCityRef": {
+                    "description": "DeliveryCityRef and DeliveryWarehouseRef pick a carrier branch from\nthe delivery lookups. Address stays as the human-readable copy.",
+                    "type": "string"
+                },
+                "deliveryWarehouseRef": {
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "paymentMethod": {
+                    "enum": [
+                        "IBAN",
+                        "сash_on_delivery",
+                        "card"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/aroma-hub_internal_models.PaymentMethod"
+                        }
+                    ]
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "productItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
+                    }
+                },
+                "promoCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreateOrderReturnRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderReturnItemRequest"
+                    }
+                },
+                "refundAmount": {
+                    "type": "integer"
+                },
+                "restock": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreateProductVariantRequest": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "stockAmount": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreatePromocodeRequest": {
+            "type": "object",
+            "properties": {
+                "brands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "campaign": {
+                    "type": "string"
+                },
+                "categoryIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code": {
+                    "type": "string"
+                },
+                "discount": {
+                    "type": "integer"
+                },
+                "discountType": {
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/aroma-hub_internal_models.DiscountType"
+                        }
+                    ]
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "maxRedemptions": {
+                    "type": "integer"
+                },
+                "minOrderAmount": {
+                    "type": "integer"
+                },
+                "perCustomerLimit": {
+                    "type": "integer"
+                },
+                "productIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "startsAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.CreateWaybillRequest": {
+            "type": "object",
+            "required": [
+                "weight"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "seatsAmount": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.DeliveryCitiesResponse": {
+            "type": "object",
+            "properties": {
+                "cities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.DeliveryCity"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.DeliveryWarehousesResponse": {
+            "type": "object",
+            "properties": {
+                "warehouses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.DeliveryWarehouse"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.GeneratePromocodeBatchRequest": {
+            "type": "object",
+            "required": [
+                "campaign",
+                "count"
+            ],
+            "properties": {
+                "alphabet": {
+                    "type": "string"
+                },
+                "brands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "campaign": {
+                    "type": "string"
+                },
+                "categoryIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "discount": {
+                    "type": "integer"
+                },
+                "discountType": {
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/aroma-hub_internal_models.DiscountType"
+                        }
+                    ]
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "integer"
+                },
+                "minOrderAmount": {
+                    "type": "integer"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "productIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "startsAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.InventoryReconciliationResponse": {
+            "type": "object",
+            "properties": {
+                "consistent": {
+                    "type": "boolean"
+                },
+                "drifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.InventoryDrift"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.ListInventoryMovementsResponse": {
+            "type": "object",
+            "properties": {
+                "movements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.InventoryMovement"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.ListOrderReturnsResponse": {
+            "type": "object",
+            "properties": {
+                "returns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.OrderReturn"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.ListPromocodesResponse": {
+            "type": "object",
+            "properties": {
+                "promocodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.Promocode"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.LookupOrderRequest": {
+            "type": "object",
+            "required": [
+                "orderId",
+                "phoneNumber"
+            ],
+            "properties": {
+                "orderId": {
+                    "type": "string"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.MarkOrderPaidRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.MerchantRequisites": {
+            "type": "object",
+            "properties": {
+                "bank": {
+                    "type": "string"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "taxId": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.Order": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "amountToPay": {
+                    "type": "integer"
+                },
+                "contactType": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.ContactType"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "delivery": {
+                    "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderDelivery"
+                },
+                "discountAmount": {
+                    "type": "integer"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "paymentMethod": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentMethod"
+                },
+                "paymentStatus": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentStatus"
+                },
+                "paymentUrl": {
+                    "type": "string"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
+                    }
+                },
+                "promoCode": {
+                    "type": "string"
+                },
+                "refundedAmount": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "trackingNumber": {
+                    "type": "string"
+                },
+                "trackingToken": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderDelivery": {
+            "type": "object",
+            "properties": {
+                "carrier": {
+                    "type": "string"
+                },
+                "cityRef": {
+                    "type": "string"
+                },
+                "warehouseRef": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderInvoice": {
+            "type": "object",
+            "properties": {
+                "amountToPay": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "discountAmount": {
+                    "type": "integer"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderInvoiceItem"
+                    }
+                },
+                "merchant": {
+                    "$ref": "#/definitions/aroma-hub_internal_application_dto.MerchantRequisites"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "orderId": {
+                    "type": "string"
+                },
+                "paymentPurpose": {
+                    "type": "string"
+                },
+                "paymentStatus": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentStatus"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "subtotal": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderInvoiceItem": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "lineTotal": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "unitPrice": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "payment": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.Payment"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.Order"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderReturnItemRequest": {
+            "type": "object",
+            "required": [
+                "quantity",
+                "variantId",
+                "volume"
+            ],
+            "properties": {
+                "quantity": {
+                    "type": "integer"
+                },
+                "variantId": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderReturnResponse": {
+            "type": "object",
+            "properties": {
+                "refund": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentRefund"
+                },
+                "return": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderReturn"
+                },
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderStatusHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.OrderStatusHistory"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderTrackingEvent": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderTrackingResponse": {
+            "type": "object",
+            "properties": {
+                "amountToPay": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "discountAmount": {
+                    "type": "integer"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.OrderTrackingEvent"
+                    }
+                },
+                "paymentStatus": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentStatus"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "trackingNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.OrderTrackingTokenResponse": {
+            "type": "object",
+            "properties": {
+                "trackingToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.PreviewPromocodeRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "productItems"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "productItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.ProductOrder": {
+            "type": "object",
+            "required": [
+                "brand",
+                "id",
+                "name",
+                "price",
+                "quantity",
+                "volume"
+            ],
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lineTotal": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "returnedQuantity": {
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "variantId": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "integer"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.AdminRefreshTokenRequest": {
+        "aroma-hub_internal_application_dto.PromocodeBatchResponse": {
             "type": "object",
-            "required": [
-                "refreshToken"
-            ],
             "properties": {
-                "refreshToken": {
+                "campaign": {
                     "type": "string"
+                },
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "aroma-hub_internal_application_dto.AdminRefreshTokenResponse": {
+        "aroma-hub_internal_application_dto.PromocodePreviewResponse": {
             "type": "object",
             "properties": {
-                "accessToken": {
+                "amountToPay": {
+                    "type": "integer"
+                },
+                "code": {
                     "type": "string"
                 },
-                "refreshToken": {
+                "discountAmount": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.BrandResponse": {
+        "aroma-hub_internal_application_dto.UpdateOrderItemsRequest": {
             "type": "object",
+            "required": [
+                "items"
+            ],
             "properties": {
-                "brands": {
+                "items": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
                     }
                 }
             }
         },
-        "aroma-hub_internal_application_dto.CreateCategoryRequest": {
+        "aroma-hub_internal_application_dto.UpdateOrderRequest": {
             "type": "object",
             "required": [
-                "name"
+                "id"
             ],
             "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "paymentMethod": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentMethod"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
+                },
+                "trackingNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.UpdateProductRequest": {
+            "type": "object",
+            "properties": {
+                "bottleFee": {
+                    "type": "number"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "categoryName": {
+                    "type": "string"
+                },
+                "characteristics": {
+                    "type": "string"
+                },
+                "composition": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "hide": {
+                    "type": "boolean"
+                },
+                "imageUrl": {
+                    "type": "string"
+                },
+                "makeVisible": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "pricePerMl": {
+                    "type": "number"
+                },
+                "setBestSeller": {
+                    "type": "boolean"
+                },
+                "unsetBestSeller": {
+                    "type": "boolean"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.CreateOrderRequest": {
+        "aroma-hub_internal_application_dto.UpdateProductVariantRequest": {
             "type": "object",
-            "required": [
-                "address",
-                "contactType",
-                "fullName",
-                "paymentMethod",
-                "phoneNumber",
-                "productItems"
+            "properties": {
+                "hide": {
+                    "type": "boolean"
+                },
+                "makeVisible": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "stockAmount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_application_dto.WaybillResponse": {
+            "type": "object",
+            "properties": {
+                "carrier": {
+                    "type": "string"
+                },
+                "orderId": {
+                    "type": "string"
+                },
+                "waybill": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.Waybill"
+                }
+            }
+        },
+        "aroma-hub_internal_models.ActorType": {
+            "type": "string",
+            "enum": [
+                "customer",
+                "admin",
+                "system"
             ],
+            "x-enum-varnames": [
+                "ActorTypeCustomer",
+                "ActorTypeAdmin",
+                "ActorTypeSystem"
+            ]
+        },
+        "aroma-hub_internal_models.Admin": {
+            "type": "object",
             "properties": {
-                "address": {
+                "createdAt": {
                     "type": "string"
                 },
-                "contactType": {
-                    "enum": [
-                        "telegram",
-                        "phone"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/aroma-hub_internal_models.ContactType"
-                        }
-                    ]
+                "disabledAt": {
+                    "type": "string"
                 },
-                "fullName": {
+                "id": {
                     "type": "string"
                 },
-                "paymentMethod": {
-                    "enum": [
-                        "IBAN",
-                        "сash_on_delivery"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/aroma-hub_internal_models.PaymentMethod"
-                        }
-                    ]
+                "role": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.AdminRole"
                 },
-                "phoneNumber": {
+                "updatedAt": {
                     "type": "string"
                 },
-                "productItems": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
-                    }
+                "vendorId": {
+                    "type": "string"
                 },
-                "promoCode": {
+                "vendorType": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.Vendor"
+                }
+            }
+        },
+        "aroma-hub_internal_models.AdminRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "manager",
+                "warehouse",
+                "marketing"
+            ],
+            "x-enum-varnames": [
+                "AdminRoleOwner",
+                "AdminRoleManager",
+                "AdminRoleWarehouse",
+                "AdminRoleMarketing"
+            ]
+        },
+        "aroma-hub_internal_models.Category": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.CreatePromocodeRequest": {
+        "aroma-hub_internal_models.ContactType": {
+            "type": "string",
+            "enum": [
+                "telegram",
+                "phone",
+                "dont_disturb"
+            ],
+            "x-enum-varnames": [
+                "ContactTypeTelegram",
+                "ContactTypePhone",
+                "ContactDontDisturb"
+            ]
+        },
+        "aroma-hub_internal_models.DeliveryCity": {
             "type": "object",
             "properties": {
-                "code": {
+                "name": {
                     "type": "string"
                 },
-                "discount": {
-                    "type": "integer"
+                "ref": {
+                    "type": "string"
                 },
-                "expiresAt": {
+                "region": {
                     "type": "string"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.ListPromocodesResponse": {
+        "aroma-hub_internal_models.DeliveryWarehouse": {
             "type": "object",
             "properties": {
-                "promocodes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/aroma-hub_internal_models.Promocode"
-                    }
+                "cityRef": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "ref": {
+                    "type": "string"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.Order": {
+        "aroma-hub_internal_models.DiscountType": {
+            "type": "string",
+            "enum": [
+                "percent",
+                "fixed"
+            ],
+            "x-enum-varnames": [
+                "DiscountTypePercent",
+                "DiscountTypeFixed"
+            ]
+        },
+        "aroma-hub_internal_models.InventoryDrift": {
             "type": "object",
             "properties": {
-                "address": {
+                "drift": {
+                    "type": "integer"
+                },
+                "ledgerAmount": {
+                    "type": "integer"
+                },
+                "productId": {
                     "type": "string"
                 },
-                "amountToPay": {
+                "sku": {
+                    "type": "string"
+                },
+                "stockAmount": {
                     "type": "integer"
                 },
-                "contactType": {
-                    "$ref": "#/definitions/aroma-hub_internal_models.ContactType"
+                "variantId": {
+                    "type": "string"
+                }
+            }
+        },
+        "aroma-hub_internal_models.InventoryMovement": {
+            "type": "object",
+            "properties": {
+                "adminId": {
+                    "type": "string"
                 },
                 "createdAt": {
                     "type": "string"
                 },
-                "fullName": {
-                    "type": "string"
+                "delta": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "string"
                 },
-                "paymentMethod": {
-                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentMethod"
-                },
-                "phoneNumber": {
+                "productId": {
                     "type": "string"
                 },
-                "products": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/aroma-hub_internal_application_dto.ProductOrder"
-                    }
+                "reason": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.MovementReason"
                 },
-                "status": {
-                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
+                "referenceId": {
+                    "type": "string"
                 },
-                "updatedAt": {
+                "variantId": {
                     "type": "string"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.OrderResponse": {
-            "type": "object",
-            "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "orders": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/aroma-hub_internal_application_dto.Order"
-                    }
-                }
-            }
+        "aroma-hub_internal_models.MovementReason": {
+            "type": "string",
+            "enum": [
+                "sale",
+                "cancellation",
+                "adjustment",
+                "restock",
+                "return"
+            ],
+            "x-enum-varnames": [
+                "MovementReasonSale",
+                "MovementReasonCancellation",
+                "MovementReasonAdjustment",
+                "MovementReasonRestock",
+                "MovementReasonReturn"
+            ]
         },
-        "aroma-hub_internal_application_dto.ProductOrder": {
+        "aroma-hub_internal_models.OrderReturn": {
             "type": "object",
-            "required": [
-                "brand",
-                "id",
-                "name",
-                "price",
-                "quantity",
-                "volume"
-            ],
             "properties": {
-                "brand": {
+                "adminId": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "createdAt": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.OrderReturnItem"
+                    }
+                },
+                "orderId": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "refundAmount": {
+                    "type": "number"
                 },
+                "restock": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "aroma-hub_internal_models.OrderReturnItem": {
+            "type": "object",
+            "properties": {
                 "quantity": {
                     "type": "integer"
                 },
+                "variantId": {
+                    "type": "string"
+                },
                 "volume": {
                     "type": "integer"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.UpdateOrderRequest": {
-            "type": "object",
-            "required": [
-                "id"
+        "aroma-hub_internal_models.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed",
+                "processing",
+                "shipped",
+                "delivered",
+                "completed",
+                "cancelled",
+                "returned",
+                "partially_returned"
             ],
+            "x-enum-varnames": [
+                "OrderStatusPending",
+                "OrderStatusConfirmed",
+                "OrderStatusProcessing",
+                "OrderStatusShipped",
+                "OrderStatusDelivered",
+                "OrderStatusCompleted",
+                "OrderStatusCancelled",
+                "OrderStatusReturned",
+                "OrderStatusPartiallyReturned"
+            ]
+        },
+        "aroma-hub_internal_models.OrderStatusHistory": {
+            "type": "object",
             "properties": {
-                "address": {
+                "actorId": {
                     "type": "string"
                 },
-                "fullName": {
+                "actorType": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.ActorType"
+                },
+                "comment": {
                     "type": "string"
                 },
-                "id": {
+                "createdAt": {
                     "type": "string"
                 },
-                "paymentMethod": {
-                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentMethod"
+                "fromStatus": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
                 },
-                "phoneNumber": {
+                "id": {
                     "type": "string"
                 },
-                "status": {
+                "orderId": {
+                    "type": "string"
+                },
+                "toStatus": {
                     "$ref": "#/definitions/aroma-hub_internal_models.OrderStatus"
                 }
             }
         },
-        "aroma-hub_internal_application_dto.UpdateProductRequest": {
+        "aroma-hub_internal_models.Payment": {
             "type": "object",
             "properties": {
-                "brand": {
-                    "type": "string"
+                "amount": {
+                    "type": "number"
                 },
-                "categoryName": {
+                "checkoutUrl": {
                     "type": "string"
                 },
-                "characteristics": {
+                "createdAt": {
                     "type": "string"
                 },
-                "composition": {
+                "id": {
                     "type": "string"
                 },
-                "description": {
+                "invoiceId": {
                     "type": "string"
                 },
-                "hide": {
-                    "type": "boolean"
-                },
-                "imageUrl": {
+                "orderId": {
                     "type": "string"
                 },
-                "makeVisible": {
-                    "type": "boolean"
-                },
-                "name": {
+                "provider": {
                     "type": "string"
                 },
-                "price": {
+                "refundedAmount": {
                     "type": "number"
                 },
-                "setBestSeller": {
-                    "type": "boolean"
-                },
-                "stockAmount": {
-                    "type": "integer"
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentStatus"
                 },
-                "unsetBestSeller": {
-                    "type": "boolean"
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "aroma-hub_internal_models.Category": {
+        "aroma-hub_internal_models.PaymentMethod": {
+            "type": "string",
+            "enum": [
+                "IBAN",
+                "сash_on_delivery",
+                "card"
+            ],
+            "x-enum-varnames": [
+                "PaymentMethodIBAN",
+                "PaymentMethodCashOnDelivery",
+                "PaymentMethodCard"
+            ]
+        },
+        "aroma-hub_internal_models.PaymentRefund": {
             "type": "object",
             "properties": {
+                "actorId": {
+                    "type": "string"
+                },
+                "actorType": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.ActorType"
+                },
+                "amount": {
+                    "type": "number"
+                },
                 "createdAt": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "orderId": {
+                    "type": "string"
+                },
+                "paymentId": {
+                    "type": "string"
+                },
+                "returnId": {
                     "type": "string"
                 },
+                "status": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PaymentRefundStatus"
+                },
                 "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "aroma-hub_internal_models.ContactType": {
-            "type": "string",
-            "enum": [
-                "telegram",
-                "phone"
-            ],
-            "x-enum-varnames": [
-                "ContactTypeTelegram",
-                "ContactTypePhone"
-            ]
-        },
-        "aroma-hub_internal_models.OrderStatus": {
+        "aroma-hub_internal_models.PaymentRefundStatus": {
             "type": "string",
             "enum": [
                 "pending",
-                "processing",
-                "completed",
-                "cancelled"
+                "succeeded",
+                "failed"
             ],
             "x-enum-varnames": [
-                "OrderStatusPending",
-                "OrderStatusProcessing",
-                "OrderStatusCompleted",
-                "OrderStatusCancelled"
+                "PaymentRefundStatusPending",
+                "PaymentRefundStatusSucceeded",
+                "PaymentRefundStatusFailed"
             ]
         },
-        "aroma-hub_internal_models.PaymentMethod": {
+        "aroma-hub_internal_models.PaymentStatus": {
             "type": "string",
             "enum": [
-                "IBAN",
-                "сash_on_delivery"
+                "unpaid",
+                "paid",
+                "refunded",
+                "failed",
+                "cancelled"
             ],
             "x-enum-varnames": [
-                "PaymentMethodIBAN",
-                "PaymentMethodCashOnDelivery"
+                "PaymentStatusUnpaid",
+                "PaymentStatusPaid",
+                "PaymentStatusRefunded",
+                "PaymentStatusFailed",
+                "PaymentStatusCancelled"
             ]
         },
         "aroma-hub_internal_models.Product": {
             "type": "object",
             "properties": {
+                "bottleFee": {
+                    "type": "number"
+                },
                 "brand": {
                     "type": "string"
                 },
@@ -1613,6 +4509,44 @@ const docTemplate = `{
                 "price": {
                     "type": "number"
                 },
+                "pricePerMl": {
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/aroma-hub_internal_models.ProductVariant"
+                    }
+                },
+                "visible": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "aroma-hub_internal_models.ProductVariant": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isDefault": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "sku": {
+                    "type": "string"
+                },
                 "stockAmount": {
                     "type": "integer"
                 },
@@ -1621,12 +4555,18 @@ const docTemplate = `{
                 },
                 "visible": {
                     "type": "boolean"
+                },
+                "volume": {
+                    "type": "integer"
                 }
             }
         },
         "aroma-hub_internal_models.Promocode": {
             "type": "object",
             "properties": {
+                "campaign": {
+                    "type": "string"
+                },
                 "code": {
                     "type": "string"
                 },
@@ -1636,14 +4576,87 @@ const docTemplate = `{
                 "discount": {
                     "type": "integer"
                 },
+                "discountType": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.DiscountType"
+                },
                 "expiresAt": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "maxRedemptions": {
+                    "type": "integer"
+                },
+                "minOrderAmount": {
+                    "type": "number"
+                },
+                "perCustomerLimit": {
+                    "type": "integer"
+                },
+                "remainingCount": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "$ref": "#/definitions/aroma-hub_internal_models.PromocodeScope"
+                },
+                "startsAt": {
+                    "type": "string"
+                },
                 "updatedAt": {
                     "type": "string"
+                },
+                "usedCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "aroma-hub_internal_models.PromocodeScope": {
+            "type": "object",
+            "properties": {
+                "brands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "categoryIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "productIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "aroma-hub_internal_models.Vendor": {
+            "type": "string",
+            "enum": [
+                "telegram"
+            ],
+            "x-enum-varnames": [
+                "VendorTelegram"
+            ]
+        },
+        "aroma-hub_internal_models.Waybill": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "estimatedDeliveryDate": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "ref": {
+                    "type": "string"
                 }
             }
         },
@@ -10,22 +10,28 @@ import (
 	"time"
 
 	_ "aroma-hub/docs/api"
+	"aroma-hub/internal/application/dto"
 	"aroma-hub/internal/application/service"
 	"aroma-hub/internal/config"
 	v1 "aroma-hub/internal/controller/http/v1"
+	fakecarrier "aroma-hub/internal/infrastructure/adapters/delivery/fake"
+	"aroma-hub/internal/infrastructure/adapters/delivery/novaposhta"
+	"aroma-hub/internal/infrastructure/adapters/invoice"
 	"aroma-hub/internal/infrastructure/adapters/messaging/telegram"
+	fakepayment "aroma-hub/internal/infrastructure/adapters/payment/fake"
+	"aroma-hub/internal/infrastructure/adapters/payment/monobank"
 	"aroma-hub/internal/infrastructure/adapters/storage"
 	"aroma-hub/internal/infrastructure/workers"
+	"aroma-hub/internal/models"
 	"aroma-hub/pkg/auth"
 	"aroma-hub/pkg/client/db/minio_s3"
 	"aroma-hub/pkg/client/db/pgsql"
 	"aroma-hub/pkg/otp_generator"
+	"aroma-hub/pkg/otp_store"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 	pgxtransactor "github.com/nordew/pgx-transactor"
-
-	stash "github.com/nordew/go-stash"
 )
 
 func MustRun() {
@@ -47,15 +53,15 @@ func MustRun() {
 	transactor := pgxtransactor.NewTransactor(pool)
 
 	otpGen := otp_generator.NewDefaultGenerator()
-	cache := stash.NewCache()
+	loginCodes := otp_store.NewStore(otp_store.Config{
+		CodeTTL:         cfg.AdminLogin.CodeTTL,
+		MaxFailures:     cfg.AdminLogin.MaxFailures,
+		LockoutDuration: cfg.AdminLogin.LockoutDuration,
+		MaxIPAttempts:   cfg.AdminLogin.MaxIPAttempts,
+		IPWindow:        cfg.AdminLogin.IPWindow,
+	}, otp_store.SystemClock{})
 
-	tokenCfg := auth.Config{
-		AccessTokenSecret:    cfg.Auth.AuthSecret,
-		AccessTokenDuration:  cfg.Auth.AccessTokenTTL,
-		RefreshTokenSecret:   cfg.Auth.AuthSecret,
-		RefreshTokenDuration: cfg.Auth.RefreshTokenTTL,
-	}
-	tokenService := auth.NewTokenService(tokenCfg)
+	tokenService := newTokenService(cfg.Auth, logger)
 
 	storages := storage.NewStorage(pool)
 
@@ -63,7 +69,7 @@ func MustRun() {
 		cfg.Telegram.Token,
 		storages,
 		otpGen,
-		cache,
+		loginCodes,
 	)
 	if err != nil {
 		logger.Fatalf("Failed to create Telegram provider: %v", err)
@@ -71,19 +77,46 @@ func MustRun() {
 
 	minio := minio_s3.MustConnect(cfg.Minio)
 
+	carrier := newCarrier(cfg.NovaPoshta, logger)
+	paymentProvider := newPaymentProvider(cfg.Monobank, logger)
+	invoiceRenderer := newInvoiceRenderer(cfg.Merchant, logger)
+
 	services := service.NewService(
 		storages,
 		transactor,
-		cache,
+		loginCodes,
 		tokenService,
 		telegramProvider,
+		carrier,
+		paymentProvider,
+		invoiceRenderer,
+		dto.MerchantRequisites{
+			Name:  cfg.Merchant.Name,
+			TaxID: cfg.Merchant.TaxID,
+			IBAN:  cfg.Merchant.IBAN,
+			Bank:  cfg.Merchant.Bank,
+		},
+		otpGen,
 		minio,
 		cfg.Minio.BucketName,
+		logger,
 	)
 
+	telegramProvider.SetAdminRegistrar(services)
+
 	promocodeWorker := workers.NewPromocodeWorker(services, logger)
 	promocodeWorker.Start()
 
+	shipmentWorker := workers.NewShipmentWorker(services, logger)
+	shipmentWorker.Start()
+
+	orderExpiryWorker := workers.NewOrderExpiryWorker(services, map[models.PaymentMethod]time.Duration{
+		models.PaymentMethodIBAN:           cfg.OrderExpiry.IBAN,
+		models.PaymentMethodCard:           cfg.OrderExpiry.Card,
+		models.PaymentMethodCashOnDelivery: cfg.OrderExpiry.CashOnDelivery,
+	}, logger)
+	orderExpiryWorker.Start()
+
 	slogHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
 	})
@@ -93,16 +126,6 @@ func MustRun() {
 	router := createRouter(&cfg)
 	setSwagger(router)
 
-	go func() {
-		cacheCfg := stash.CacheWorkerConfig{
-			Cache:    cache,
-			Interval: 1 * time.Minute,
-			StopCh:   make(chan struct{}),
-		}
-
-		stash.StartCacheWorker(ctx, cacheCfg)
-	}()
-
 	serverShutdownDone := make(chan struct{})
 	go func() {
 		defer close(serverShutdownDone)
@@ -124,6 +147,8 @@ func MustRun() {
 
 	logger.Println("Stopping worker...")
 	promocodeWorker.Stop()
+	shipmentWorker.Stop()
+	orderExpiryWorker.Stop()
 	telegramProvider.Stop()
 
 	logger.Println("Stopping HTTP server...")
@@ -139,6 +164,95 @@ func MustRun() {
 	logger.Println("Shutdown complete")
 }
 
+func newTokenService(cfg config.Auth, logger *log.Logger) *auth.TokenService {
+	alg := auth.Algorithm(cfg.Algorithm)
+
+	accessKeys, err := newKeySet(alg, cfg.AccessKeyID, cfg.AccessKey, cfg.PreviousAccessKeys)
+	if err != nil {
+		logger.Fatalf("Failed to load access token keys: %v", err)
+	}
+
+	refreshKeys, err := newKeySet(alg, cfg.RefreshKeyID, cfg.RefreshKey, cfg.PreviousRefreshKeys)
+	if err != nil {
+		logger.Fatalf("Failed to load refresh token keys: %v", err)
+	}
+
+	tokenService, err := auth.NewTokenService(auth.Config{
+		AccessKeys:           accessKeys,
+		RefreshKeys:          refreshKeys,
+		AccessTokenDuration:  cfg.AccessTokenTTL,
+		RefreshTokenDuration: cfg.RefreshTokenTTL,
+		Issuer:               auth.Issuer,
+	})
+	if err != nil {
+		logger.Fatalf("Failed to create token service: %v", err)
+	}
+
+	return tokenService
+}
+
+func newKeySet(alg auth.Algorithm, id, key string, previous map[string]string) (auth.KeySet, error) {
+	signing, err := auth.NewKey(id, alg, key)
+	if err != nil {
+		return auth.KeySet{}, err
+	}
+
+	old := make([]auth.Key, 0, len(previous))
+	for prevID, prevKey := range previous {
+		k, err := auth.NewKey(prevID, alg, prevKey)
+		if err != nil {
+			return auth.KeySet{}, err
+		}
+		old = append(old, k)
+	}
+
+	return auth.NewKeySet(signing, old...), nil
+}
+
+func newCarrier(cfg config.NovaPoshta, logger *log.Logger) service.Carrier {
+	if cfg.UseFake {
+		logger.Println("Using the in-memory carrier, waybills are not real")
+		return fakecarrier.NewCarrier(nil, nil)
+	}
+
+	if cfg.APIKey == "" {
+		logger.Fatalf("NOVA_POSHTA_API_KEY is not set; set NOVA_POSHTA_USE_FAKE=true for local development")
+	}
+
+	return novaposhta.NewClient(cfg)
+}
+
+func newPaymentProvider(cfg config.Monobank, logger *log.Logger) service.PaymentProvider {
+	if cfg.UseFake {
+		if cfg.FakeSecret == "" {
+			logger.Fatalf("MONOBANK_FAKE_SECRET must be set to use the in-memory payment provider")
+		}
+
+		logger.Println("Using the in-memory payment provider, card payments are not real")
+		return fakepayment.NewProvider(cfg.FakeSecret, cfg.RedirectURL)
+	}
+
+	if cfg.Token == "" {
+		logger.Fatalf("MONOBANK_TOKEN is not set; set MONOBANK_USE_FAKE=true for local development")
+	}
+
+	return monobank.NewClient(cfg)
+}
+
+func newInvoiceRenderer(cfg config.Merchant, logger *log.Logger) service.InvoiceRenderer {
+	if cfg.InvoiceFont == "" {
+		logger.Println("Invoice font is not set, PDF invoices are disabled")
+		return invoice.Unavailable{}
+	}
+
+	renderer, err := invoice.NewPDFRenderer(cfg.InvoiceFont)
+	if err != nil {
+		logger.Fatalf("Failed to create invoice renderer: %v", err)
+	}
+
+	return renderer
+}
+
 func createRouter(cfg *config.Config) *fiber.App {
 	return fiber.New(fiber.Config{
 		ReadTimeout:  10 * time.Second,
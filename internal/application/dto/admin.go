@@ -1,7 +1,16 @@
 package dto
 
+import (
+	"aroma-hub/internal/models"
+	"time"
+)
+
+// AdminLoginRequest carries the code the bot sent an admin together with
+// their Telegram ID, which the bot shows next to the code.
 type AdminLoginRequest struct {
-	OTP string `json:"otp" validate:"required"`
+	VendorID string `json:"vendorId" validate:"required"`
+	OTP      string `json:"otp" validate:"required"`
+	IP       string `json:"-"`
 }
 
 type AdminLoginResponse struct {
@@ -18,6 +27,34 @@ type AdminRefreshTokenResponse struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+type AdminLogoutRequest struct {
+	AdminID   string `json:"-"`
+	SessionID string `json:"-"`
+}
+
+type AdminLogoutAllResponse struct {
+	RevokedSessions int64 `json:"revokedSessions"`
+}
+
+type AssignAdminRoleRequest struct {
+	AdminID string           `json:"-"`
+	Role    models.AdminRole `json:"role" validate:"required"`
+}
+
 type ListAdminFilter struct {
-	VendorID string `json:"vendorId"`
+	ID       string           `json:"id"`
+	VendorID string           `json:"vendorId"`
+	Role     models.AdminRole `json:"role"`
+	Active   bool             `json:"active"`
+}
+
+type CreateAdminInviteRequest struct {
+	CreatedBy string           `json:"-"`
+	Role      models.AdminRole `json:"role" validate:"required"`
+}
+
+type AdminInviteResponse struct {
+	Link      string           `json:"link"`
+	Role      models.AdminRole `json:"role"`
+	ExpiresAt time.Time        `json:"expiresAt"`
 }
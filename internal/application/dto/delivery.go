@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"aroma-hub/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type SearchDeliveryCitiesFilter struct {
+	Query string `json:"query"`
+}
+
+type ListDeliveryWarehousesFilter struct {
+	CityRef string `json:"cityRef"`
+	Query   string `json:"query"`
+}
+
+type DeliveryCitiesResponse struct {
+	Cities []models.DeliveryCity `json:"cities"`
+}
+
+type DeliveryWarehousesResponse struct {
+	Warehouses []models.DeliveryWarehouse `json:"warehouses"`
+}
+
+type CreateWaybillRequest struct {
+	OrderID     string  `json:"-"`
+	AdminID     string  `json:"-"`
+	Weight      float64 `json:"weight" validate:"required"`
+	SeatsAmount uint    `json:"seatsAmount"`
+	Description string  `json:"description"`
+}
+
+type WaybillResponse struct {
+	OrderID string         `json:"orderId"`
+	Carrier string         `json:"carrier"`
+	Waybill models.Waybill `json:"waybill"`
+}
+
+// CarrierWaybillRequest is everything a carrier needs to register a parcel.
+// CashOnDelivery is zero when the order is prepaid.
+type CarrierWaybillRequest struct {
+	RecipientName  string
+	RecipientPhone string
+	CityRef        string
+	WarehouseRef   string
+	Weight         float64
+	SeatsAmount    uint
+	Description    string
+	DeclaredValue  decimal.Decimal
+	CashOnDelivery decimal.Decimal
+}
+
+// CarrierTrackingRequest identifies a parcel to track. Some carriers only
+// reveal full details when the recipient's phone number is given.
+type CarrierTrackingRequest struct {
+	Number string
+	Phone  string
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "aroma-hub/internal/models"
+
+type ListInventoryMovementFilter struct {
+	ProductID   string                `json:"productId"`
+	VariantID   string                `json:"variantId"`
+	Reason      models.MovementReason `json:"reason"`
+	ReferenceID string                `json:"referenceId"`
+	Limit       uint                  `json:"limit"`
+	Page        uint                  `json:"page"`
+}
+
+type ListInventoryMovementsResponse struct {
+	Movements []models.InventoryMovement `json:"movements"`
+	Total     int64                      `json:"total"`
+}
+
+type InventoryReconciliationResponse struct {
+	Consistent bool                    `json:"consistent"`
+	Drifts     []models.InventoryDrift `json:"drifts"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"aroma-hub/internal/models"
+	"time"
+)
+
+// MerchantRequisites are the shop's FOP details customers transfer IBAN
+// payments to.
+type MerchantRequisites struct {
+	Name  string `json:"name"`
+	TaxID string `json:"taxId"`
+	IBAN  string `json:"iban"`
+	Bank  string `json:"bank,omitempty"`
+}
+
+type OrderInvoiceItem struct {
+	Brand     string `json:"brand"`
+	Name      string `json:"name"`
+	Volume    uint   `json:"volume"`
+	Quantity  uint   `json:"quantity"`
+	UnitPrice uint   `json:"unitPrice"`
+	LineTotal uint   `json:"lineTotal"`
+}
+
+// OrderInvoice is a bank transfer invoice for an IBAN order. Number is the
+// reference the customer puts in the payment purpose, the full order ID.
+type OrderInvoice struct {
+	Number         string               `json:"number"`
+	OrderID        string               `json:"orderId"`
+	PaymentPurpose string               `json:"paymentPurpose"`
+	Merchant       MerchantRequisites   `json:"merchant"`
+	FullName       string               `json:"fullName"`
+	PhoneNumber    string               `json:"phoneNumber"`
+	Items          []OrderInvoiceItem   `json:"items"`
+	Subtotal       uint                 `json:"subtotal"`
+	DiscountAmount uint                 `json:"discountAmount"`
+	AmountToPay    uint                 `json:"amountToPay"`
+	PaymentStatus  models.PaymentStatus `json:"paymentStatus"`
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+type MarkOrderPaidRequest struct {
+	OrderID string `json:"-"`
+	AdminID string `json:"-"`
+	Comment string `json:"comment,omitempty"`
+}
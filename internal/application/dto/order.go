@@ -6,26 +6,46 @@ import (
 )
 
 type ProductOrder struct {
-	ID       string `json:"id" validate:"required"`
-	Brand    string `json:"brand" validate:"required"`
-	Name     string `json:"name" validate:"required"`
-	Price    uint   `json:"price" validate:"required"`
-	Quantity uint   `json:"quantity" validate:"required"`
-	Volume   uint   `json:"volume" validate:"required"`
+	ID        string `json:"id" validate:"required"`
+	VariantID string `json:"variantId,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+	Brand     string `json:"brand" validate:"required"`
+	Name      string `json:"name" validate:"required"`
+	Price     uint   `json:"price" validate:"required"`
+	LineTotal uint   `json:"lineTotal,omitempty"`
+	Quantity  uint   `json:"quantity" validate:"required"`
+	Volume    uint   `json:"volume" validate:"required"`
+
+	ReturnedQuantity uint `json:"returnedQuantity,omitempty"`
 }
 
 type Order struct {
-	ID            string               `json:"id"`
-	FullName      string               `json:"fullName"`
-	PhoneNumber   string               `json:"phoneNumber"`
-	Address       string               `json:"address"`
-	PaymentMethod models.PaymentMethod `json:"paymentMethod"`
-	ContactType   models.ContactType   `json:"contactType"`
-	AmountToPay   uint                 `json:"amountToPay"`
-	Status        models.OrderStatus   `json:"status"`
-	Products      []ProductOrder       `json:"products"`
-	CreatedAt     time.Time            `json:"createdAt"`
-	UpdatedAt     time.Time            `json:"updatedAt"`
+	ID             string               `json:"id"`
+	FullName       string               `json:"fullName"`
+	PhoneNumber    string               `json:"phoneNumber"`
+	Address        string               `json:"address"`
+	PaymentMethod  models.PaymentMethod `json:"paymentMethod"`
+	ContactType    models.ContactType   `json:"contactType"`
+	PromoCode      string               `json:"promoCode,omitempty"`
+	Subtotal       uint                 `json:"subtotal"`
+	DiscountAmount uint                 `json:"discountAmount"`
+	AmountToPay    uint                 `json:"amountToPay"`
+	RefundedAmount uint                 `json:"refundedAmount,omitempty"`
+	Status         models.OrderStatus   `json:"status"`
+	PaymentStatus  models.PaymentStatus `json:"paymentStatus"`
+	PaymentURL     string               `json:"paymentUrl,omitempty"`
+	Delivery       *OrderDelivery       `json:"delivery,omitempty"`
+	TrackingToken  string               `json:"trackingToken,omitempty"`
+	TrackingNumber string               `json:"trackingNumber,omitempty"`
+	Products       []ProductOrder       `json:"products"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	UpdatedAt      time.Time            `json:"updatedAt"`
+}
+
+type OrderDelivery struct {
+	Carrier      string `json:"carrier,omitempty"`
+	CityRef      string `json:"cityRef"`
+	WarehouseRef string `json:"warehouseRef"`
 }
 
 type OrderResponse struct {
@@ -34,22 +54,39 @@ type OrderResponse struct {
 }
 
 type CreateOrderRequest struct {
-	FullName      string               `json:"fullName" validate:"required"`
-	PhoneNumber   string               `json:"phoneNumber" validate:"required"`
-	Address       string               `json:"address" validate:"required"`
-	PaymentMethod models.PaymentMethod `json:"paymentMethod" validate:"required,oneof=IBAN сash_on_delivery"`
-	PromoCode     string               `json:"promoCode"`
-	ContactType   models.ContactType   `json:"contactType" validate:"required,oneof=telegram phone"`
-	ProductItems  []ProductOrder       `json:"productItems" validate:"required"`
+	IdempotencyKey string               `json:"-"`
+	FullName       string               `json:"fullName" validate:"required"`
+	PhoneNumber    string               `json:"phoneNumber" validate:"required"`
+	Address        string               `json:"address" validate:"required"`
+	PaymentMethod  models.PaymentMethod `json:"paymentMethod" validate:"required,oneof=IBAN сash_on_delivery card"`
+	PromoCode      string               `json:"promoCode"`
+	ContactType    models.ContactType   `json:"contactType" validate:"required,oneof=telegram phone"`
+	ProductItems   []ProductOrder       `json:"productItems" validate:"required"`
+
+	// DeliveryCityRef and DeliveryWarehouseRef pick a carrier branch from
+	// the delivery lookups. Address stays as the human-readable copy.
+	DeliveryCityRef      string `json:"deliveryCityRef,omitempty"`
+	DeliveryWarehouseRef string `json:"deliveryWarehouseRef,omitempty"`
 }
 
 type UpdateOrderRequest struct {
-	ID            string               `json:"id" validate:"required"`
-	FullName      string               `json:"fullName,omitempty"`
-	PhoneNumber   string               `json:"phoneNumber,omitempty"`
-	Address       string               `json:"address,omitempty"`
-	Status        models.OrderStatus   `json:"status,omitempty"`
-	PaymentMethod models.PaymentMethod `json:"paymentMethod,omitempty"`
+	ID             string               `json:"id" validate:"required"`
+	AdminID        string               `json:"-"`
+	Comment        string               `json:"comment,omitempty"`
+	FullName       string               `json:"fullName,omitempty"`
+	PhoneNumber    string               `json:"phoneNumber,omitempty"`
+	Address        string               `json:"address,omitempty"`
+	Status         models.OrderStatus   `json:"status,omitempty"`
+	PaymentMethod  models.PaymentMethod `json:"paymentMethod,omitempty"`
+	TrackingNumber string               `json:"trackingNumber,omitempty"`
+}
+
+// UpdateOrderItemsRequest replaces the lines of an order. Each item names a
+// product, its variant, volume and quantity; the other fields are ignored.
+type UpdateOrderItemsRequest struct {
+	OrderID string         `json:"-"`
+	AdminID string         `json:"-"`
+	Items   []ProductOrder `json:"items" validate:"required,min=1,dive"`
 }
 
 type ListOrderFilter struct {
@@ -57,10 +94,88 @@ type ListOrderFilter struct {
 	Page  uint `json:"page"`
 
 	IDs           []string             `json:"id"`
+	TrackingToken string               `json:"-"`
 	UserID        string               `json:"userId"`
 	PaymentMethod models.PaymentMethod `json:"paymentMethod"`
 	ContactType   models.ContactType   `json:"contactType"`
 	FromDate      *time.Time           `json:"fromDate"`
 	ToDate        *time.Time           `json:"toDate"`
 	Status        models.OrderStatus   `json:"status"`
+	PaymentStatus models.PaymentStatus `json:"paymentStatus"`
+
+	Statuses          []models.OrderStatus `json:"-"`
+	HasTrackingNumber bool                 `json:"-"`
+}
+
+type CancelOrderRequest struct {
+	ID      string `json:"-"`
+	AdminID string `json:"-"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// ExpirePendingOrdersRequest selects unpaid pending orders of a payment
+// method placed before CreatedBefore.
+type ExpirePendingOrdersRequest struct {
+	PaymentMethod models.PaymentMethod
+	CreatedBefore time.Time
+}
+
+type OrderStatusHistoryResponse struct {
+	History []models.OrderStatusHistory `json:"history"`
+}
+
+type LookupOrderRequest struct {
+	OrderID     string `json:"orderId" validate:"required"`
+	PhoneNumber string `json:"phoneNumber" validate:"required"`
+}
+
+// OrderTrackingResponse is what a customer sees by tracking token. It leaves
+// out the name, phone number and address on the order.
+type OrderTrackingResponse struct {
+	Status         models.OrderStatus   `json:"status"`
+	PaymentStatus  models.PaymentStatus `json:"paymentStatus"`
+	TrackingNumber string               `json:"trackingNumber,omitempty"`
+	Subtotal       uint                 `json:"subtotal"`
+	DiscountAmount uint                 `json:"discountAmount"`
+	AmountToPay    uint                 `json:"amountToPay"`
+	Products       []ProductOrder       `json:"products"`
+	History        []OrderTrackingEvent `json:"history"`
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+type OrderTrackingEvent struct {
+	Status    models.OrderStatus `json:"status"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+type OrderTrackingTokenResponse struct {
+	TrackingToken string `json:"trackingToken"`
+}
+
+type OrderReturnItemRequest struct {
+	VariantID string `json:"variantId" validate:"required"`
+	Volume    uint   `json:"volume" validate:"required"`
+	Quantity  uint   `json:"quantity" validate:"required"`
+}
+
+// CreateOrderReturnRequest opens a return for some lines of an order.
+// RefundAmount is in hryvnias; when the order was paid online it is sent back
+// through the payment provider.
+type CreateOrderReturnRequest struct {
+	OrderID      string                   `json:"-"`
+	AdminID      string                   `json:"-"`
+	Items        []OrderReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+	Restock      bool                     `json:"restock"`
+	RefundAmount uint                     `json:"refundAmount"`
+	Comment      string                   `json:"comment,omitempty"`
+}
+
+type OrderReturnResponse struct {
+	Return models.OrderReturn    `json:"return"`
+	Status models.OrderStatus    `json:"status"`
+	Refund *models.PaymentRefund `json:"refund,omitempty"`
+}
+
+type ListOrderReturnsResponse struct {
+	Returns []models.OrderReturn `json:"returns"`
 }
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"aroma-hub/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentInvoiceRequest asks a payment provider for a checkout page. Amount
+// is in hryvnias.
+type PaymentInvoiceRequest struct {
+	OrderID     string
+	Amount      decimal.Decimal
+	Description string
+}
+
+type PaymentInvoice struct {
+	InvoiceID   string
+	CheckoutURL string
+}
+
+// PaymentEvent is a verified webhook notification. Status is empty for
+// intermediate provider states the shop does not track.
+type PaymentEvent struct {
+	InvoiceID string
+	Status    models.PaymentStatus
+	Amount    decimal.Decimal
+}
+
+type PaymentWebhookRequest struct {
+	Body      []byte
+	Signature string
+}
+
+type RefundOrderPaymentRequest struct {
+	OrderID string `json:"-"`
+	AdminID string `json:"-"`
+}
+
+type OrderPaymentResponse struct {
+	Payment models.Payment `json:"payment"`
+}
@@ -3,6 +3,7 @@ package dto
 import "aroma-hub/internal/models"
 
 type CreateProductRequest struct {
+	AdminID         string  `json:"-"`
 	CategoryName    string  `json:"categoryName"`
 	Brand           string  `json:"brand"`
 	Name            string  `json:"name"`
@@ -10,8 +11,10 @@ type CreateProductRequest struct {
 	Composition     string  `json:"composition"`
 	Characteristics string  `json:"characteristics"`
 	Price           float64 `json:"price"`
+	PricePerMl      float64 `json:"pricePerMl"`
+	BottleFee       float64 `json:"bottleFee"`
 	IsBestSeller    bool    `json:"isBestSeller"`
-	StockAmount     uint    `json:"stockAmount"`
+	StockAmount     uint    `json:"stockAmount"` // millilitres of the default decant variant
 }
 
 type ListProductResponse struct {
@@ -51,7 +54,8 @@ type UpdateProductRequest struct {
 	Composition     string  `json:"composition"`
 	Characteristics string  `json:"characteristics"`
 	Price           float64 `json:"price"`
-	StockAmount     uint    `json:"stockAmount"`
+	PricePerMl      float64 `json:"pricePerMl"`
+	BottleFee       float64 `json:"bottleFee"`
 	MakeVisible     bool    `json:"makeVisible"`
 	Hide            bool    `json:"hide"`
 	SetBestSeller   bool    `json:"setBestSeller"`
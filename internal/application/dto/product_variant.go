@@ -0,0 +1,33 @@
+package dto
+
+type CreateProductVariantRequest struct {
+	ProductID   string  `json:"-"`
+	AdminID     string  `json:"-"`
+	SKU         string  `json:"sku"`
+	Volume      uint    `json:"volume"`
+	Price       float64 `json:"price"`
+	StockAmount uint    `json:"stockAmount"`
+}
+
+type ListProductVariantFilter struct {
+	IDs           []string `json:"id"`
+	ProductIDs    []string `json:"productIds"`
+	ShowInvisible bool     `json:"-"`
+}
+
+type UpdateProductVariantRequest struct {
+	ID          string  `json:"-"`
+	ProductID   string  `json:"-"`
+	AdminID     string  `json:"-"`
+	SKU         string  `json:"sku"`
+	Price       float64 `json:"price"`
+	StockAmount *uint   `json:"stockAmount"`
+	MakeVisible bool    `json:"makeVisible"`
+	Hide        bool    `json:"hide"`
+}
+
+// OnlyChangesStock reports whether the update touches nothing but the stock
+// amount.
+func (r UpdateProductVariantRequest) OnlyChangesStock() bool {
+	return r.SKU == "" && r.Price == 0 && !r.MakeVisible && !r.Hide
+}
@@ -6,9 +6,39 @@ import (
 )
 
 type CreatePromocodeRequest struct {
-	Code      string    `json:"code"`
-	Discount  uint      `json:"discount"`
-	ExpiresAt time.Time `json:"expiresAt"`
+	Code             string              `json:"code"`
+	Campaign         string              `json:"campaign"`
+	DiscountType     models.DiscountType `json:"discountType" validate:"omitempty,oneof=percent fixed"`
+	Discount         uint                `json:"discount"`
+	CategoryIDs      []string            `json:"categoryIds"`
+	Brands           []string            `json:"brands"`
+	ProductIDs       []string            `json:"productIds"`
+	StartsAt         time.Time           `json:"startsAt"`
+	ExpiresAt        time.Time           `json:"expiresAt"`
+	MaxRedemptions   uint                `json:"maxRedemptions"`
+	PerCustomerLimit uint                `json:"perCustomerLimit"`
+	MinOrderAmount   uint                `json:"minOrderAmount"`
+}
+
+type GeneratePromocodeBatchRequest struct {
+	Campaign       string              `json:"campaign" validate:"required"`
+	Count          uint                `json:"count" validate:"required"`
+	Prefix         string              `json:"prefix"`
+	Alphabet       string              `json:"alphabet"`
+	Length         uint                `json:"length"`
+	DiscountType   models.DiscountType `json:"discountType" validate:"omitempty,oneof=percent fixed"`
+	Discount       uint                `json:"discount"`
+	CategoryIDs    []string            `json:"categoryIds"`
+	Brands         []string            `json:"brands"`
+	ProductIDs     []string            `json:"productIds"`
+	StartsAt       time.Time           `json:"startsAt"`
+	ExpiresAt      time.Time           `json:"expiresAt"`
+	MinOrderAmount uint                `json:"minOrderAmount"`
+}
+
+type PromocodeBatchResponse struct {
+	Campaign string   `json:"campaign"`
+	Codes    []string `json:"codes"`
 }
 
 type ListPromocodesResponse struct {
@@ -17,12 +47,34 @@ type ListPromocodesResponse struct {
 }
 
 type ListPromocodeFilter struct {
-	ID           string `json:"id"`
-	Code         string `json:"code"`
-	DiscountFrom uint   `json:"discountFrom"`
-	DiscountTo   uint   `json:"discountTo"`
-	Active       bool   `json:"active"`
-	Expired      bool   `json:"expired"`
-	Limit        uint   `json:"limit"`
-	Page         uint   `json:"page"`
+	ID           string              `json:"id"`
+	Code         string              `json:"code"`
+	Campaign     string              `json:"campaign"`
+	DiscountType models.DiscountType `json:"discountType"`
+	DiscountFrom uint                `json:"discountFrom"`
+	DiscountTo   uint                `json:"discountTo"`
+	Active       bool                `json:"active"`
+	Expired      bool                `json:"expired"`
+	Limit        uint                `json:"limit"`
+	Page         uint                `json:"page"`
+}
+
+type CountPromocodeRedemptionFilter struct {
+	PromocodeID string `json:"promocodeId"`
+	PhoneNumber string `json:"phoneNumber"`
+}
+
+type PreviewPromocodeRequest struct {
+	Code         string         `json:"code" validate:"required"`
+	PhoneNumber  string         `json:"phoneNumber"`
+	ProductItems []ProductOrder `json:"productItems" validate:"required"`
+}
+
+type PromocodePreviewResponse struct {
+	Code           string `json:"code"`
+	Valid          bool   `json:"valid"`
+	Reason         string `json:"reason,omitempty"`
+	Subtotal       uint   `json:"subtotal"`
+	DiscountAmount uint   `json:"discountAmount"`
+	AmountToPay    uint   `json:"amountToPay"`
 }
@@ -2,8 +2,25 @@ package service
 
 import (
 	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"aroma-hub/pkg/auth"
+	"aroma-hub/pkg/otp_store"
 	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+)
+
+var (
+	ErrInvalidRefreshToken = "Invalid or expired refresh token"
+	ErrRefreshTokenReused  = "Refresh token was already used, the session has been revoked"
+	ErrSessionRequired     = "Token is not bound to a session, log in again"
+	ErrAdminNotFound       = "Admin not found"
+	ErrLastOwner           = "The last active owner cannot be demoted, disabled or removed"
+	ErrAdminDisabled       = "Admin is disabled"
 )
 
 func (s *Service) IsAdmin(ctx context.Context, vendorID string) (bool, error) {
@@ -17,54 +34,247 @@ func (s *Service) IsAdmin(ctx context.Context, vendorID string) (bool, error) {
 	return len(admins) > 0, nil
 }
 
+// AdminLogin exchanges the one-time code the bot sent an admin for a token
+// pair. The code is used up on success; repeated wrong codes lock the admin
+// out for a while.
 func (s *Service) AdminLogin(ctx context.Context, input dto.AdminLoginRequest) (dto.AdminLoginResponse, error) {
-	vendorID, ok := s.cache.Get(input.OTP)
-	if !ok {
-		return dto.AdminLoginResponse{}, errx.NewBadRequest().WithDescription("Invalid OTP")
+	if err := s.loginCodes.Verify(input.VendorID, input.IP, input.OTP); err != nil {
+		switch {
+		case errors.Is(err, otp_store.ErrLocked), errors.Is(err, otp_store.ErrTooManyAttempts):
+			return dto.AdminLoginResponse{}, errx.NewForbidden().WithDescription(err.Error())
+		case errors.Is(err, otp_store.ErrInvalidCode), errors.Is(err, otp_store.ErrAccountRequired):
+			return dto.AdminLoginResponse{}, errx.NewBadRequest().WithDescription("Invalid OTP")
+		default:
+			return dto.AdminLoginResponse{}, errx.NewInternal().WithDescriptionAndCause("failed to verify OTP", err)
+		}
 	}
 
 	admins, err := s.storage.ListAdmins(ctx, dto.ListAdminFilter{
-		VendorID: vendorID.(string),
+		VendorID: input.VendorID,
 	})
 	if err != nil {
 		return dto.AdminLoginResponse{}, err
 	}
+	if len(admins) == 0 {
+		return dto.AdminLoginResponse{}, errx.NewBadRequest().WithDescription("Invalid OTP")
+	}
 	admin := admins[0]
 
-	if admin.VendorID != vendorID {
-		return dto.AdminLoginResponse{}, errx.NewBadRequest().WithDescription("Invalid OTP")
+	if !admin.IsActive() {
+		return dto.AdminLoginResponse{}, errx.NewForbidden().WithDescription(ErrAdminDisabled)
+	}
+
+	return s.startAdminSession(ctx, admin)
+}
+
+// AdminRefresh exchanges a refresh token for a new token pair. Each refresh
+// token works once: a token that was already rotated means it leaked, so the
+// whole session is revoked and its holder has to log in again.
+func (s *Service) AdminRefresh(ctx context.Context, input dto.AdminRefreshTokenRequest) (dto.AdminRefreshTokenResponse, error) {
+	claims, err := s.tokenService.VerifyRefreshToken(input.RefreshToken)
+	if err != nil {
+		return dto.AdminRefreshTokenResponse{}, errx.NewUnauthorized().WithDescription(ErrInvalidRefreshToken)
+	}
+
+	var (
+		resp   dto.AdminRefreshTokenResponse
+		reused bool
+	)
+
+	err = s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		session, err := s.storage.LockAdminSession(ctx, claims.SessionID)
+		if err != nil {
+			if errx.IsCode(err, errx.NotFound) {
+				return errx.NewUnauthorized().WithDescription(ErrInvalidRefreshToken)
+			}
+			return err
+		}
+
+		if session.AdminID != claims.UserID || !session.IsActive(time.Now()) {
+			return errx.NewUnauthorized().WithDescription(ErrInvalidRefreshToken)
+		}
+
+		if session.RefreshTokenID != claims.ID {
+			reused = true
+			return s.storage.RevokeAdminSession(ctx, session.ID)
+		}
+
+		// The admin is read again so a changed role applies from now on.
+		admins, err := s.storage.ListAdmins(ctx, dto.ListAdminFilter{ID: session.AdminID})
+		if err != nil {
+			return err
+		}
+		if len(admins) == 0 || !admins[0].IsActive() {
+			return errx.NewUnauthorized().WithDescription(ErrInvalidRefreshToken)
+		}
+
+		identity := adminIdentity(admins[0], session.ID)
+
+		accessToken, err := s.tokenService.GenerateAccessToken(identity)
+		if err != nil {
+			return err
+		}
+
+		refreshToken, refreshClaims, err := s.tokenService.GenerateRefreshToken(identity)
+		if err != nil {
+			return err
+		}
+
+		session.RefreshTokenID = refreshClaims.ID
+		session.ExpiresAt = refreshClaims.ExpiresAt.Time
+		if err := s.storage.RotateAdminSession(ctx, session); err != nil {
+			return err
+		}
+
+		resp = dto.AdminRefreshTokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.AdminRefreshTokenResponse{}, err
+	}
+	if reused {
+		return dto.AdminRefreshTokenResponse{}, errx.NewUnauthorized().WithDescription(ErrRefreshTokenReused)
 	}
 
-	accessToken, err := s.tokenService.GenerateAccessToken(admin.ID, admin.VendorID)
+	return resp, nil
+}
+
+// AdminLogout ends the session the request was made with. Access tokens
+// already issued stay valid until they expire.
+func (s *Service) AdminLogout(ctx context.Context, input dto.AdminLogoutRequest) error {
+	if input.SessionID == "" {
+		return errx.NewUnauthorized().WithDescription(ErrSessionRequired)
+	}
+
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		session, err := s.storage.LockAdminSession(ctx, input.SessionID)
+		if err != nil {
+			return err
+		}
+		if session.AdminID != input.AdminID {
+			return errx.NewNotFound().WithDescription("admin session not found")
+		}
+
+		return s.storage.RevokeAdminSession(ctx, session.ID)
+	})
+}
+
+// AdminLogoutAll ends every session of the admin, logging them out on all
+// devices.
+func (s *Service) AdminLogoutAll(ctx context.Context, adminID string) (dto.AdminLogoutAllResponse, error) {
+	revoked, err := s.storage.RevokeAdminSessions(ctx, adminID)
+	if err != nil {
+		return dto.AdminLogoutAllResponse{}, err
+	}
+
+	return dto.AdminLogoutAllResponse{RevokedSessions: revoked}, nil
+}
+
+// startAdminSession opens a session for the admin and issues its first token
+// pair.
+func (s *Service) startAdminSession(ctx context.Context, admin models.Admin) (dto.AdminLoginResponse, error) {
+	identity := adminIdentity(admin, uuid.NewString())
+
+	accessToken, err := s.tokenService.GenerateAccessToken(identity)
 	if err != nil {
 		return dto.AdminLoginResponse{}, err
 	}
 
-	refreshToken, err := s.tokenService.GenerateRefreshToken(admin.ID, admin.VendorID)
+	refreshToken, refreshClaims, err := s.tokenService.GenerateRefreshToken(identity)
 	if err != nil {
 		return dto.AdminLoginResponse{}, err
 	}
 
+	session := models.NewAdminSession(identity.SessionID, admin.ID, refreshClaims.ID, refreshClaims.ExpiresAt.Time)
+	if err := s.storage.CreateAdminSession(ctx, session); err != nil {
+		return dto.AdminLoginResponse{}, err
+	}
+
 	return dto.AdminLoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *Service) AdminRefresh(_ context.Context, input dto.AdminRefreshTokenRequest) (dto.AdminRefreshTokenResponse, error) {
-	return dto.AdminRefreshTokenResponse{}, nil
-	//accessToken, err := s.tokenService.GenerateAccessToken(input.AdminID, input.VendorID)
-	//if err != nil {
-	//	return dto.AdminLoginResponse{}, err
-	//}
-	//
-	//refreshToken, err := s.tokenService.GenerateRefreshToken(input.AdminID, input.VendorID)
-	//if err != nil {
-	//	return dto.AdminLoginResponse{}, err
-	//}
-	//
-	//return dto.AdminLoginResponse{
-	//	AccessToken:  accessToken,
-	//	RefreshToken: refreshToken,
-	//}, nil
+func adminIdentity(admin models.Admin, sessionID string) auth.Identity {
+	return auth.Identity{
+		UserID:    admin.ID,
+		VendorID:  admin.VendorID,
+		SessionID: sessionID,
+		Role:      string(admin.Role),
+	}
+}
+
+// AssignAdminRole changes what an admin may do. The new role takes effect
+// when the admin's access token is next refreshed. The last owner can't give
+// up the role, so someone can always manage admins.
+func (s *Service) AssignAdminRole(ctx context.Context, input dto.AssignAdminRoleRequest) (models.Admin, error) {
+	if !input.Role.IsValid() {
+		return models.Admin{}, errx.NewValidation().WithDescription(models.ErrInvalidAdminRole)
+	}
+
+	// Assigning the role an admin already has changes nothing, so it must
+	// not trip the last-owner guard.
+	admin, err := s.getAdmin(ctx, input.AdminID)
+	if err != nil {
+		return models.Admin{}, err
+	}
+	if admin.Role == input.Role {
+		return admin, nil
+	}
+
+	err = s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		admin, err := s.lockAdminChange(ctx, input.AdminID)
+		if err != nil {
+			return err
+		}
+
+		if admin.Role == input.Role {
+			return nil
+		}
+
+		return s.storage.UpdateAdminRole(ctx, admin.ID, input.Role)
+	})
+	if err != nil {
+		return models.Admin{}, err
+	}
+
+	return s.getAdmin(ctx, input.AdminID)
+}
+
+// lockAdminChange loads an admin about to lose its rights. It fails when the
+// admin is the last active owner, so someone can always manage admins. It
+// must run inside a transaction.
+func (s *Service) lockAdminChange(ctx context.Context, adminID string) (models.Admin, error) {
+	owners, err := s.storage.LockAdminsByRole(ctx, models.AdminRoleOwner)
+	if err != nil {
+		return models.Admin{}, err
+	}
+
+	admin, err := s.getAdmin(ctx, adminID)
+	if err != nil {
+		return models.Admin{}, err
+	}
+
+	if admin.Role == models.AdminRoleOwner && admin.IsActive() && owners <= 1 {
+		return models.Admin{}, errx.NewBadRequest().WithDescription(ErrLastOwner)
+	}
+
+	return admin, nil
+}
+
+func (s *Service) getAdmin(ctx context.Context, id string) (models.Admin, error) {
+	admins, err := s.storage.ListAdmins(ctx, dto.ListAdminFilter{ID: id})
+	if err != nil {
+		return models.Admin{}, err
+	}
+	if len(admins) == 0 {
+		return models.Admin{}, errx.NewNotFound().WithDescription(ErrAdminNotFound)
+	}
+
+	return admins[0], nil
 }
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+)
+
+const (
+	adminInviteTTL = 24 * time.Hour
+)
+
+var (
+	ErrAdminInviteInvalid = "Invite link is invalid, expired or already used"
+	ErrAlreadyAdmin       = "You are already an admin"
+)
+
+func (s *Service) ListAdmins(ctx context.Context) ([]models.Admin, error) {
+	admins, err := s.storage.ListAdmins(ctx, dto.ListAdminFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if admins == nil {
+		admins = make([]models.Admin, 0)
+	}
+
+	return admins, nil
+}
+
+// CreateAdminInvite issues a one-time link that makes whoever opens it in
+// Telegram an admin with the given role.
+func (s *Service) CreateAdminInvite(ctx context.Context, input dto.CreateAdminInviteRequest) (dto.AdminInviteResponse, error) {
+	invite, token, err := models.NewAdminInvite(input.Role, input.CreatedBy, adminInviteTTL)
+	if err != nil {
+		return dto.AdminInviteResponse{}, err
+	}
+
+	if err := s.storage.CreateAdminInvite(ctx, invite); err != nil {
+		return dto.AdminInviteResponse{}, err
+	}
+
+	return dto.AdminInviteResponse{
+		Link:      s.messagingProvider.DeepLink(token),
+		Role:      invite.Role,
+		ExpiresAt: invite.ExpiresAt,
+	}, nil
+}
+
+// AcceptAdminInvite registers the Telegram user vendorID as an admin with
+// the invite's role and uses the invite up.
+func (s *Service) AcceptAdminInvite(ctx context.Context, token, vendorID string) (models.Admin, error) {
+	var admin models.Admin
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		invite, err := s.storage.LockAdminInviteByTokenHash(ctx, models.HashAdminInviteToken(token))
+		if err != nil {
+			if errx.IsCode(err, errx.NotFound) {
+				return errx.NewNotFound().WithDescription(ErrAdminInviteInvalid)
+			}
+			return err
+		}
+		if !invite.IsUsable(time.Now()) {
+			return errx.NewNotFound().WithDescription(ErrAdminInviteInvalid)
+		}
+
+		existing, err := s.storage.ListAdmins(ctx, dto.ListAdminFilter{VendorID: vendorID})
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return errx.NewAlreadyExists().WithDescription(ErrAlreadyAdmin)
+		}
+
+		admin, err = models.NewAdmin(uuid.NewString(), vendorID, models.VendorTelegram, invite.Role)
+		if err != nil {
+			return err
+		}
+
+		if err := s.storage.CreateAdmin(ctx, admin); err != nil {
+			return err
+		}
+
+		return s.storage.MarkAdminInviteUsed(ctx, invite.ID, admin.ID)
+	})
+	if err != nil {
+		return models.Admin{}, err
+	}
+
+	return admin, nil
+}
+
+// DisableAdmin stops an admin from logging in and ends their sessions.
+// Access tokens already issued stay valid until they expire.
+func (s *Service) DisableAdmin(ctx context.Context, adminID string) (models.Admin, error) {
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		admin, err := s.lockAdminChange(ctx, adminID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.storage.SetAdminDisabled(ctx, admin.ID, true); err != nil {
+			return err
+		}
+
+		_, err = s.storage.RevokeAdminSessions(ctx, admin.ID)
+		return err
+	})
+	if err != nil {
+		return models.Admin{}, err
+	}
+
+	return s.getAdmin(ctx, adminID)
+}
+
+func (s *Service) EnableAdmin(ctx context.Context, adminID string) (models.Admin, error) {
+	if err := s.storage.SetAdminDisabled(ctx, adminID, false); err != nil {
+		return models.Admin{}, err
+	}
+
+	return s.getAdmin(ctx, adminID)
+}
+
+// DeleteAdmin removes an admin along with their sessions.
+func (s *Service) DeleteAdmin(ctx context.Context, adminID string) error {
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		admin, err := s.lockAdminChange(ctx, adminID)
+		if err != nil {
+			return err
+		}
+
+		return s.storage.DeleteAdmin(ctx, admin.ID)
+	})
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	minDeliverySearchLength   = 2
+	trackingBatchSize         = 100
+	defaultParcelDescription  = "Парфумерія"
+	defaultParcelSeatsAmount  = 1
+	shipmentSyncOrdersPerPage = 100
+)
+
+var (
+	ErrDeliverySearchTooShort   = "Search query must be at least 2 characters long"
+	ErrDeliveryCityRequired     = "City reference is required"
+	ErrOrderHasWaybill          = "Order already has a waybill"
+	ErrOrderHasNoDeliveryPoint  = "Order has no delivery warehouse"
+	ErrOrderNotReadyForShipment = "Order cannot be shipped in its current status"
+	ErrParcelWeightInvalid      = "Parcel weight must be greater than zero"
+)
+
+// shipmentSyncStatuses are the order statuses whose parcels are still worth
+// tracking.
+var shipmentSyncStatuses = []models.OrderStatus{
+	models.OrderStatusConfirmed,
+	models.OrderStatusProcessing,
+	models.OrderStatusShipped,
+}
+
+func (s *Service) SearchDeliveryCities(ctx context.Context, filter dto.SearchDeliveryCitiesFilter) (dto.DeliveryCitiesResponse, error) {
+	query := strings.TrimSpace(filter.Query)
+	if len([]rune(query)) < minDeliverySearchLength {
+		return dto.DeliveryCitiesResponse{}, errx.NewBadRequest().WithDescription(ErrDeliverySearchTooShort)
+	}
+
+	cities, err := s.carrier.SearchCities(ctx, query)
+	if err != nil {
+		return dto.DeliveryCitiesResponse{}, err
+	}
+
+	return dto.DeliveryCitiesResponse{Cities: cities}, nil
+}
+
+func (s *Service) ListDeliveryWarehouses(
+	ctx context.Context,
+	filter dto.ListDeliveryWarehousesFilter,
+) (dto.DeliveryWarehousesResponse, error) {
+	if filter.CityRef == "" {
+		return dto.DeliveryWarehousesResponse{}, errx.NewBadRequest().WithDescription(ErrDeliveryCityRequired)
+	}
+
+	warehouses, err := s.carrier.ListWarehouses(ctx, filter.CityRef, strings.TrimSpace(filter.Query))
+	if err != nil {
+		return dto.DeliveryWarehousesResponse{}, err
+	}
+
+	return dto.DeliveryWarehousesResponse{Warehouses: warehouses}, nil
+}
+
+// CreateOrderWaybill registers the order's parcel with the carrier and stores
+// the waybill number on the order. Cash-on-delivery orders are sent with
+// the amount to collect from the customer.
+func (s *Service) CreateOrderWaybill(ctx context.Context, input dto.CreateWaybillRequest) (dto.WaybillResponse, error) {
+	if input.Weight <= 0 {
+		return dto.WaybillResponse{}, errx.NewBadRequest().WithDescription(ErrParcelWeightInvalid)
+	}
+
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{input.OrderID}})
+	if err != nil {
+		return dto.WaybillResponse{}, err
+	}
+	order := orders[0]
+
+	if order.TrackingNumber != "" {
+		return dto.WaybillResponse{}, errx.NewConflict().WithDescription(ErrOrderHasWaybill)
+	}
+	if order.DeliveryWarehouseRef == "" {
+		return dto.WaybillResponse{}, errx.NewBadRequest().WithDescription(ErrOrderHasNoDeliveryPoint)
+	}
+	if !order.Status.CanTransitionTo(models.OrderStatusShipped) {
+		return dto.WaybillResponse{}, errx.NewBadRequest().WithDescription(ErrOrderNotReadyForShipment)
+	}
+
+	seats := input.SeatsAmount
+	if seats == 0 {
+		seats = defaultParcelSeatsAmount
+	}
+	description := strings.TrimSpace(input.Description)
+	if description == "" {
+		description = defaultParcelDescription
+	}
+
+	cashOnDelivery := decimal.Zero
+	if order.PaymentMethod == models.PaymentMethodCashOnDelivery {
+		cashOnDelivery = order.AmountToPay
+	}
+
+	waybill, err := s.carrier.CreateWaybill(ctx, dto.CarrierWaybillRequest{
+		RecipientName:  order.FullName,
+		RecipientPhone: models.NormalizePhoneNumber(order.PhoneNumber),
+		CityRef:        order.DeliveryCityRef,
+		WarehouseRef:   order.DeliveryWarehouseRef,
+		Weight:         input.Weight,
+		SeatsAmount:    seats,
+		Description:    description,
+		DeclaredValue:  order.AmountToPay,
+		CashOnDelivery: cashOnDelivery,
+	})
+	if err != nil {
+		return dto.WaybillResponse{}, err
+	}
+
+	stored, err := s.storage.SetOrderWaybill(ctx, order.ID, s.carrier.Name(), waybill.Number)
+	if err != nil {
+		return dto.WaybillResponse{}, err
+	}
+	if !stored {
+		// A concurrent request stored its waybill first; this one is a
+		// duplicate the shop would otherwise be billed for.
+		if err := s.carrier.DeleteWaybill(ctx, waybill); err != nil {
+			s.logger.Printf("waybill %s for order %s was created twice and could not be deleted in %s: %v",
+				waybill.Number, order.ID, s.carrier.Name(), err)
+		}
+
+		return dto.WaybillResponse{}, errx.NewConflict().WithDescription(ErrOrderHasWaybill)
+	}
+
+	return dto.WaybillResponse{
+		OrderID: order.ID,
+		Carrier: s.carrier.Name(),
+		Waybill: waybill,
+	}, nil
+}
+
+// SyncShipmentStatuses asks the carrier where every open parcel is and moves
+// the orders to shipped or delivered accordingly. It returns the number of
+// orders whose status changed.
+func (s *Service) SyncShipmentStatuses(ctx context.Context) (int, error) {
+	orders, err := s.listTrackedOrders(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		updated int
+		errs    []error
+	)
+
+	for start := 0; start < len(orders); start += trackingBatchSize {
+		batch := orders[start:min(start+trackingBatchSize, len(orders))]
+
+		parcels := make([]dto.CarrierTrackingRequest, len(batch))
+		for i, o := range batch {
+			parcels[i] = dto.CarrierTrackingRequest{
+				Number: o.TrackingNumber,
+				Phone:  models.NormalizePhoneNumber(o.PhoneNumber),
+			}
+		}
+
+		trackings, err := s.carrier.TrackShipments(ctx, parcels)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		trackingByNumber := make(map[string]models.ShipmentTracking, len(trackings))
+		for _, t := range trackings {
+			trackingByNumber[t.Number] = t
+		}
+
+		for _, o := range batch {
+			tracking, ok := trackingByNumber[o.TrackingNumber]
+			if !ok {
+				continue
+			}
+
+			changed, err := s.applyShipmentTracking(ctx, o, tracking)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("order %s: %w", o.ID, err))
+				continue
+			}
+			if changed {
+				updated++
+			}
+		}
+	}
+
+	return updated, errors.Join(errs...)
+}
+
+func (s *Service) listTrackedOrders(ctx context.Context) ([]models.Order, error) {
+	var tracked []models.Order
+
+	for page := uint(1); ; page++ {
+		orders, total, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{
+			Statuses:          shipmentSyncStatuses,
+			HasTrackingNumber: true,
+			Limit:             shipmentSyncOrdersPerPage,
+			Page:              page,
+		})
+		if err != nil {
+			if errx.IsCode(err, errx.NotFound) {
+				break
+			}
+
+			return nil, err
+		}
+
+		for _, o := range orders {
+			if o.DeliveryCarrier == s.carrier.Name() {
+				tracked = append(tracked, o)
+			}
+		}
+
+		if int64(page*shipmentSyncOrdersPerPage) >= total {
+			break
+		}
+	}
+
+	return tracked, nil
+}
+
+func (s *Service) applyShipmentTracking(ctx context.Context, order models.Order, tracking models.ShipmentTracking) (bool, error) {
+	steps := shipmentStatusSteps(order.Status, tracking.State.OrderStatus())
+	if len(steps) == 0 {
+		return false, nil
+	}
+
+	comment := fmt.Sprintf("%s: %s", s.carrier.Name(), tracking.StatusText)
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		for _, next := range steps {
+			if err := s.changeOrderStatus(ctx, order.ID, next, models.ActorTypeSystem, s.carrier.Name(), comment); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// shipmentStatusSteps returns the transitions that take an order from current
+// to target, passing through shipped when the parcel was picked up and
+// delivered between two polls.
+func shipmentStatusSteps(current, target models.OrderStatus) []models.OrderStatus {
+	if target == "" || target == current {
+		return nil
+	}
+	if current.CanTransitionTo(target) {
+		return []models.OrderStatus{target}
+	}
+	if current.CanTransitionTo(models.OrderStatusShipped) && models.OrderStatusShipped.CanTransitionTo(target) {
+		return []models.OrderStatus{models.OrderStatusShipped, target}
+	}
+
+	return nil
+}
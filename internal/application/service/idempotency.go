@@ -0,0 +1,57 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/nordew/go-errx"
+)
+
+var (
+	ErrIdempotencyKeyReused = "Idempotency-Key has already been used with a different request"
+)
+
+// errIdempotencyKeyTaken aborts an order transaction that lost the race for
+// its key to a concurrent request.
+var errIdempotencyKeyTaken = errors.New("idempotency key taken")
+
+func hashOrderRequest(input dto.CreateOrderRequest) (string, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return "", errx.NewInternal().WithDescriptionAndCause("failed to hash order request", err)
+	}
+
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayedOrderID returns the order a previous request with this key created.
+// It returns an empty ID for a fresh key and a conflict when the key was used
+// for a different request body.
+func (s *Service) replayedOrderID(ctx context.Context, key, requestHash string) (string, error) {
+	stored, err := s.storage.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		if errx.IsCode(err, errx.NotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	if stored.RequestHash != requestHash {
+		return "", errx.NewConflict().WithDescription(ErrIdempotencyKeyReused)
+	}
+
+	return stored.OrderID, nil
+}
+
+// DeleteExpiredIdempotencyKeys forgets keys whose retry window has passed.
+func (s *Service) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return s.storage.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+}
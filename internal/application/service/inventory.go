@@ -0,0 +1,57 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+)
+
+func (s *Service) ListInventoryMovements(
+	ctx context.Context,
+	filter dto.ListInventoryMovementFilter,
+) (dto.ListInventoryMovementsResponse, error) {
+	movements, total, err := s.storage.ListInventoryMovements(ctx, filter)
+	if err != nil {
+		return dto.ListInventoryMovementsResponse{}, err
+	}
+
+	return dto.ListInventoryMovementsResponse{
+		Movements: movements,
+		Total:     total,
+	}, nil
+}
+
+// ReconcileInventory recomputes every variant's stock from the ledger and
+// reports the variants where it does not match the stored stock.
+func (s *Service) ReconcileInventory(ctx context.Context) (dto.InventoryReconciliationResponse, error) {
+	drifts, err := s.storage.ListInventoryDrifts(ctx)
+	if err != nil {
+		return dto.InventoryReconciliationResponse{}, err
+	}
+
+	return dto.InventoryReconciliationResponse{
+		Consistent: len(drifts) == 0,
+		Drifts:     drifts,
+	}, nil
+}
+
+// recordMovement writes a ledger entry for a stock change. Zero deltas are
+// skipped so no-op adjustments leave no noise.
+func (s *Service) recordMovement(
+	ctx context.Context,
+	variant models.ProductVariant,
+	delta int64,
+	reason models.MovementReason,
+	referenceID, adminID string,
+) error {
+	if delta == 0 {
+		return nil
+	}
+
+	movement, err := models.NewInventoryMovement(variant.ProductID, variant.ID, delta, reason, referenceID, adminID)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.CreateInventoryMovement(ctx, movement)
+}
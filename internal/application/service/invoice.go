@@ -0,0 +1,198 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+)
+
+const (
+	// ibanPaymentProvider records bank transfers an admin confirmed by hand.
+	ibanPaymentProvider = "iban"
+
+	invoicePurposeFmt = "Оплата замовлення № %s"
+)
+
+var (
+	ErrOrderNotIBAN = "Invoices are only issued for IBAN orders"
+)
+
+func (s *Service) orderInvoice(ctx context.Context, order models.Order) (dto.OrderInvoice, error) {
+	if order.PaymentMethod != models.PaymentMethodIBAN {
+		return dto.OrderInvoice{}, errx.NewBadRequest().WithDescription(ErrOrderNotIBAN)
+	}
+
+	orderProducts, err := s.storage.ListOrderLines(ctx, []string{order.ID})
+	if err != nil {
+		return dto.OrderInvoice{}, err
+	}
+
+	items := make([]dto.OrderInvoiceItem, 0, len(orderProducts))
+	for _, op := range orderProducts {
+		items = append(items, dto.OrderInvoiceItem{
+			Brand:     op.Brand,
+			Name:      op.Name,
+			Volume:    op.Volume,
+			Quantity:  op.Quantity,
+			UnitPrice: uint(op.UnitPrice.IntPart()),
+			LineTotal: uint(op.LineTotal.IntPart()),
+		})
+	}
+
+	// The full order ID is the invoice number: a prefix of it is not unique
+	// enough to match a transfer to its order.
+	return dto.OrderInvoice{
+		Number:         order.ID,
+		OrderID:        order.ID,
+		PaymentPurpose: fmt.Sprintf(invoicePurposeFmt, order.ID),
+		Merchant:       s.merchant,
+		FullName:       order.FullName,
+		PhoneNumber:    order.PhoneNumber,
+		Items:          items,
+		Subtotal:       uint(order.Subtotal.IntPart()),
+		DiscountAmount: uint(order.DiscountAmount.IntPart()),
+		AmountToPay:    uint(order.AmountToPay.IntPart()),
+		PaymentStatus:  order.PaymentStatus,
+		CreatedAt:      order.CreatedAt,
+	}, nil
+}
+
+func (s *Service) GetOrderInvoice(ctx context.Context, orderID string) (dto.OrderInvoice, error) {
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{orderID}})
+	if err != nil {
+		return dto.OrderInvoice{}, err
+	}
+
+	return s.orderInvoice(ctx, orders[0])
+}
+
+// RenderOrderInvoice returns the order's invoice as a PDF document.
+func (s *Service) RenderOrderInvoice(ctx context.Context, orderID string) ([]byte, error) {
+	invoice, err := s.GetOrderInvoice(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRenderer.RenderInvoice(invoice)
+}
+
+// RenderTrackedOrderInvoice lets a customer download the invoice of the order
+// a tracking token was issued for.
+func (s *Service) RenderTrackedOrderInvoice(ctx context.Context, token string) ([]byte, error) {
+	order, err := s.orderByTrackingToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.orderInvoice(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRenderer.RenderInvoice(invoice)
+}
+
+// SendOrderInvoice posts the payment instructions to the admins' Telegram so
+// they can be forwarded to the customer.
+func (s *Service) SendOrderInvoice(ctx context.Context, orderID string) error {
+	invoice, err := s.GetOrderInvoice(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.messagingProvider.BroadcastMessage(ctx, buildInvoiceMessage(invoice)); err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to send invoice", err)
+	}
+
+	return nil
+}
+
+func buildInvoiceMessage(invoice dto.OrderInvoice) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("🧾 Рахунок на оплату № %s\n\n", invoice.Number))
+
+	sb.WriteString(fmt.Sprintf("Отримувач: %s\n", invoice.Merchant.Name))
+	sb.WriteString(fmt.Sprintf("РНОКПП / ЄДРПОУ: %s\n", invoice.Merchant.TaxID))
+	sb.WriteString(fmt.Sprintf("IBAN: %s\n", invoice.Merchant.IBAN))
+	if invoice.Merchant.Bank != "" {
+		sb.WriteString(fmt.Sprintf("Банк: %s\n", invoice.Merchant.Bank))
+	}
+
+	sb.WriteString("\nТовари:\n")
+	for _, item := range invoice.Items {
+		sb.WriteString(fmt.Sprintf("- %s %s, %d мл, %d шт., %d грн\n",
+			item.Brand, item.Name, item.Volume, item.Quantity, item.LineTotal))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nСума товарів: %d грн\n", invoice.Subtotal))
+	if invoice.DiscountAmount > 0 {
+		sb.WriteString(fmt.Sprintf("Знижка: %d грн\n", invoice.DiscountAmount))
+	}
+	sb.WriteString(fmt.Sprintf("До сплати: %d грн\n", invoice.AmountToPay))
+	sb.WriteString(fmt.Sprintf("\nПризначення платежу: %s\n", invoice.PaymentPurpose))
+
+	return sb.String()
+}
+
+// MarkOrderInvoicePaid records a bank transfer the admin found on the
+// merchant account. A pending order is confirmed.
+func (s *Service) MarkOrderInvoicePaid(ctx context.Context, input dto.MarkOrderPaidRequest) (dto.OrderPaymentResponse, error) {
+	var payment models.Payment
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if _, err := s.storage.LockOrderStatus(ctx, input.OrderID); err != nil {
+			return err
+		}
+
+		orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{input.OrderID}})
+		if err != nil {
+			return err
+		}
+		order := orders[0]
+
+		if order.PaymentMethod != models.PaymentMethodIBAN {
+			return errx.NewBadRequest().WithDescription(ErrOrderNotIBAN)
+		}
+		if order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusRefunded {
+			return errx.NewConflict().WithDescription(ErrOrderAlreadyPaid)
+		}
+		if order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusReturned {
+			return errx.NewBadRequest().WithDescription(ErrOrderPaymentClosed)
+		}
+
+		payment, err = models.NewPayment(order.ID, ibanPaymentProvider, order.ID, "", order.AmountToPay)
+		if err != nil {
+			return err
+		}
+		payment.Status = models.PaymentStatusPaid
+
+		if err := s.storage.CreatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		comment := input.Comment
+		if comment == "" {
+			comment = paymentReceivedComment
+		}
+
+		return s.applyOrderPaymentStatus(
+			ctx,
+			order.ID,
+			models.PaymentStatusPaid,
+			models.ActorTypeAdmin,
+			input.AdminID,
+			comment,
+		)
+	})
+	if err != nil {
+		return dto.OrderPaymentResponse{}, err
+	}
+
+	return dto.OrderPaymentResponse{Payment: payment}, nil
+}
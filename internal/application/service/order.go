@@ -4,7 +4,9 @@ import (
 	"aroma-hub/internal/application/dto"
 	"aroma-hub/internal/models"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,21 +17,38 @@ import (
 )
 
 var (
-	ErrCreateOrder                    = "Failed to create order"
-	ErrGetProduct                     = "Failed to get product"
-	ErrProductNotFound                = "Product not found"
-	ErrInsufficientStock              = "Insufficient stock"
-	ErrOrderValidation                = "Order validation failed"
-	ErrUpdateStock                    = "Failed to update stock"
-	ErrPersistOrder                   = "Failed to save order"
-	ErrPromoCodeExpired               = "Promo code has expired"
-	ErrOnlyPendingOrdersCanBeCanceled = "only pending orders can be canceled"
+	ErrCreateOrder                   = "Failed to create order"
+	ErrGetProduct                    = "Failed to get product"
+	ErrProductNotFound               = "Product not found"
+	ErrInsufficientStock             = "Insufficient stock"
+	ErrOrderValidation               = "Order validation failed"
+	ErrUpdateStock                   = "Failed to update stock"
+	ErrPersistOrder                  = "Failed to save order"
+	ErrOrderNotFound                 = "Order not found"
+	ErrPromoCodeExpired              = "Promo code has expired"
+	ErrPromoCodeNotFound             = "Promo code not found"
+	ErrPromoCodeNotStarted           = "Promo code is not active yet"
+	ErrPromoCodeNotApplicable        = "Promo code does not apply to any item in the order"
+	ErrPromoCodeUsageLimitReached    = "Promo code usage limit has been reached"
+	ErrPromoCodeCustomerLimitReached = "Promo code has already been used the maximum number of times for this phone number"
 )
 
 type OrderData struct {
-	TotalAmount   decimal.Decimal
-	StockUpdates  map[string]uint
-	OrderProducts []models.OrderProduct
+	IdempotencyKey *models.IdempotencyKey
+	Promocode      *models.Promocode
+	Subtotal       decimal.Decimal
+	DiscountAmount decimal.Decimal
+	TotalAmount    decimal.Decimal
+	Reservations   []StockReservation
+	OrderProducts  []models.OrderProduct
+}
+
+// StockReservation is the stock an order takes from one variant, in the
+// variant's own unit.
+type StockReservation struct {
+	Product models.Product
+	Variant models.ProductVariant
+	Amount  uint
 }
 
 type productInfoResult struct {
@@ -38,21 +57,39 @@ type productInfoResult struct {
 	quantityByID map[string]uint
 }
 
-func (s *Service) CreateOrder(ctx context.Context, input dto.CreateOrderRequest) error {
-	if err := s.validateOrderInput(ctx, input); err != nil {
-		return err
+func (s *Service) CreateOrder(ctx context.Context, input dto.CreateOrderRequest) (dto.Order, error) {
+	var requestHash string
+	if input.IdempotencyKey != "" {
+		hash, err := hashOrderRequest(input)
+		if err != nil {
+			return dto.Order{}, err
+		}
+		requestHash = hash
+
+		replayedID, err := s.replayedOrderID(ctx, input.IdempotencyKey, requestHash)
+		if err != nil {
+			return dto.Order{}, err
+		}
+		if replayedID != "" {
+			return s.getReplayedOrder(ctx, replayedID)
+		}
+	}
+
+	promocode, err := s.validateOrderInput(ctx, input)
+	if err != nil {
+		return dto.Order{}, err
 	}
 
 	productInfo, err := s.prepareProductInfo(ctx, input.ProductItems)
 	if err != nil {
-		return err
+		return dto.Order{}, err
 	}
 
 	orderID := uuid.New().String()
 
-	orderData, err := s.calculateOrderData(input.ProductItems, orderID, productInfo.productByID)
+	orderData, err := s.calculateOrderData(input.ProductItems, orderID, productInfo.productByID, promocode)
 	if err != nil {
-		return err
+		return dto.Order{}, err
 	}
 
 	order, err := models.NewOrder(
@@ -63,37 +100,93 @@ func (s *Service) CreateOrder(ctx context.Context, input dto.CreateOrderRequest)
 		input.PaymentMethod,
 		input.PromoCode,
 		input.ContactType,
-		orderData.TotalAmount,
+		orderData.Subtotal,
+		orderData.DiscountAmount,
 	)
 	if err != nil {
-		return err
+		return dto.Order{}, err
+	}
+
+	if err := order.SetDeliveryPoint(input.DeliveryCityRef, input.DeliveryWarehouseRef); err != nil {
+		return dto.Order{}, err
+	}
+
+	if input.IdempotencyKey != "" {
+		key, err := models.NewIdempotencyKey(input.IdempotencyKey, requestHash, order.ID)
+		if err != nil {
+			return dto.Order{}, err
+		}
+		orderData.IdempotencyKey = &key
 	}
 
 	if err := s.executeOrderTransaction(ctx, order, orderData); err != nil {
-		return err
+		if errors.Is(err, errIdempotencyKeyTaken) {
+			// A concurrent retry placed the order first; answer as it did.
+			replayedID, err := s.replayedOrderID(ctx, input.IdempotencyKey, requestHash)
+			if err != nil {
+				return dto.Order{}, err
+			}
+
+			return s.getReplayedOrder(ctx, replayedID)
+		}
+
+		return dto.Order{}, err
+	}
+
+	orderDTO := toOrderDTO(order, orderData.OrderProducts)
+
+	if order.PaymentMethod == models.PaymentMethodCard {
+		// The order stands even if the provider is down; an admin can
+		// issue the checkout page later.
+		payment, err := s.createPaymentInvoice(ctx, order)
+		if err != nil {
+			s.logger.Printf("failed to create payment invoice for order %s: %v", order.ID, err)
+		} else {
+			orderDTO.PaymentURL = payment.CheckoutURL
+		}
 	}
 
 	go func() {
 		if err := s.broadcastPlacedOrder(ctx, order.ID); err != nil {
-			fmt.Printf("failed to broadcast order: %v\n", err)
+			s.logger.Printf("failed to broadcast order: %v", err)
 		}
 	}()
 
-	return nil
+	return orderDTO, nil
 }
 
-func (s *Service) validateOrderInput(ctx context.Context, input dto.CreateOrderRequest) error {
+// getReplayedOrder answers a repeated create request with the order it
+// created, including the checkout page if it is still unpaid.
+func (s *Service) getReplayedOrder(ctx context.Context, id string) (dto.Order, error) {
+	order, err := s.getOrder(ctx, id)
+	if err != nil {
+		return dto.Order{}, err
+	}
+
+	paymentURL, err := s.pendingPaymentURL(ctx, id)
+	if err != nil {
+		return dto.Order{}, err
+	}
+	order.PaymentURL = paymentURL
+
+	return order, nil
+}
+
+func (s *Service) validateOrderInput(ctx context.Context, input dto.CreateOrderRequest) (*models.Promocode, error) {
 	if len(input.ProductItems) == 0 {
-		return errx.NewBadRequest().WithDescription("order must contain at least one item")
+		return nil, errx.NewBadRequest().WithDescription("order must contain at least one item")
 	}
 
-	if input.PromoCode != "" {
-		if err := s.validatePromoCode(ctx, input.PromoCode); err != nil {
-			return err
-		}
+	if input.PromoCode == "" {
+		return nil, nil
 	}
 
-	return nil
+	promocode, err := s.validatePromoCode(ctx, input.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promocode, nil
 }
 
 func (s *Service) prepareProductInfo(
@@ -140,12 +233,17 @@ func (s *Service) calculateOrderData(
 	productItems []dto.ProductOrder,
 	orderID string,
 	productByID map[string]models.Product,
+	promocode *models.Promocode,
 ) (OrderData, error) {
 	result := OrderData{
-		TotalAmount:   decimal.Zero,
-		StockUpdates:  make(map[string]uint, len(productByID)),
-		OrderProducts: make([]models.OrderProduct, 0, len(productItems)),
+		Promocode:      promocode,
+		Subtotal:       decimal.Zero,
+		DiscountAmount: decimal.Zero,
+		OrderProducts:  make([]models.OrderProduct, 0, len(productItems)),
 	}
+	eligibleAmount := decimal.Zero
+	requestedStock := make(map[string]uint, len(productItems))
+	reservationByVariant := make(map[string]StockReservation, len(productItems))
 
 	for _, productItem := range productItems {
 		product, exists := productByID[productItem.ID]
@@ -154,15 +252,27 @@ func (s *Service) calculateOrderData(
 				fmt.Sprintf("product %s not found", productItem.ID))
 		}
 
-		if err := s.validateProductStock(product, productItem.Quantity); err != nil {
+		variant, err := resolveOrderVariant(product, productItem.VariantID)
+		if err != nil {
+			return OrderData{}, err
+		}
+
+		volume, err := variant.LineVolume(productItem.Volume)
+		if err != nil {
+			return OrderData{}, err
+		}
+
+		requestedStock[variant.ID] += variant.StockUnits(volume, productItem.Quantity)
+		if err := s.validateVariantStock(product, variant, requestedStock[variant.ID]); err != nil {
 			return OrderData{}, err
 		}
 
 		orderProduct, err := models.NewOrderProduct(
 			orderID,
-			product.ID,
+			product,
+			variant,
 			productItem.Quantity,
-			productItem.Volume,
+			volume,
 		)
 		if err != nil {
 			return OrderData{}, fmt.Errorf("creating order product: %w", err)
@@ -170,46 +280,163 @@ func (s *Service) calculateOrderData(
 
 		result.OrderProducts = append(result.OrderProducts, orderProduct)
 
-		itemAmount := product.Price.Mul(decimal.NewFromInt(int64(productItem.Quantity)))
-		result.TotalAmount = result.TotalAmount.Add(itemAmount)
+		itemAmount := orderProduct.LineTotal
+		result.Subtotal = result.Subtotal.Add(itemAmount)
 
-		result.StockUpdates[product.ID] = product.StockAmount - productItem.Quantity
+		if promocode != nil && promocode.AppliesTo(product) {
+			eligibleAmount = eligibleAmount.Add(itemAmount)
+		}
+
+		reservationByVariant[variant.ID] = StockReservation{
+			Product: product,
+			Variant: variant,
+			Amount:  requestedStock[variant.ID],
+		}
 	}
 
+	result.Reservations = make([]StockReservation, 0, len(reservationByVariant))
+	for _, reservation := range reservationByVariant {
+		result.Reservations = append(result.Reservations, reservation)
+	}
+	// A stable order keeps concurrent transactions from locking the same rows
+	// in opposite order.
+	sort.Slice(result.Reservations, func(i, j int) bool {
+		return result.Reservations[i].Variant.ID < result.Reservations[j].Variant.ID
+	})
+
+	if promocode != nil {
+		discount, err := promocodeDiscount(*promocode, result.Subtotal, eligibleAmount)
+		if err != nil {
+			return OrderData{}, err
+		}
+		result.DiscountAmount = discount
+	}
+	result.TotalAmount = result.Subtotal.Sub(result.DiscountAmount)
+
 	return result, nil
 }
 
-func (s *Service) validateProductStock(product models.Product, requestedQuantity uint) error {
-	if product.StockAmount == 0 {
-		return errx.NewBadRequest().WithDescription("product is out of stock")
+// minAmountToPay is what an order costs at least however large its discount,
+// since orders with nothing to pay are rejected.
+var minAmountToPay = decimal.NewFromInt(1)
+
+// promocodeDiscount returns the discount the promocode gives an order with
+// the given subtotal, of which eligibleAmount is spent on items it covers.
+// The discount leaves at least minAmountToPay to pay.
+func promocodeDiscount(promocode models.Promocode, subtotal, eligibleAmount decimal.Decimal) (decimal.Decimal, error) {
+	if subtotal.LessThan(promocode.MinOrderAmount) {
+		return decimal.Zero, errx.NewBadRequest().WithDescription(
+			fmt.Sprintf("promo code %s requires a minimum order amount of %d грн",
+				promocode.Code, promocode.MinOrderAmount.IntPart()))
+	}
+
+	if eligibleAmount.IsZero() {
+		return decimal.Zero, errx.NewBadRequest().WithDescription(ErrPromoCodeNotApplicable)
+	}
+
+	discount := promocode.DiscountFor(eligibleAmount)
+	maxDiscount := decimal.Max(subtotal.Sub(minAmountToPay), decimal.Zero)
+
+	return decimal.Min(discount, maxDiscount), nil
+}
+
+// resolveOrderVariant picks the variant an order line refers to. Lines without
+// a variant are decants poured to order, as older clients send them; they are
+// never matched to a bottle, whatever the product's default variant is.
+func resolveOrderVariant(product models.Product, variantID string) (models.ProductVariant, error) {
+	if variantID == "" {
+		if variant, ok := product.LooseVariant(); ok {
+			return variant, nil
+		}
+
+		return models.ProductVariant{}, errx.NewBadRequest().WithDescription(
+			fmt.Sprintf("product %s is not sold as a decant, choose a variant", product.Name))
+	}
+
+	for _, variant := range product.Variants {
+		if variant.ID == variantID {
+			return variant, nil
+		}
+	}
+
+	return models.ProductVariant{}, errx.NewNotFound().WithDescription(
+		fmt.Sprintf("%s: %s", ErrVariantNotFound, variantID))
+}
+
+func (s *Service) validateVariantStock(product models.Product, variant models.ProductVariant, requested uint) error {
+	if variant.StockAmount == 0 {
+		return errx.NewBadRequest().WithDescription(
+			fmt.Sprintf("product %s (%s) is out of stock", product.Name, variant.SKU))
 	}
 
-	if product.StockAmount < requestedQuantity {
+	if variant.StockAmount < requested {
 		return errx.NewBadRequest().WithDescription(
-			fmt.Sprintf("insufficient stock for product %s: requested %d, available %d",
-				product.Name, requestedQuantity, product.StockAmount))
+			fmt.Sprintf("%s for product %s (%s): requested %d %s, available %d %s",
+				ErrInsufficientStock, product.Name, variant.SKU,
+				requested, stockUnit(variant), variant.StockAmount, stockUnit(variant)))
 	}
 
 	return nil
 }
 
+// reserveStock decrements stock atomically, so an order placed concurrently
+// with this one cannot take the same millilitres or bottles.
+func (s *Service) reserveStock(ctx context.Context, orderID string, reservations []StockReservation) error {
+	for _, r := range reservations {
+		reserved, err := s.storage.ReserveProductVariantStock(ctx, r.Variant.ID, r.Amount)
+		if err != nil {
+			return err
+		}
+
+		if !reserved {
+			return errx.NewBadRequest().WithDescription(
+				fmt.Sprintf("%s for product %s (%s): requested %d %s is no longer available",
+					ErrInsufficientStock, r.Product.Name, r.Variant.SKU, r.Amount, stockUnit(r.Variant)))
+		}
+
+		if err := s.recordMovement(ctx, r.Variant, -int64(r.Amount), models.MovementReasonSale, orderID, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stockUnit(variant models.ProductVariant) string {
+	if variant.IsLoose() {
+		return "ml"
+	}
+
+	return "pcs"
+}
+
 func (s *Service) executeOrderTransaction(
 	ctx context.Context,
 	order models.Order,
 	orderData OrderData,
 ) error {
 	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if orderData.IdempotencyKey != nil {
+			stored, err := s.storage.CreateIdempotencyKey(ctx, *orderData.IdempotencyKey)
+			if err != nil {
+				return err
+			}
+			if !stored {
+				return errIdempotencyKeyTaken
+			}
+		}
+
 		if _, err := s.storage.CreateOrder(ctx, order); err != nil {
 			return err
 		}
 
-		for productID, newStock := range orderData.StockUpdates {
-			if err := s.storage.UpdateProduct(ctx, dto.UpdateProductRequest{
-				ID:          productID,
-				StockAmount: newStock,
-			}); err != nil {
-				return err
-			}
+		placed := models.NewOrderStatusHistory(order.ID, "", order.Status, models.ActorTypeCustomer, "", "")
+		if err := s.storage.CreateOrderStatusHistory(ctx, placed); err != nil {
+			return err
+		}
+
+		if err := s.reserveStock(ctx, order.ID, orderData.Reservations); err != nil {
+			return err
 		}
 
 		for _, product := range orderData.OrderProducts {
@@ -218,26 +445,100 @@ func (s *Service) executeOrderTransaction(
 			}
 		}
 
+		if orderData.Promocode != nil {
+			if err := s.redeemPromocode(ctx, *orderData.Promocode, order); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 }
 
-func (s *Service) validatePromoCode(ctx context.Context, promoCode string) error {
-	promoCodes, _, err := s.storage.ListPromocodes(ctx, dto.ListPromocodeFilter{
-		Code: promoCode,
-	})
-	if err != nil {
+func (s *Service) redeemPromocode(ctx context.Context, promocode models.Promocode, order models.Order) error {
+	// Serialises concurrent checkouts with the same code so limits cannot be overrun.
+	if err := s.storage.LockPromocode(ctx, promocode.ID); err != nil {
 		return err
 	}
-	code := promoCodes[0]
 
-	if code.ExpiresAt.Before(time.Now()) {
-		return errx.NewForbidden().WithDescription(ErrPromoCodeExpired)
+	if err := s.checkPromocodeLimits(ctx, promocode, order.PhoneNumber); err != nil {
+		return err
+	}
+
+	redemption := models.NewPromocodeRedemption(
+		promocode.ID,
+		order.ID,
+		order.PhoneNumber,
+		order.DiscountAmount,
+	)
+
+	return s.storage.CreatePromocodeRedemption(ctx, redemption)
+}
+
+func (s *Service) checkPromocodeLimits(ctx context.Context, promocode models.Promocode, phoneNumber string) error {
+	if promocode.MaxRedemptions > 0 {
+		used, err := s.storage.CountPromocodeRedemptions(ctx, dto.CountPromocodeRedemptionFilter{
+			PromocodeID: promocode.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if uint(used) >= promocode.MaxRedemptions {
+			return errx.NewForbidden().WithDescription(ErrPromoCodeUsageLimitReached)
+		}
+	}
+
+	if promocode.PerCustomerLimit > 0 && phoneNumber != "" {
+		used, err := s.storage.CountPromocodeRedemptions(ctx, dto.CountPromocodeRedemptionFilter{
+			PromocodeID: promocode.ID,
+			PhoneNumber: phoneNumber,
+		})
+		if err != nil {
+			return err
+		}
+
+		if uint(used) >= promocode.PerCustomerLimit {
+			return errx.NewForbidden().WithDescription(ErrPromoCodeCustomerLimitReached)
+		}
 	}
 
 	return nil
 }
 
+func (s *Service) validatePromoCode(ctx context.Context, promoCode string) (models.Promocode, error) {
+	code, err := s.findPromocode(ctx, promoCode)
+	if err != nil {
+		return models.Promocode{}, err
+	}
+
+	if code.IsDeleted() {
+		return models.Promocode{}, errx.NewNotFound().WithDescription(ErrPromoCodeNotFound)
+	}
+	if code.IsExpired() {
+		return models.Promocode{}, errx.NewForbidden().WithDescription(ErrPromoCodeExpired)
+	}
+	if !code.IsStarted() {
+		return models.Promocode{}, errx.NewForbidden().WithDescription(ErrPromoCodeNotStarted)
+	}
+
+	return code, nil
+}
+
+// findPromocode looks a promocode up by its exact code, case-insensitively.
+// Deleted promocodes are returned too; validatePromoCode rejects them.
+func (s *Service) findPromocode(ctx context.Context, promoCode string) (models.Promocode, error) {
+	promocode, err := s.storage.GetPromocodeByCode(ctx, promoCode)
+	if err != nil {
+		if errx.IsCode(err, errx.NotFound) {
+			return models.Promocode{}, errx.NewNotFound().WithDescription(ErrPromoCodeNotFound)
+		}
+		return models.Promocode{}, err
+	}
+
+	return promocode, nil
+}
+
 func (s *Service) broadcastPlacedOrder(ctx context.Context, id string) error {
 	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{
 		IDs: []string{id},
@@ -250,33 +551,25 @@ func (s *Service) broadcastPlacedOrder(ctx context.Context, id string) error {
 	}
 	order := orders[0]
 
-	orderProducts, _, err := s.storage.ListOrderProducts(ctx, dto.ListOrderProductFilter{
-		OrderIDs: []string{id},
-	})
+	orderProducts, err := s.storage.ListOrderLines(ctx, []string{id})
 	if err != nil {
 		return fmt.Errorf("failed to fetch order products: %w", err)
 	}
 
-	productIDs := make([]string, 0, len(orderProducts))
-	for _, op := range orderProducts {
-		productIDs = append(productIDs, op.ProductID)
-	}
-
-	products, _, err := s.storage.ListProducts(ctx, dto.ListProductFilter{
-		IDs: productIDs,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to fetch products: %w", err)
+	message := buildOrderMessage(order, orderProducts)
+	if err := s.messagingProvider.BroadcastMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to broadcast message: %w", err)
 	}
 
-	productMap := make(map[string]models.Product)
-	for _, p := range products {
-		productMap[p.ID] = p
-	}
+	if order.PaymentMethod == models.PaymentMethodIBAN {
+		invoice, err := s.orderInvoice(ctx, order)
+		if err != nil {
+			return fmt.Errorf("failed to build invoice: %w", err)
+		}
 
-	message := buildOrderMessage(order, orderProducts, productMap)
-	if err := s.messagingProvider.BroadcastMessage(ctx, message); err != nil {
-		return fmt.Errorf("failed to broadcast message: %w", err)
+		if err := s.messagingProvider.BroadcastMessage(ctx, buildInvoiceMessage(invoice)); err != nil {
+			return fmt.Errorf("failed to broadcast invoice: %w", err)
+		}
 	}
 
 	return nil
@@ -285,28 +578,28 @@ func (s *Service) broadcastPlacedOrder(ctx context.Context, id string) error {
 func buildOrderMessage(
 	order models.Order,
 	orderProducts []models.OrderProduct,
-	productMap map[string]models.Product,
 ) string {
 	var sb strings.Builder
 
 	sb.WriteString("📦 Нове замовлення!\n\n")
 
-	amountToPay := order.AmountToPay.IntPart()
 	sb.WriteString(fmt.Sprintf("Клієнт: %s\n", order.FullName))
 	sb.WriteString(fmt.Sprintf("Телефон: %s\n", order.PhoneNumber))
 	sb.WriteString(fmt.Sprintf("Адреса: %s\n", order.Address))
 	sb.WriteString(fmt.Sprintf("Спосіб оплати: %s\n", translatePaymentMethod(order.PaymentMethod)))
 	sb.WriteString(fmt.Sprintf("Тип контакту: %s\n", translateContactType(order.ContactType)))
-	sb.WriteString(fmt.Sprintf("Сума до сплати: %d грн\n", amountToPay))
+	sb.WriteString(fmt.Sprintf("Сума товарів: %d грн\n", order.Subtotal.IntPart()))
+	if order.PromoCode != "" {
+		sb.WriteString(fmt.Sprintf("Промокод: %s\n", order.PromoCode))
+		sb.WriteString(fmt.Sprintf("Знижка: %d грн\n", order.DiscountAmount.IntPart()))
+	}
+	sb.WriteString(fmt.Sprintf("Сума до сплати: %d грн\n", order.AmountToPay.IntPart()))
 	sb.WriteString(fmt.Sprintf("Статус: %s\n", translateOrderStatus(order.Status)))
 
 	sb.WriteString("\nТовари:\n")
 	for _, op := range orderProducts {
-		if p, ok := productMap[op.ProductID]; ok {
-			price := p.Price.IntPart()
-
-			sb.WriteString(fmt.Sprintf("- %s, %d шт., %d грн\n", p.Name, op.Quantity, price))
-		}
+		sb.WriteString(fmt.Sprintf("- %s %s, %d мл, %d шт., %d грн\n",
+			op.Brand, op.Name, op.Volume, op.Quantity, op.UnitPrice.IntPart()))
 	}
 
 	sb.WriteString(fmt.Sprintf("\nДата створення: %s\n", formatDateInUkrainian(order.CreatedAt)))
@@ -320,6 +613,8 @@ func translatePaymentMethod(pm models.PaymentMethod) string {
 		return "ФОП"
 	case models.PaymentMethodCashOnDelivery:
 		return "Накладений платіж"
+	case models.PaymentMethodCard:
+		return "Оплата карткою онлайн"
 	default:
 		return string(pm)
 	}
@@ -338,14 +633,24 @@ func translateContactType(ct models.ContactType) string {
 
 func translateOrderStatus(os models.OrderStatus) string {
 	switch os {
-	case "Pending":
+	case models.OrderStatusPending:
 		return "В очікуванні"
-	case "Confirmed":
+	case models.OrderStatusConfirmed:
 		return "Підтверджено"
-	case "Shipped":
+	case models.OrderStatusProcessing:
+		return "В обробці"
+	case models.OrderStatusShipped:
 		return "Відправлено"
-	case "Delivered":
+	case models.OrderStatusDelivered:
 		return "Доставлено"
+	case models.OrderStatusCompleted:
+		return "Виконано"
+	case models.OrderStatusCancelled:
+		return "Скасовано"
+	case models.OrderStatusReturned:
+		return "Повернено"
+	case models.OrderStatusPartiallyReturned:
+		return "Частково повернено"
 	default:
 		return string(os)
 	}
@@ -369,26 +674,11 @@ func (s *Service) ListOrders(ctx context.Context, filter dto.ListOrderFilter) (d
 
 	orderIDs := extractOrderIDs(orders)
 
-	orderProducts, _, err := s.storage.ListOrderProducts(ctx, dto.ListOrderProductFilter{OrderIDs: orderIDs})
-	if err != nil && !errx.IsCode(err, errx.NotFound) {
+	orderProducts, err := s.storage.ListOrderLines(ctx, orderIDs)
+	if err != nil {
 		return dto.OrderResponse{}, fmt.Errorf("failed to list order products: %w", err)
 	}
 
-	productIDs := extractProductIDs(orderProducts)
-
-	products, _, err := s.storage.ListProducts(ctx, dto.ListProductFilter{
-		IDs:   productIDs,
-		Limit: uint(len(productIDs)),
-	})
-	if err != nil && !errx.IsCode(err, errx.NotFound) {
-		return dto.OrderResponse{}, fmt.Errorf("failed to list products: %w", err)
-	}
-
-	productMap := make(map[string]models.Product, len(products))
-	for _, p := range products {
-		productMap[p.ID] = p
-	}
-
 	orderProductsMap := make(map[string][]models.OrderProduct, len(orders))
 	for _, op := range orderProducts {
 		orderProductsMap[op.OrderID] = append(orderProductsMap[op.OrderID], op)
@@ -396,32 +686,7 @@ func (s *Service) ListOrders(ctx context.Context, filter dto.ListOrderFilter) (d
 
 	orderDTOs := make([]dto.Order, len(orders))
 	for i, o := range orders {
-		items := make([]dto.ProductOrder, 0, len(orderProductsMap[o.ID]))
-
-		for _, op := range orderProductsMap[o.ID] {
-			if p, ok := productMap[op.ProductID]; ok {
-				items = append(items, dto.ProductOrder{
-					ID:       p.ID,
-					Name:     p.Name,
-					Brand:    p.Brand,
-					Price:    uint(p.Price.IntPart()),
-					Quantity: op.Quantity,
-					Volume:   op.Volume,
-				})
-			}
-		}
-
-		orderDTOs[i] = dto.Order{
-			ID:            o.ID,
-			FullName:      o.FullName,
-			PhoneNumber:   o.PhoneNumber,
-			Address:       o.Address,
-			PaymentMethod: o.PaymentMethod,
-			Status:        o.Status,
-			CreatedAt:     o.CreatedAt,
-			UpdatedAt:     o.UpdatedAt,
-			Products:      items,
-		}
+		orderDTOs[i] = toOrderDTO(o, orderProductsMap[o.ID])
 	}
 
 	return dto.OrderResponse{
@@ -430,6 +695,98 @@ func (s *Service) ListOrders(ctx context.Context, filter dto.ListOrderFilter) (d
 	}, nil
 }
 
+// getOrder returns a single order with its lines.
+func (s *Service) getOrder(ctx context.Context, id string) (dto.Order, error) {
+	resp, err := s.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{id}})
+	if err != nil {
+		return dto.Order{}, err
+	}
+	if len(resp.Orders) == 0 {
+		return dto.Order{}, errx.NewNotFound().WithDescription(ErrOrderNotFound)
+	}
+
+	return resp.Orders[0], nil
+}
+
+// LookupOrder lets a customer view their order. The phone number must match
+// the one on the order; a mismatch looks the same as a missing order.
+func (s *Service) LookupOrder(ctx context.Context, input dto.LookupOrderRequest) (dto.Order, error) {
+	if input.OrderID == "" || input.PhoneNumber == "" {
+		return dto.Order{}, errx.NewBadRequest().WithDescription("order id and phone number are required")
+	}
+	if _, err := uuid.Parse(input.OrderID); err != nil {
+		return dto.Order{}, errx.NewNotFound().WithDescription(ErrOrderNotFound)
+	}
+
+	order, err := s.getOrder(ctx, input.OrderID)
+	if err != nil {
+		if errx.IsCode(err, errx.NotFound) {
+			return dto.Order{}, errx.NewNotFound().WithDescription(ErrOrderNotFound)
+		}
+
+		return dto.Order{}, err
+	}
+
+	if models.NormalizePhoneNumber(order.PhoneNumber) != models.NormalizePhoneNumber(input.PhoneNumber) {
+		return dto.Order{}, errx.NewNotFound().WithDescription(ErrOrderNotFound)
+	}
+
+	return order, nil
+}
+
+func toOrderDTO(o models.Order, orderProducts []models.OrderProduct) dto.Order {
+	items := make([]dto.ProductOrder, 0, len(orderProducts))
+	for _, op := range orderProducts {
+		items = append(items, dto.ProductOrder{
+			ID:        op.ProductID,
+			VariantID: op.VariantID,
+			SKU:       op.SKU,
+			Name:      op.Name,
+			Brand:     op.Brand,
+			Price:     uint(op.UnitPrice.IntPart()),
+			LineTotal: uint(op.LineTotal.IntPart()),
+			Quantity:  op.Quantity,
+			Volume:    op.Volume,
+
+			ReturnedQuantity: op.ReturnedQuantity,
+		})
+	}
+
+	return dto.Order{
+		ID:             o.ID,
+		FullName:       o.FullName,
+		PhoneNumber:    o.PhoneNumber,
+		Address:        o.Address,
+		PaymentMethod:  o.PaymentMethod,
+		ContactType:    o.ContactType,
+		PromoCode:      o.PromoCode,
+		Subtotal:       uint(o.Subtotal.IntPart()),
+		DiscountAmount: uint(o.DiscountAmount.IntPart()),
+		AmountToPay:    uint(o.AmountToPay.IntPart()),
+		RefundedAmount: uint(o.RefundedAmount.IntPart()),
+		Status:         o.Status,
+		PaymentStatus:  o.PaymentStatus,
+		Delivery:       toOrderDeliveryDTO(o),
+		TrackingToken:  o.TrackingToken,
+		TrackingNumber: o.TrackingNumber,
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+		Products:       items,
+	}
+}
+
+func toOrderDeliveryDTO(o models.Order) *dto.OrderDelivery {
+	if o.DeliveryCityRef == "" && o.DeliveryCarrier == "" {
+		return nil
+	}
+
+	return &dto.OrderDelivery{
+		Carrier:      o.DeliveryCarrier,
+		CityRef:      o.DeliveryCityRef,
+		WarehouseRef: o.DeliveryWarehouseRef,
+	}
+}
+
 func extractOrderIDs(orders []models.Order) []string {
 	ids := make([]string, len(orders))
 
@@ -440,95 +797,143 @@ func extractOrderIDs(orders []models.Order) []string {
 	return ids
 }
 
-func extractProductIDs(orderProducts []models.OrderProduct) []string {
-	set := make(map[string]struct{}, len(orderProducts))
+func (s *Service) orderVariants(
+	ctx context.Context,
+	orderProducts []models.OrderProduct,
+) (map[string]models.ProductVariant, error) {
+	variantMap := make(map[string]models.ProductVariant, len(orderProducts))
+	if len(orderProducts) == 0 {
+		return variantMap, nil
+	}
 
+	variantIDs := make([]string, 0, len(orderProducts))
 	for _, op := range orderProducts {
-		set[op.ProductID] = struct{}{}
+		variantIDs = append(variantIDs, op.VariantID)
+	}
+
+	variants, err := s.storage.ListProductVariants(ctx, dto.ListProductVariantFilter{
+		IDs:           variantIDs,
+		ShowInvisible: true,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	ids := make([]string, 0, len(set))
-	for id := range set {
-		ids = append(ids, id)
+	for _, v := range variants {
+		variantMap[v.ID] = v
 	}
 
-	return ids
+	return variantMap, nil
 }
 
+// UpdateOrder changes the order details and, when a status is given, moves
+// the order through the status state machine in the same transaction.
 func (s *Service) UpdateOrder(ctx context.Context, input dto.UpdateOrderRequest) error {
-	return s.storage.UpdateOrder(ctx, input)
-}
+	if input.Status == "" {
+		return s.storage.UpdateOrder(ctx, input)
+	}
 
-func (s *Service) CancelOrder(ctx context.Context, id string) error {
-	if err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
-		orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{
-			IDs: []string{id},
-		})
-		if err != nil {
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		status := input.Status
+		input.Status = ""
+
+		if err := s.storage.UpdateOrder(ctx, input); err != nil {
 			return err
 		}
-		order := orders[0]
 
-		if order.Status != models.OrderStatusPending {
-			return errx.NewBadRequest().WithDescription(ErrOnlyPendingOrdersCanBeCanceled)
-		}
+		return s.changeOrderStatus(ctx, input.ID, status, models.ActorTypeAdmin, input.AdminID, input.Comment)
+	})
+}
 
-		if err := s.storage.UpdateOrder(ctx, dto.UpdateOrderRequest{
-			ID:     id,
-			Status: models.OrderStatusCancelled,
-		}); err != nil {
-			return fmt.Errorf("updating order status: %w", err)
-		}
+func (s *Service) CancelOrder(ctx context.Context, input dto.CancelOrderRequest) error {
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		return s.cancelOrder(ctx, input.ID, models.ActorTypeAdmin, input.AdminID, input.Comment)
+	})
+}
 
-		if err := s.restoreProductQuantities(ctx, id); err != nil {
-			return err
-		}
+// cancelOrder cancels the order, returning its stock and promocode. It must
+// run inside a transaction.
+func (s *Service) cancelOrder(
+	ctx context.Context,
+	orderID string,
+	actorType models.ActorType,
+	actorID, comment string,
+) error {
+	return s.changeOrderStatus(ctx, orderID, models.OrderStatusCancelled, actorType, actorID, comment)
+}
 
-		return nil
-	}); err != nil {
-		return err
+func (s *Service) ListOrderStatusHistory(ctx context.Context, orderID string) (dto.OrderStatusHistoryResponse, error) {
+	history, err := s.storage.ListOrderStatusHistory(ctx, orderID)
+	if err != nil {
+		return dto.OrderStatusHistoryResponse{}, err
 	}
 
-	return nil
+	return dto.OrderStatusHistoryResponse{History: history}, nil
 }
 
-func (s *Service) restoreProductQuantities(ctx context.Context, orderID string) error {
-	orderProducts, _, err := s.storage.ListOrderProducts(ctx, dto.ListOrderProductFilter{
-		OrderIDs: []string{orderID},
-	})
+// changeOrderStatus validates and applies a status transition, runs its side
+// effects and records it in the history. It must run inside a transaction.
+func (s *Service) changeOrderStatus(
+	ctx context.Context,
+	orderID string,
+	next models.OrderStatus,
+	actorType models.ActorType,
+	actorID, comment string,
+) error {
+	current, err := s.storage.LockOrderStatus(ctx, orderID)
 	if err != nil {
 		return err
 	}
 
-	productIDs := make([]string, 0, len(orderProducts))
-	for i := range orderProducts {
-		productIDs = append(productIDs, orderProducts[i].ProductID)
+	if err := current.ValidateTransition(next); err != nil {
+		return err
 	}
 
-	products, _, err := s.storage.ListProducts(ctx, dto.ListProductFilter{
-		IDs: productIDs,
-	})
+	if err := s.storage.UpdateOrder(ctx, dto.UpdateOrderRequest{
+		ID:     orderID,
+		Status: next,
+	}); err != nil {
+		return fmt.Errorf("updating order status: %w", err)
+	}
+
+	if next == models.OrderStatusCancelled {
+		if err := s.restoreProductQuantities(ctx, orderID, actorID); err != nil {
+			return err
+		}
+
+		if err := s.storage.ReleasePromocodeRedemptions(ctx, orderID); err != nil {
+			return err
+		}
+	}
+
+	entry := models.NewOrderStatusHistory(orderID, current, next, actorType, actorID, comment)
+
+	return s.storage.CreateOrderStatusHistory(ctx, entry)
+}
+
+func (s *Service) restoreProductQuantities(ctx context.Context, orderID, adminID string) error {
+	orderProducts, err := s.storage.ListOrderLines(ctx, []string{orderID})
 	if err != nil {
 		return err
 	}
 
-	productMap := make(map[string]models.Product, len(products))
-	for i := range products {
-		productMap[products[i].ID] = products[i]
+	variantMap, err := s.orderVariants(ctx, orderProducts)
+	if err != nil {
+		return err
 	}
 
 	for _, op := range orderProducts {
-		product, exists := productMap[op.ProductID]
+		variant, exists := variantMap[op.VariantID]
 		if !exists {
 			continue
 		}
 
-		product.StockAmount += op.Quantity
+		amount := variant.StockUnits(op.Volume, op.Quantity)
+		if err := s.storage.RestockProductVariant(ctx, variant.ID, amount); err != nil {
+			return err
+		}
 
-		if err := s.storage.UpdateProduct(ctx, dto.UpdateProductRequest{
-			ID:          product.ID,
-			StockAmount: product.StockAmount,
-		}); err != nil {
+		if err := s.recordMovement(ctx, variant, int64(amount), models.MovementReasonCancellation, orderID, adminID); err != nil {
 			return err
 		}
 	}
@@ -536,7 +941,7 @@ func (s *Service) restoreProductQuantities(ctx context.Context, orderID string)
 	return nil
 }
 
-func (s *Service) DeleteOrder(ctx context.Context, id string) error {
+func (s *Service) DeleteOrder(ctx context.Context, id, adminID string) error {
 	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{
 		IDs: []string{id},
 	})
@@ -545,15 +950,20 @@ func (s *Service) DeleteOrder(ctx context.Context, id string) error {
 	}
 	order := orders[0]
 
-	switch order.Status {
-	case models.OrderStatusPending:
-		if err := s.CancelOrder(ctx, id); err != nil {
+	switch {
+	case order.Status.CanTransitionTo(models.OrderStatusCancelled):
+		if err := s.CancelOrder(ctx, dto.CancelOrderRequest{ID: id, AdminID: adminID}); err != nil {
 			return err
 		}
-	case models.OrderStatusCancelled:
+	case order.Status == models.OrderStatusCancelled:
 		return errx.NewBadRequest().WithDescription("order already cancelled")
-	case models.OrderStatusCompleted:
+	case order.Status == models.OrderStatusCompleted:
 		return errx.NewBadRequest().WithDescription("order already completed")
+	default:
+		// Shipped and returned orders have left the warehouse; deleting them
+		// would lose the stock and money they moved.
+		return errx.NewBadRequest().WithDescription(
+			fmt.Sprintf("order in status %s cannot be deleted", order.Status))
 	}
 
 	err = s.storage.DeleteOrder(ctx, id)
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrOrderNotEditable     = "Only orders that have not been shipped can be edited"
+	ErrPaidOrderNotEditable = "Paid orders cannot be edited"
+)
+
+// orderLineKey identifies an order line: a variant sold at one volume.
+type orderLineKey struct {
+	variantID string
+	volume    uint
+}
+
+// UpdateOrderItems replaces the lines of an order that has not shipped yet.
+// Lines that stay keep the price they were sold at and new lines take the
+// current price. Stock moves by the difference, and the promocode discount
+// and amount to pay are recalculated. An open checkout page still asks for
+// the old amount, so it is voided and a new one is issued.
+func (s *Service) UpdateOrderItems(ctx context.Context, input dto.UpdateOrderItemsRequest) (dto.Order, error) {
+	if len(input.Items) == 0 {
+		return dto.Order{}, errx.NewBadRequest().WithDescription("order must contain at least one item")
+	}
+
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{input.OrderID}})
+	if err != nil {
+		return dto.Order{}, err
+	}
+	if err := checkOrderEditable(orders[0]); err != nil {
+		return dto.Order{}, err
+	}
+
+	invoiceCancelled, err := s.cancelOpenPayments(ctx, input.OrderID)
+	if err != nil {
+		return dto.Order{}, err
+	}
+
+	err = s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if _, err := s.storage.LockOrderStatus(ctx, input.OrderID); err != nil {
+			return err
+		}
+
+		orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{input.OrderID}})
+		if err != nil {
+			return err
+		}
+		order := orders[0]
+
+		if err := checkOrderEditable(order); err != nil {
+			return err
+		}
+
+		oldLines, err := s.storage.ListOrderLines(ctx, []string{order.ID})
+		if err != nil {
+			return err
+		}
+
+		productByID, err := s.editedOrderProducts(ctx, input.Items, oldLines)
+		if err != nil {
+			return err
+		}
+
+		newLines, err := buildEditedOrderLines(order.ID, input.Items, oldLines, productByID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.adjustEditedOrderStock(ctx, order.ID, input.AdminID, oldLines, newLines); err != nil {
+			return err
+		}
+
+		if err := s.storage.DeleteOrderProducts(ctx, order.ID); err != nil {
+			return err
+		}
+		for _, line := range newLines {
+			if err := s.storage.CreateOrderProduct(ctx, line); err != nil {
+				return err
+			}
+		}
+
+		return s.recalculateOrderAmounts(ctx, order, newLines, productByID)
+	})
+
+	// Whether or not the edit went through, the voided page needs a
+	// replacement for what the order costs now.
+	var paymentURL string
+	if invoiceCancelled {
+		paymentURL = s.reissueOrderPayment(ctx, input.OrderID)
+	}
+
+	if err != nil {
+		return dto.Order{}, err
+	}
+
+	order, err := s.getOrder(ctx, input.OrderID)
+	if err != nil {
+		return dto.Order{}, err
+	}
+	order.PaymentURL = paymentURL
+
+	return order, nil
+}
+
+// checkOrderEditable reports why the order's lines cannot change, if they
+// cannot.
+func checkOrderEditable(order models.Order) error {
+	if !order.Status.IsEditable() {
+		return errx.NewBadRequest().WithDescription(ErrOrderNotEditable)
+	}
+	if order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusRefunded {
+		return errx.NewBadRequest().WithDescription(ErrPaidOrderNotEditable)
+	}
+
+	return nil
+}
+
+// reissueOrderPayment opens a checkout page for the order's current amount
+// and returns its URL. The order stands without one if the provider is
+// down; an admin can issue it later.
+func (s *Service) reissueOrderPayment(ctx context.Context, orderID string) string {
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{orderID}})
+	if err == nil {
+		var payment models.Payment
+		payment, err = s.createPaymentInvoice(ctx, orders[0])
+		if err == nil {
+			return payment.CheckoutURL
+		}
+	}
+
+	s.logger.Printf("failed to reissue payment invoice for order %s: %v", orderID, err)
+
+	return ""
+}
+
+// editedOrderProducts loads the catalogue entries of the products the order
+// has and will have, hidden ones included.
+func (s *Service) editedOrderProducts(
+	ctx context.Context,
+	items []dto.ProductOrder,
+	oldLines []models.OrderProduct,
+) (map[string]models.Product, error) {
+	idSet := make(map[string]struct{}, len(items)+len(oldLines))
+	for _, item := range items {
+		idSet[item.ID] = struct{}{}
+	}
+	for _, line := range oldLines {
+		idSet[line.ProductID] = struct{}{}
+	}
+
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+
+	products, _, err := s.storage.ListProducts(ctx, dto.ListProductFilter{
+		IDs:           ids,
+		ShowInvisible: true,
+		Limit:         uint(len(ids)),
+	})
+	if err != nil && !errx.IsCode(err, errx.NotFound) {
+		return nil, err
+	}
+
+	productByID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		productByID[p.ID] = p
+	}
+
+	return productByID, nil
+}
+
+// buildEditedOrderLines turns the requested items into order lines, keeping
+// the original price of lines the order already had.
+func buildEditedOrderLines(
+	orderID string,
+	items []dto.ProductOrder,
+	oldLines []models.OrderProduct,
+	productByID map[string]models.Product,
+) ([]models.OrderProduct, error) {
+	oldByKey := make(map[orderLineKey]models.OrderProduct, len(oldLines))
+	for _, line := range oldLines {
+		oldByKey[orderLineKey{line.VariantID, line.Volume}] = line
+	}
+
+	lines := make([]models.OrderProduct, 0, len(items))
+	indexByKey := make(map[orderLineKey]int, len(items))
+
+	for _, item := range items {
+		if item.Quantity == 0 {
+			return nil, models.ErrInvalidQuantity
+		}
+
+		product, ok := productByID[item.ID]
+		if !ok {
+			return nil, errx.NewNotFound().WithDescription(
+				fmt.Sprintf("%s: %s", ErrProductNotFound, item.ID))
+		}
+
+		variant, err := resolveOrderVariant(product, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+
+		volume, err := variant.LineVolume(item.Volume)
+		if err != nil {
+			return nil, err
+		}
+
+		key := orderLineKey{variant.ID, volume}
+		if i, ok := indexByKey[key]; ok {
+			lines[i].Quantity += item.Quantity
+			lines[i].LineTotal = lines[i].UnitPrice.Mul(decimal.NewFromInt(int64(lines[i].Quantity)))
+			continue
+		}
+
+		line, ok := oldByKey[key]
+		if ok {
+			line.Quantity = item.Quantity
+			line.LineTotal = line.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		} else {
+			line, err = models.NewOrderProduct(orderID, product, variant, item.Quantity, volume)
+			if err != nil {
+				return nil, fmt.Errorf("creating order product: %w", err)
+			}
+		}
+
+		indexByKey[key] = len(lines)
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// adjustEditedOrderStock reserves the extra stock the edited order needs and
+// returns what it no longer needs. It must run inside a transaction.
+func (s *Service) adjustEditedOrderStock(
+	ctx context.Context,
+	orderID, adminID string,
+	oldLines, newLines []models.OrderProduct,
+) error {
+	variantMap, err := s.orderVariants(ctx, append(append([]models.OrderProduct{}, oldLines...), newLines...))
+	if err != nil {
+		return err
+	}
+
+	deltas := make(map[string]int64)
+	for _, line := range oldLines {
+		if variant, ok := variantMap[line.VariantID]; ok {
+			deltas[variant.ID] -= int64(variant.StockUnits(line.Volume, line.Quantity))
+		}
+	}
+	for _, line := range newLines {
+		if variant, ok := variantMap[line.VariantID]; ok {
+			deltas[variant.ID] += int64(variant.StockUnits(line.Volume, line.Quantity))
+		}
+	}
+
+	// A stable order keeps concurrent transactions from locking the same rows
+	// in opposite order.
+	variantIDs := make([]string, 0, len(deltas))
+	for id := range deltas {
+		variantIDs = append(variantIDs, id)
+	}
+	sort.Strings(variantIDs)
+
+	for _, id := range variantIDs {
+		delta := deltas[id]
+		variant := variantMap[id]
+
+		switch {
+		case delta > 0:
+			reserved, err := s.storage.ReserveProductVariantStock(ctx, id, uint(delta))
+			if err != nil {
+				return err
+			}
+			if !reserved {
+				return errx.NewBadRequest().WithDescription(
+					fmt.Sprintf("%s for %s: requested %d %s more, available %d %s",
+						ErrInsufficientStock, variant.SKU, delta, stockUnit(variant), variant.StockAmount, stockUnit(variant)))
+			}
+
+			if err := s.recordMovement(ctx, variant, -delta, models.MovementReasonSale, orderID, adminID); err != nil {
+				return err
+			}
+		case delta < 0:
+			if err := s.storage.RestockProductVariant(ctx, id, uint(-delta)); err != nil {
+				return err
+			}
+
+			if err := s.recordMovement(ctx, variant, -delta, models.MovementReasonCancellation, orderID, adminID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// recalculateOrderAmounts stores the order's new subtotal, promocode discount
+// and amount to pay. It must run inside a transaction.
+func (s *Service) recalculateOrderAmounts(
+	ctx context.Context,
+	order models.Order,
+	lines []models.OrderProduct,
+	productByID map[string]models.Product,
+) error {
+	var promocode *models.Promocode
+	if order.PromoCode != "" {
+		// The code was valid when the order was placed, so it still applies
+		// after it expired or was deleted; findPromocode returns both.
+		code, err := s.findPromocode(ctx, order.PromoCode)
+		if err != nil {
+			return err
+		}
+		promocode = &code
+	}
+
+	subtotal, eligible := decimal.Zero, decimal.Zero
+	for _, line := range lines {
+		subtotal = subtotal.Add(line.LineTotal)
+
+		if promocode != nil && promocode.AppliesTo(productByID[line.ProductID]) {
+			eligible = eligible.Add(line.LineTotal)
+		}
+	}
+
+	discount := decimal.Zero
+	if promocode != nil {
+		var err error
+		discount, err = promocodeDiscount(*promocode, subtotal, eligible)
+		if err != nil {
+			return err
+		}
+
+		if err := s.storage.UpdatePromocodeRedemptionDiscount(ctx, order.ID, discount); err != nil {
+			return err
+		}
+	}
+
+	amountToPay := subtotal.Sub(discount)
+	if !amountToPay.IsPositive() {
+		return errx.NewValidation().WithDescription(models.ErrAmountToPayInvalid)
+	}
+
+	return s.storage.SetOrderAmounts(ctx, order.ID, subtotal, discount, amountToPay)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+)
+
+const (
+	orderExpiryActor        = "order-expiry"
+	orderExpiredComment     = "Не оплачено вчасно"
+	expiryOrdersPerPage     = 100
+	expiryMaxOrdersInNotice = 50
+)
+
+// ExpirePendingOrders cancels the unpaid pending orders the request selects,
+// returning their stock and promocodes, and tells the admins which orders
+// were cancelled. It returns how many orders it cancelled.
+func (s *Service) ExpirePendingOrders(ctx context.Context, input dto.ExpirePendingOrdersRequest) (int, error) {
+	candidates, err := s.listExpiredOrders(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		expired []models.Order
+		errs    []error
+	)
+
+	for _, o := range candidates {
+		cancelled, err := s.expireOrder(ctx, o.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", o.ID, err))
+		}
+		if cancelled {
+			expired = append(expired, o)
+		}
+	}
+
+	if len(expired) > 0 {
+		if err := s.messagingProvider.BroadcastMessage(ctx, buildExpiredOrdersMessage(expired)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to broadcast expired orders: %w", err))
+		}
+	}
+
+	return len(expired), errors.Join(errs...)
+}
+
+func (s *Service) listExpiredOrders(ctx context.Context, input dto.ExpirePendingOrdersRequest) ([]models.Order, error) {
+	var expired []models.Order
+
+	for page := uint(1); ; page++ {
+		orders, total, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{
+			Status:        models.OrderStatusPending,
+			PaymentMethod: input.PaymentMethod,
+			ToDate:        &input.CreatedBefore,
+			Limit:         expiryOrdersPerPage,
+			Page:          page,
+		})
+		if err != nil {
+			if errx.IsCode(err, errx.NotFound) {
+				break
+			}
+
+			return nil, err
+		}
+
+		for _, o := range orders {
+			if o.PaymentStatus != models.PaymentStatusPaid {
+				expired = append(expired, o)
+			}
+		}
+
+		if int64(page*expiryOrdersPerPage) >= total {
+			break
+		}
+	}
+
+	return expired, nil
+}
+
+// expireOrder cancels the order unless it was paid or moved on since it was
+// listed, then voids its open checkout pages. A page that cannot be voided
+// is reported; a payment that still arrives on it is refunded by the
+// webhook.
+func (s *Service) expireOrder(ctx context.Context, orderID string) (bool, error) {
+	var cancelled bool
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		current, err := s.storage.LockOrderStatus(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if current != models.OrderStatusPending {
+			return nil
+		}
+
+		orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{orderID}})
+		if err != nil {
+			return err
+		}
+		if orders[0].PaymentStatus == models.PaymentStatusPaid {
+			return nil
+		}
+
+		if err := s.cancelOrder(ctx, orderID, models.ActorTypeSystem, orderExpiryActor, orderExpiredComment); err != nil {
+			return err
+		}
+		cancelled = true
+
+		return nil
+	})
+	if err != nil || !cancelled {
+		return false, err
+	}
+
+	if _, err := s.cancelOpenPayments(ctx, orderID); err != nil {
+		return true, fmt.Errorf("failed to void checkout page: %w", err)
+	}
+
+	return true, nil
+}
+
+func buildExpiredOrdersMessage(orders []models.Order) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("⌛ Скасовано неоплачені замовлення: %d\n\n", len(orders)))
+
+	for i, o := range orders {
+		if i == expiryMaxOrdersInNotice {
+			sb.WriteString(fmt.Sprintf("…та ще %d\n", len(orders)-i))
+			break
+		}
+
+		sb.WriteString(fmt.Sprintf("- № %s, %s, %s, %d грн (%s), від %s\n",
+			o.ID, o.FullName, o.PhoneNumber, o.AmountToPay.IntPart(),
+			translatePaymentMethod(o.PaymentMethod), formatDateInUkrainian(o.CreatedAt)))
+	}
+
+	return sb.String()
+}
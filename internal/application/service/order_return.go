@@ -0,0 +1,277 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrOrderNotReturnable     = "Only shipped, delivered or completed orders can be returned"
+	ErrReturnLineNotFound     = "Returned item is not in the order"
+	ErrReturnQuantityExceeded = "Returned quantity exceeds what is left to return"
+	ErrReturnRefundExceeded   = "Refund exceeds the amount the customer paid"
+)
+
+// CreateOrderReturn records goods a customer sent back. Returned items can be
+// put back in stock, and the refund is sent through the payment provider when
+// the order was paid online. The order becomes returned once every item has
+// come back and partially returned until then.
+func (s *Service) CreateOrderReturn(ctx context.Context, input dto.CreateOrderReturnRequest) (dto.OrderReturnResponse, error) {
+	items := make([]models.OrderReturnItem, 0, len(input.Items))
+	for _, item := range input.Items {
+		items = append(items, models.OrderReturnItem{
+			VariantID: item.VariantID,
+			Volume:    item.Volume,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	ret, err := models.NewOrderReturn(
+		input.OrderID,
+		input.AdminID,
+		items,
+		input.Restock,
+		decimal.NewFromInt(int64(input.RefundAmount)),
+		input.Comment,
+	)
+	if err != nil {
+		return dto.OrderReturnResponse{}, err
+	}
+
+	var (
+		status    models.OrderStatus
+		refund    *models.PaymentRefund
+		invoiceID string
+	)
+
+	err = s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		current, err := s.storage.LockOrderStatus(ctx, ret.OrderID)
+		if err != nil {
+			return err
+		}
+
+		lines, err := s.checkOrderReturn(ctx, ret)
+		if err != nil {
+			return err
+		}
+
+		var payment *models.Payment
+		if ret.RefundAmount.IsPositive() {
+			payment, err = s.lockReturnPayment(ctx, ret)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.returnOrderItems(ctx, ret, lines); err != nil {
+			return err
+		}
+
+		if err := s.storage.CreateOrderReturn(ctx, ret); err != nil {
+			return err
+		}
+
+		if ret.RefundAmount.IsPositive() {
+			if payment != nil && payment.Provider == s.paymentProvider.Name() {
+				// The provider is asked once the return is committed; until
+				// then the refund only holds its share of the payment.
+				pending, err := models.NewPaymentRefund(*payment, ret.ID, ret.RefundAmount, models.ActorTypeAdmin, ret.AdminID)
+				if err != nil {
+					return err
+				}
+				if err := s.storage.CreatePaymentRefund(ctx, pending); err != nil {
+					return err
+				}
+
+				refund, invoiceID = &pending, payment.InvoiceID
+			} else {
+				if err := s.storage.AddOrderRefundedAmount(ctx, ret.OrderID, ret.RefundAmount); err != nil {
+					return err
+				}
+
+				if payment != nil {
+					if _, err := s.recordPaymentRefund(ctx, *payment, ret.RefundAmount, models.ActorTypeAdmin, ret.AdminID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		status = models.OrderStatusReturned
+		for _, line := range lines {
+			if line.ReturnableQuantity() > returnedQuantity(ret, line) {
+				status = models.OrderStatusPartiallyReturned
+				break
+			}
+		}
+
+		if status == current {
+			return nil
+		}
+
+		return s.changeOrderStatus(ctx, ret.OrderID, status, models.ActorTypeAdmin, ret.AdminID, ret.Comment)
+	})
+	if err != nil {
+		return dto.OrderReturnResponse{}, err
+	}
+
+	response := dto.OrderReturnResponse{Return: ret, Status: status}
+	if refund == nil {
+		return response, nil
+	}
+
+	// The return stands even when the provider rejects the refund; the
+	// response carries the failed refund so it can be retried.
+	settled, _, err := s.sendPaymentRefund(ctx, *refund, invoiceID)
+	if err != nil && settled.Status != models.PaymentRefundStatusFailed {
+		return dto.OrderReturnResponse{}, err
+	}
+	response.Refund = &settled
+
+	return response, nil
+}
+
+// lockReturnPayment locks the order's completed payment, if any, and checks
+// the return's refund fits in what is left of it. It must run under the
+// order lock.
+func (s *Service) lockReturnPayment(ctx context.Context, ret models.OrderReturn) (*models.Payment, error) {
+	paid, err := s.paidPayment(ctx, ret.OrderID)
+	if err != nil || paid == nil {
+		return nil, err
+	}
+
+	payment, err := s.storage.LockPaymentByInvoiceID(ctx, paid.Provider, paid.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	refundable, err := s.refundableAmount(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if ret.RefundAmount.GreaterThan(refundable) {
+		return nil, errx.NewBadRequest().WithDescription(ErrReturnRefundExceeded)
+	}
+
+	return &payment, nil
+}
+
+func (s *Service) ListOrderReturns(ctx context.Context, orderID string) (dto.ListOrderReturnsResponse, error) {
+	returns, err := s.storage.ListOrderReturns(ctx, orderID)
+	if err != nil {
+		return dto.ListOrderReturnsResponse{}, err
+	}
+
+	return dto.ListOrderReturnsResponse{Returns: returns}, nil
+}
+
+// checkOrderReturn validates a return against the order as it is now and
+// returns the order's lines.
+func (s *Service) checkOrderReturn(ctx context.Context, ret models.OrderReturn) ([]models.OrderProduct, error) {
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{ret.OrderID}})
+	if err != nil {
+		return nil, err
+	}
+	order := orders[0]
+
+	if !order.Status.IsReturnable() {
+		return nil, errx.NewBadRequest().WithDescription(ErrOrderNotReturnable)
+	}
+
+	pending, err := s.storage.PendingRefundAmount(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if ret.RefundAmount.GreaterThan(order.AmountToPay.Sub(order.RefundedAmount).Sub(pending)) {
+		return nil, errx.NewBadRequest().WithDescription(ErrReturnRefundExceeded)
+	}
+
+	lines, err := s.storage.ListOrderLines(ctx, []string{ret.OrderID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range ret.Items {
+		line, ok := findOrderLine(lines, item.VariantID, item.Volume)
+		if !ok {
+			return nil, errx.NewBadRequest().WithDescription(
+				fmt.Sprintf("%s: %s, %d ml", ErrReturnLineNotFound, item.VariantID, item.Volume))
+		}
+		if item.Quantity > line.ReturnableQuantity() {
+			return nil, errx.NewBadRequest().WithDescription(
+				fmt.Sprintf("%s: %s %s, %d left", ErrReturnQuantityExceeded, line.Brand, line.Name, line.ReturnableQuantity()))
+		}
+	}
+
+	return lines, nil
+}
+
+// returnOrderItems counts the items as returned and puts them back in stock
+// when the return asks for it. It must run inside a transaction.
+func (s *Service) returnOrderItems(ctx context.Context, ret models.OrderReturn, lines []models.OrderProduct) error {
+	var variantMap map[string]models.ProductVariant
+	if ret.Restock {
+		var err error
+		variantMap, err = s.orderVariants(ctx, lines)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range ret.Items {
+		returned, err := s.storage.ReturnOrderProduct(ctx, ret.OrderID, item.VariantID, item.Volume, item.Quantity)
+		if err != nil {
+			return err
+		}
+		if !returned {
+			return errx.NewBadRequest().WithDescription(ErrReturnQuantityExceeded)
+		}
+
+		if !ret.Restock {
+			continue
+		}
+
+		variant, exists := variantMap[item.VariantID]
+		if !exists {
+			continue
+		}
+
+		amount := variant.StockUnits(item.Volume, item.Quantity)
+		if err := s.storage.RestockProductVariant(ctx, variant.ID, amount); err != nil {
+			return err
+		}
+
+		if err := s.recordMovement(ctx, variant, int64(amount), models.MovementReasonReturn, ret.ID, ret.AdminID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findOrderLine(lines []models.OrderProduct, variantID string, volume uint) (models.OrderProduct, bool) {
+	for _, line := range lines {
+		if line.VariantID == variantID && line.Volume == volume {
+			return line, true
+		}
+	}
+
+	return models.OrderProduct{}, false
+}
+
+// returnedQuantity is how many items of the line the return brings back.
+func returnedQuantity(ret models.OrderReturn, line models.OrderProduct) uint {
+	for _, item := range ret.Items {
+		if item.VariantID == line.VariantID && item.Volume == line.Volume {
+			return item.Quantity
+		}
+	}
+
+	return 0
+}
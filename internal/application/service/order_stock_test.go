@@ -0,0 +1,220 @@
+package service_test
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/application/service"
+	"aroma-hub/internal/config"
+	"aroma-hub/internal/infrastructure/adapters/storage"
+	"aroma-hub/internal/models"
+	"aroma-hub/pkg/client/db/pgsql"
+	"context"
+	"log"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+// The tests below need a disposable PostgreSQL database; they migrate it
+// and leave their rows behind only if cleanup fails.
+const testPostgresDSNEnv = "TEST_POSTGRES_DSN"
+
+const concurrentBuyers = 20
+
+type silentMessenger struct{}
+
+func (silentMessenger) BroadcastMessage(context.Context, string) error { return nil }
+
+func (silentMessenger) DeepLink(string) string { return "" }
+
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(testPostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testPostgresDSNEnv)
+	}
+
+	ctx := context.Background()
+	cfg := config.Postgres{DSN: dsn, MigrationsDir: "../../../migrations"}
+
+	pool := pgsql.MustConnect(ctx, cfg)
+	pgsql.MustMigrate(ctx, pool, cfg)
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// createLastItem stores a visible product with one fixed-size variant of
+// which a single piece is left.
+func createLastItem(t *testing.T, pool *pgxpool.Pool, store *storage.Storage) (models.Product, models.ProductVariant) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	var categoryID string
+	err := pool.QueryRow(ctx, "INSERT INTO categories (name) VALUES ($1) RETURNING id", "test-"+uuid.NewString()).
+		Scan(&categoryID)
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	product, err := models.NewProduct(
+		uuid.NewString(),
+		categoryID,
+		"Test",
+		"Last bottle",
+		"",
+		"",
+		"",
+		decimal.NewFromInt(1000),
+		decimal.Zero,
+		decimal.Zero,
+		false,
+	)
+	if err != nil {
+		t.Fatalf("new product: %v", err)
+	}
+	if err := store.CreateProduct(ctx, product); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "UPDATE products SET visible = TRUE WHERE id = $1", product.ID); err != nil {
+		t.Fatalf("show product: %v", err)
+	}
+
+	variant, err := models.NewProductVariant(product.ID, "", 100, decimal.NewFromInt(1000), 1, true)
+	if err != nil {
+		t.Fatalf("new variant: %v", err)
+	}
+	if err := store.CreateProductVariant(ctx, variant); err != nil {
+		t.Fatalf("create variant: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		_, _ = pool.Exec(ctx, "DELETE FROM orders WHERE id IN (SELECT order_id FROM order_products WHERE variant_id = $1)", variant.ID)
+		_, _ = pool.Exec(ctx, "DELETE FROM products WHERE id = $1", product.ID)
+		_, _ = pool.Exec(ctx, "DELETE FROM categories WHERE id = $1", categoryID)
+	})
+
+	return product, variant
+}
+
+func variantStock(t *testing.T, pool *pgxpool.Pool, id string) int {
+	t.Helper()
+
+	var stock int
+	if err := pool.QueryRow(context.Background(), "SELECT stock_amount FROM product_variants WHERE id = $1", id).
+		Scan(&stock); err != nil {
+		t.Fatalf("read stock: %v", err)
+	}
+
+	return stock
+}
+
+func TestReserveProductVariantStockConcurrent(t *testing.T) {
+	pool := testPool(t)
+	store := storage.NewStorage(pool)
+	_, variant := createLastItem(t, pool, store)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+	)
+
+	for range concurrentBuyers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ok, err := store.ReserveProductVariantStock(context.Background(), variant.ID, 1)
+			if err != nil {
+				t.Errorf("reserve stock: %v", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 1 {
+		t.Fatalf("reserved %d times, want exactly 1", reserved)
+	}
+	if stock := variantStock(t, pool, variant.ID); stock != 0 {
+		t.Fatalf("stock left %d, want 0", stock)
+	}
+}
+
+func TestCreateOrderConcurrentLastItem(t *testing.T) {
+	pool := testPool(t)
+	store := storage.NewStorage(pool)
+	product, variant := createLastItem(t, pool, store)
+
+	svc := service.NewService(
+		store,
+		pgxtransactor.NewTransactor(pool),
+		nil,
+		nil,
+		silentMessenger{},
+		nil,
+		nil,
+		nil,
+		dto.MerchantRequisites{},
+		nil,
+		nil,
+		"",
+		log.Default(),
+	)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		placed  int
+		refused int
+	)
+
+	for range concurrentBuyers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := svc.CreateOrder(context.Background(), dto.CreateOrderRequest{
+				FullName:      "Test Buyer",
+				PhoneNumber:   "+380501234567",
+				Address:       "Kyiv",
+				PaymentMethod: models.PaymentMethodCashOnDelivery,
+				ContactType:   models.ContactTypePhone,
+				ProductItems: []dto.ProductOrder{{
+					ID:        product.ID,
+					VariantID: variant.ID,
+					Quantity:  1,
+				}},
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				refused++
+				return
+			}
+			placed++
+		}()
+	}
+	wg.Wait()
+
+	if placed != 1 {
+		t.Fatalf("placed %d orders, want exactly 1 (%d refused)", placed, refused)
+	}
+	if stock := variantStock(t, pool, variant.ID); stock != 0 {
+		t.Fatalf("stock left %d, want 0", stock)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/nordew/go-errx"
+)
+
+var (
+	ErrTrackingTokenNotFound = "Tracking token is invalid or has been revoked"
+)
+
+// TrackOrder shows a customer the progress of the order the token was issued
+// for.
+func (s *Service) TrackOrder(ctx context.Context, token string) (dto.OrderTrackingResponse, error) {
+	order, err := s.orderByTrackingToken(ctx, token)
+	if err != nil {
+		return dto.OrderTrackingResponse{}, err
+	}
+
+	orderProducts, err := s.storage.ListOrderLines(ctx, []string{order.ID})
+	if err != nil {
+		return dto.OrderTrackingResponse{}, err
+	}
+
+	history, err := s.storage.ListOrderStatusHistory(ctx, order.ID)
+	if err != nil {
+		return dto.OrderTrackingResponse{}, err
+	}
+
+	events := make([]dto.OrderTrackingEvent, 0, len(history))
+	for _, h := range history {
+		events = append(events, dto.OrderTrackingEvent{
+			Status:    h.ToStatus,
+			CreatedAt: h.CreatedAt,
+		})
+	}
+
+	orderDTO := toOrderDTO(order, orderProducts)
+
+	return dto.OrderTrackingResponse{
+		Status:         orderDTO.Status,
+		PaymentStatus:  orderDTO.PaymentStatus,
+		TrackingNumber: orderDTO.TrackingNumber,
+		Subtotal:       orderDTO.Subtotal,
+		DiscountAmount: orderDTO.DiscountAmount,
+		AmountToPay:    orderDTO.AmountToPay,
+		Products:       orderDTO.Products,
+		History:        events,
+		CreatedAt:      orderDTO.CreatedAt,
+	}, nil
+}
+
+// orderByTrackingToken finds the order a tracking token was issued for.
+// Unknown, malformed and revoked tokens all look the same.
+func (s *Service) orderByTrackingToken(ctx context.Context, token string) (models.Order, error) {
+	if !models.IsTrackingTokenFormat(token) {
+		return models.Order{}, errx.NewNotFound().WithDescription(ErrTrackingTokenNotFound)
+	}
+
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{TrackingToken: token, Limit: 1})
+	if err != nil {
+		if errx.IsCode(err, errx.NotFound) {
+			return models.Order{}, errx.NewNotFound().WithDescription(ErrTrackingTokenNotFound)
+		}
+
+		return models.Order{}, err
+	}
+	if len(orders) == 0 {
+		return models.Order{}, errx.NewNotFound().WithDescription(ErrTrackingTokenNotFound)
+	}
+
+	return orders[0], nil
+}
+
+// ReissueOrderTrackingToken gives the order a fresh tracking token. The
+// previous token stops working immediately.
+func (s *Service) ReissueOrderTrackingToken(ctx context.Context, orderID string) (dto.OrderTrackingTokenResponse, error) {
+	token, err := models.NewTrackingToken()
+	if err != nil {
+		return dto.OrderTrackingTokenResponse{}, err
+	}
+
+	if err := s.storage.SetOrderTrackingToken(ctx, orderID, token); err != nil {
+		return dto.OrderTrackingTokenResponse{}, err
+	}
+
+	return dto.OrderTrackingTokenResponse{TrackingToken: token}, nil
+}
+
+// RevokeOrderTrackingToken disables tracking for the order until a new token
+// is issued.
+func (s *Service) RevokeOrderTrackingToken(ctx context.Context, orderID string) error {
+	return s.storage.SetOrderTrackingToken(ctx, orderID, "")
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	paymentReceivedComment        = "Оплату отримано"
+	paymentInvoiceDescPrefix      = "Замовлення"
+	latePaymentRefundedMessage    = "⚠️ Надійшла оплата за скасоване замовлення № %s. Кошти повернуто клієнту автоматично."
+	latePaymentNotRefundedMessage = "⚠️ Надійшла оплата за скасоване замовлення № %s. Автоматично повернути кошти не вдалося (%v), поверніть їх вручну."
+)
+
+var (
+	ErrOrderAlreadyPaid     = "Order is already paid"
+	ErrOrderNotPaid         = "Order has no completed payment to refund"
+	ErrOrderPaymentClosed   = "Cancelled or returned orders cannot be paid"
+	ErrPaymentNotRefundable = "Bank transfers are refunded manually"
+	ErrRefundInProgress     = "The rest of the payment is already being refunded"
+)
+
+// createPaymentInvoice opens a checkout page with the payment provider for the
+// order's full amount and records it as an unpaid payment.
+func (s *Service) createPaymentInvoice(ctx context.Context, order models.Order) (models.Payment, error) {
+	invoice, err := s.paymentProvider.CreateInvoice(ctx, dto.PaymentInvoiceRequest{
+		OrderID:     order.ID,
+		Amount:      order.AmountToPay,
+		Description: fmt.Sprintf("%s %s", paymentInvoiceDescPrefix, order.ID),
+	})
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	payment, err := models.NewPayment(
+		order.ID,
+		s.paymentProvider.Name(),
+		invoice.InvoiceID,
+		invoice.CheckoutURL,
+		order.AmountToPay,
+	)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if err := s.storage.CreatePayment(ctx, payment); err != nil {
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+// pendingPaymentURL returns the checkout page of the order's latest unpaid
+// invoice, or an empty string when there is none.
+func (s *Service) pendingPaymentURL(ctx context.Context, orderID string) (string, error) {
+	payments, err := s.storage.ListPayments(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range payments {
+		if p.Status == models.PaymentStatusUnpaid {
+			return p.CheckoutURL, nil
+		}
+	}
+
+	return "", nil
+}
+
+// cancelOpenPayments voids the order's unpaid invoices with the payment
+// provider, so the customer cannot pay an amount that no longer applies. It
+// reports whether any invoice was voided.
+func (s *Service) cancelOpenPayments(ctx context.Context, orderID string) (bool, error) {
+	payments, err := s.storage.ListPayments(ctx, orderID)
+	if err != nil {
+		return false, err
+	}
+
+	cancelled := false
+	for _, p := range payments {
+		if p.Provider != s.paymentProvider.Name() || !p.Status.CanTransitionTo(models.PaymentStatusCancelled) {
+			continue
+		}
+
+		if err := s.paymentProvider.CancelInvoice(ctx, p.InvoiceID); err != nil {
+			return cancelled, err
+		}
+
+		err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+			payment, err := s.storage.LockPaymentByInvoiceID(ctx, p.Provider, p.InvoiceID)
+			if err != nil {
+				return err
+			}
+			// A webhook may have recorded a payment in the meantime.
+			if !payment.Status.CanTransitionTo(models.PaymentStatusCancelled) {
+				return nil
+			}
+
+			return s.storage.UpdatePayment(ctx, payment.ID, models.PaymentStatusCancelled, payment.RefundedAmount)
+		})
+		if err != nil {
+			return cancelled, err
+		}
+
+		cancelled = true
+	}
+
+	return cancelled, nil
+}
+
+// CreateOrderPayment issues a new checkout page for an order, e.g. when the
+// customer lost the link or the first invoice could not be created.
+func (s *Service) CreateOrderPayment(ctx context.Context, orderID string) (dto.OrderPaymentResponse, error) {
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{orderID}})
+	if err != nil {
+		return dto.OrderPaymentResponse{}, err
+	}
+	order := orders[0]
+
+	if order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusRefunded {
+		return dto.OrderPaymentResponse{}, errx.NewConflict().WithDescription(ErrOrderAlreadyPaid)
+	}
+	if order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusReturned {
+		return dto.OrderPaymentResponse{}, errx.NewBadRequest().WithDescription(ErrOrderPaymentClosed)
+	}
+
+	payment, err := s.createPaymentInvoice(ctx, order)
+	if err != nil {
+		return dto.OrderPaymentResponse{}, err
+	}
+
+	return dto.OrderPaymentResponse{Payment: payment}, nil
+}
+
+// HandlePaymentWebhook applies a payment provider notification to the payment
+// and its order. Notifications for unknown invoices and stale or repeated
+// notifications are acknowledged and ignored, so the provider stops retrying.
+func (s *Service) HandlePaymentWebhook(ctx context.Context, input dto.PaymentWebhookRequest) error {
+	event, err := s.paymentProvider.VerifyWebhook(ctx, input.Body, input.Signature)
+	if err != nil {
+		return err
+	}
+	if event.Status == "" {
+		return nil
+	}
+
+	var paidOrderID string
+
+	err = s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		payment, err := s.storage.LockPaymentByInvoiceID(ctx, s.paymentProvider.Name(), event.InvoiceID)
+		if err != nil {
+			if errx.IsCode(err, errx.NotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !payment.Status.CanTransitionTo(event.Status) {
+			return nil
+		}
+
+		if event.Status == models.PaymentStatusRefunded {
+			// A full refund covers the refunds still waiting for an answer.
+			remaining := payment.Amount.Sub(payment.RefundedAmount)
+			if err := s.storage.AddOrderRefundedAmount(ctx, payment.OrderID, remaining); err != nil {
+				return err
+			}
+
+			if err := s.storage.SettlePendingPaymentRefunds(
+				ctx,
+				payment.ID,
+				models.PaymentRefundStatusSucceeded,
+			); err != nil {
+				return err
+			}
+
+			_, err := s.recordPaymentRefund(ctx, payment, remaining, models.ActorTypeSystem, s.paymentProvider.Name())
+			return err
+		}
+
+		if err := s.storage.UpdatePayment(ctx, payment.ID, event.Status, payment.RefundedAmount); err != nil {
+			return err
+		}
+
+		if event.Status == models.PaymentStatusPaid {
+			paidOrderID = payment.OrderID
+		}
+
+		return s.applyOrderPaymentStatus(
+			ctx,
+			payment.OrderID,
+			event.Status,
+			models.ActorTypeSystem,
+			s.paymentProvider.Name(),
+			paymentReceivedComment,
+		)
+	})
+	if err != nil || paidOrderID == "" {
+		return err
+	}
+
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{paidOrderID}})
+	if err != nil {
+		return err
+	}
+	if orders[0].Status == models.OrderStatusCancelled {
+		// The payment is recorded either way; a failed refund is left to
+		// the admins the alert went to rather than retried by the provider.
+		if err := s.refundLatePayment(ctx, paidOrderID); err != nil {
+			s.logger.Printf("failed to refund late payment for order %s: %v", paidOrderID, err)
+		}
+	}
+
+	return nil
+}
+
+// applyOrderPaymentStatus mirrors a payment status change on the order. A
+// paid pending order is confirmed on behalf of the actor. It must run inside
+// a transaction.
+func (s *Service) applyOrderPaymentStatus(
+	ctx context.Context,
+	orderID string,
+	status models.PaymentStatus,
+	actorType models.ActorType,
+	actorID, comment string,
+) error {
+	current, err := s.storage.LockOrderStatus(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	orders, _, err := s.storage.ListOrders(ctx, dto.ListOrderFilter{IDs: []string{orderID}})
+	if err != nil {
+		return err
+	}
+	if !orders[0].PaymentStatus.CanTransitionTo(status) {
+		return nil
+	}
+
+	if err := s.storage.SetOrderPaymentStatus(ctx, orderID, status); err != nil {
+		return err
+	}
+
+	if status == models.PaymentStatusPaid && current == models.OrderStatusPending {
+		return s.changeOrderStatus(
+			ctx,
+			orderID,
+			models.OrderStatusConfirmed,
+			actorType,
+			actorID,
+			comment,
+		)
+	}
+
+	return nil
+}
+
+// RefundOrderPayment returns what is left of the order's payment to the
+// customer through the payment provider. The refund is recorded as pending
+// under the order lock before the provider is asked, so a concurrent refund
+// or return cannot send the same money twice.
+func (s *Service) RefundOrderPayment(ctx context.Context, input dto.RefundOrderPaymentRequest) (dto.OrderPaymentResponse, error) {
+	payment, err := s.refundOrderPayment(ctx, input.OrderID, models.ActorTypeAdmin, input.AdminID)
+	if err != nil {
+		return dto.OrderPaymentResponse{}, err
+	}
+
+	return dto.OrderPaymentResponse{Payment: payment}, nil
+}
+
+func (s *Service) refundOrderPayment(
+	ctx context.Context,
+	orderID string,
+	actorType models.ActorType,
+	actorID string,
+) (models.Payment, error) {
+	var (
+		refund    models.PaymentRefund
+		invoiceID string
+	)
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if _, err := s.storage.LockOrderStatus(ctx, orderID); err != nil {
+			return err
+		}
+
+		paid, err := s.paidPayment(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if paid == nil {
+			return errx.NewBadRequest().WithDescription(ErrOrderNotPaid)
+		}
+		if paid.Provider != s.paymentProvider.Name() {
+			return errx.NewBadRequest().WithDescription(ErrPaymentNotRefundable)
+		}
+
+		payment, err := s.storage.LockPaymentByInvoiceID(ctx, paid.Provider, paid.InvoiceID)
+		if err != nil {
+			return err
+		}
+
+		refundable, err := s.refundableAmount(ctx, payment)
+		if err != nil {
+			return err
+		}
+		if !refundable.IsPositive() {
+			return errx.NewConflict().WithDescription(ErrRefundInProgress)
+		}
+
+		refund, err = models.NewPaymentRefund(payment, "", refundable, actorType, actorID)
+		if err != nil {
+			return err
+		}
+		invoiceID = payment.InvoiceID
+
+		return s.storage.CreatePaymentRefund(ctx, refund)
+	})
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	_, payment, err := s.sendPaymentRefund(ctx, refund, invoiceID)
+
+	return payment, err
+}
+
+// refundLatePayment sends back money that arrived after its order was
+// cancelled, e.g. on a checkout page that could not be voided, and tells the
+// admins either way.
+func (s *Service) refundLatePayment(ctx context.Context, orderID string) error {
+	_, refundErr := s.refundOrderPayment(ctx, orderID, models.ActorTypeSystem, s.paymentProvider.Name())
+
+	message := fmt.Sprintf(latePaymentRefundedMessage, orderID)
+	if refundErr != nil {
+		message = fmt.Sprintf(latePaymentNotRefundedMessage, orderID, refundErr)
+	}
+
+	if err := s.messagingProvider.BroadcastMessage(ctx, message); err != nil {
+		return errors.Join(refundErr, err)
+	}
+
+	return refundErr
+}
+
+// refundableAmount is what is left of a locked payment once completed and
+// pending refunds are taken out. It must run under the order lock.
+func (s *Service) refundableAmount(ctx context.Context, payment models.Payment) (decimal.Decimal, error) {
+	pending, err := s.storage.PendingRefundAmount(ctx, payment.OrderID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return payment.Amount.Sub(payment.RefundedAmount).Sub(pending), nil
+}
+
+// sendPaymentRefund asks the provider for a refund recorded as pending and
+// settles it with the answer. A refund the provider rejected is kept as
+// failed and its error is returned.
+func (s *Service) sendPaymentRefund(
+	ctx context.Context,
+	refund models.PaymentRefund,
+	invoiceID string,
+) (models.PaymentRefund, models.Payment, error) {
+	refundErr := s.paymentProvider.Refund(ctx, invoiceID, refund.Amount)
+
+	var payment models.Payment
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if _, err := s.storage.LockOrderStatus(ctx, refund.OrderID); err != nil {
+			return err
+		}
+
+		var err error
+		payment, err = s.storage.LockPaymentByInvoiceID(ctx, s.paymentProvider.Name(), invoiceID)
+		if err != nil {
+			return err
+		}
+
+		if refundErr != nil {
+			refund.Status = models.PaymentRefundStatusFailed
+			_, err := s.storage.SettlePaymentRefund(ctx, refund.ID, refund.Status)
+			return err
+		}
+
+		refund.Status = models.PaymentRefundStatusSucceeded
+		settled, err := s.storage.SettlePaymentRefund(ctx, refund.ID, refund.Status)
+		if err != nil {
+			return err
+		}
+		if !settled {
+			// The provider's webhook got here first.
+			return nil
+		}
+
+		if err := s.storage.AddOrderRefundedAmount(ctx, refund.OrderID, refund.Amount); err != nil {
+			return err
+		}
+
+		payment, err = s.recordPaymentRefund(ctx, payment, refund.Amount, refund.ActorType, refund.ActorID)
+		return err
+	})
+	if err != nil {
+		return models.PaymentRefund{}, models.Payment{}, err
+	}
+	if refundErr != nil {
+		return refund, payment, refundErr
+	}
+
+	return refund, payment, nil
+}
+
+// paidPayment returns the order's completed payment, or nil when it has none.
+func (s *Service) paidPayment(ctx context.Context, orderID string) (*models.Payment, error) {
+	payments, err := s.storage.ListPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range payments {
+		if payments[i].Status == models.PaymentStatusPaid {
+			return &payments[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// recordPaymentRefund adds amount to what was refunded of a locked payment.
+// Once all of it is refunded the payment and the order become refunded. It
+// must run inside a transaction.
+func (s *Service) recordPaymentRefund(
+	ctx context.Context,
+	payment models.Payment,
+	amount decimal.Decimal,
+	actorType models.ActorType,
+	actorID string,
+) (models.Payment, error) {
+	payment.RefundedAmount = decimal.Min(payment.RefundedAmount.Add(amount), payment.Amount)
+	if payment.RefundedAmount.Equal(payment.Amount) {
+		payment.Status = models.PaymentStatusRefunded
+	}
+
+	if err := s.storage.UpdatePayment(ctx, payment.ID, payment.Status, payment.RefundedAmount); err != nil {
+		return models.Payment{}, err
+	}
+
+	if payment.Status != models.PaymentStatusRefunded {
+		return payment, nil
+	}
+
+	if err := s.applyOrderPaymentStatus(
+		ctx,
+		payment.OrderID,
+		models.PaymentStatusRefunded,
+		actorType,
+		actorID,
+		"",
+	); err != nil {
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 )
@@ -41,14 +42,30 @@ func (s *Service) CreateProduct(ctx context.Context, input dto.CreateProductRequ
 		input.Composition,
 		input.Characteristics,
 		decimal.NewFromFloat(input.Price),
-		input.StockAmount,
+		decimal.NewFromFloat(input.PricePerMl),
+		decimal.NewFromFloat(input.BottleFee),
 		input.IsBestSeller,
 	)
 	if err != nil {
 		return err
 	}
 
-	return s.storage.CreateProduct(ctx, product)
+	variant, err := models.NewProductVariant(product.ID, "", 0, decimal.Zero, input.StockAmount, true)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if err := s.storage.CreateProduct(ctx, product); err != nil {
+			return err
+		}
+
+		if err := s.storage.CreateProductVariant(ctx, variant); err != nil {
+			return err
+		}
+
+		return s.recordMovement(ctx, variant, int64(variant.StockAmount), models.MovementReasonRestock, "", input.AdminID)
+	})
 }
 
 func (s *Service) ListProducts(
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrVariantNotFound       = "Product variant not found"
+	ErrVariantNotAvailable   = "Product variant is not available"
+	ErrDeleteDefaultVariant  = "default variant cannot be deleted"
+	ErrDuplicateLooseVariant = "product already has a decant variant poured to order"
+)
+
+func (s *Service) CreateProductVariant(ctx context.Context, input dto.CreateProductVariantRequest) error {
+	products, _, err := s.storage.ListProducts(ctx, dto.ListProductFilter{
+		IDs:           []string{input.ProductID},
+		ShowInvisible: true,
+		Limit:         1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, ok := products[0].LooseVariant(); ok && input.Volume == 0 {
+		return errx.NewBadRequest().WithDescription(ErrDuplicateLooseVariant)
+	}
+
+	// Products get their default variant when they are created; only one
+	// left without one takes the new variant as its default.
+	_, hasDefault := products[0].DefaultVariant()
+	isDefault := !hasDefault
+
+	variant, err := models.NewProductVariant(
+		input.ProductID,
+		input.SKU,
+		input.Volume,
+		decimal.NewFromFloat(input.Price),
+		input.StockAmount,
+		isDefault,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		if err := s.storage.CreateProductVariant(ctx, variant); err != nil {
+			return err
+		}
+
+		return s.recordMovement(ctx, variant, int64(variant.StockAmount), models.MovementReasonRestock, "", input.AdminID)
+	})
+}
+
+// UpdateProductVariant applies the admin's changes. Setting the stock records
+// the difference as a manual adjustment.
+func (s *Service) UpdateProductVariant(ctx context.Context, input dto.UpdateProductVariantRequest) error {
+	if input.StockAmount == nil {
+		return s.storage.UpdateProductVariant(ctx, input)
+	}
+
+	return s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		variant, err := s.storage.LockProductVariant(ctx, input.ProductID, input.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.storage.UpdateProductVariant(ctx, input); err != nil {
+			return err
+		}
+
+		delta := int64(*input.StockAmount) - int64(variant.StockAmount)
+
+		return s.recordMovement(ctx, variant, delta, models.MovementReasonAdjustment, "", input.AdminID)
+	})
+}
+
+func (s *Service) DeleteProductVariant(ctx context.Context, productID, id string) error {
+	variants, err := s.storage.ListProductVariants(ctx, dto.ListProductVariantFilter{
+		IDs:           []string{id},
+		ProductIDs:    []string{productID},
+		ShowInvisible: true,
+	})
+	if err != nil {
+		return err
+	}
+	if len(variants) == 0 {
+		return errx.NewNotFound().WithDescription(ErrVariantNotFound)
+	}
+	if variants[0].IsDefault {
+		return errx.NewBadRequest().WithDescription(ErrDeleteDefaultVariant)
+	}
+
+	return s.storage.DeleteProductVariant(ctx, productID, id)
+}
@@ -3,22 +3,152 @@ package service
 import (
 	"aroma-hub/internal/application/dto"
 	"aroma-hub/internal/models"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nordew/go-errx"
+	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxPromocodeBatchSize      = 1000
+	defaultPromocodeCodeLength = 8
+	minPromocodeCodeLength     = 4
+	maxPromocodeCodeAttempts   = 10
+	promocodeExportPageSize    = 100
+)
+
+var (
+	ErrInvalidBatchSize     = fmt.Sprintf("count must be between 1 and %d", maxPromocodeBatchSize)
+	ErrCampaignRequired     = "campaign is required"
+	ErrInvalidCodeLength    = fmt.Sprintf("code length must be at least %d and, with the prefix, at most %d characters", minPromocodeCodeLength, models.PromocodeMaxLength)
+	ErrCodeSpaceExhausted   = "failed to generate enough unique codes, try a longer code or a larger alphabet"
+	ErrNoPromocodesToExport = "no promocodes found for the campaign"
 )
 
 func (s *Service) CreatePromocode(ctx context.Context, input dto.CreatePromocodeRequest) error {
 	promocode, err := models.NewPromocode(
 		input.Code,
+		input.DiscountType,
 		input.Discount,
+		models.PromocodeScope{
+			CategoryIDs: input.CategoryIDs,
+			Brands:      input.Brands,
+			ProductIDs:  input.ProductIDs,
+		},
+		input.StartsAt,
 		input.ExpiresAt,
+		input.MaxRedemptions,
+		input.PerCustomerLimit,
+		decimal.NewFromInt(int64(input.MinOrderAmount)),
 	)
 	if err != nil {
 		return err
 	}
+	promocode.Campaign = strings.TrimSpace(input.Campaign)
 
 	return s.storage.CreatePromocode(ctx, promocode)
 }
 
+func (s *Service) GeneratePromocodeBatch(
+	ctx context.Context,
+	input dto.GeneratePromocodeBatchRequest,
+) (dto.PromocodeBatchResponse, error) {
+	campaign := strings.TrimSpace(input.Campaign)
+	if campaign == "" {
+		return dto.PromocodeBatchResponse{}, errx.NewValidation().WithDescription(ErrCampaignRequired)
+	}
+	if input.Count == 0 || input.Count > maxPromocodeBatchSize {
+		return dto.PromocodeBatchResponse{}, errx.NewValidation().WithDescription(ErrInvalidBatchSize)
+	}
+
+	length := int(input.Length)
+	if length == 0 {
+		length = defaultPromocodeCodeLength
+	}
+	if length < minPromocodeCodeLength || len([]rune(input.Prefix))+length > models.PromocodeMaxLength {
+		return dto.PromocodeBatchResponse{}, errx.NewValidation().WithDescription(ErrInvalidCodeLength)
+	}
+
+	scope := models.PromocodeScope{
+		CategoryIDs: input.CategoryIDs,
+		Brands:      input.Brands,
+		ProductIDs:  input.ProductIDs,
+	}
+	minOrderAmount := decimal.NewFromInt(int64(input.MinOrderAmount))
+
+	codes := make([]string, 0, input.Count)
+
+	err := s.transactor.ExecuteInTx(ctx, []pgxtransactor.Storage{s.storage}, func() error {
+		for uint(len(codes)) < input.Count {
+			code, err := s.createUniquePromocode(ctx, input, campaign, length, scope, minOrderAmount)
+			if err != nil {
+				return err
+			}
+
+			codes = append(codes, code)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.PromocodeBatchResponse{}, err
+	}
+
+	return dto.PromocodeBatchResponse{
+		Campaign: campaign,
+		Codes:    codes,
+	}, nil
+}
+
+func (s *Service) createUniquePromocode(
+	ctx context.Context,
+	input dto.GeneratePromocodeBatchRequest,
+	campaign string,
+	length int,
+	scope models.PromocodeScope,
+	minOrderAmount decimal.Decimal,
+) (string, error) {
+	for attempt := 0; attempt < maxPromocodeCodeAttempts; attempt++ {
+		code, err := s.codeGenerator.GenerateCode(input.Prefix, input.Alphabet, length)
+		if err != nil {
+			return "", errx.NewValidation().WithDescriptionAndCause("failed to generate promocode", err)
+		}
+
+		promocode, err := models.NewPromocode(
+			code,
+			input.DiscountType,
+			input.Discount,
+			scope,
+			input.StartsAt,
+			input.ExpiresAt,
+			1,
+			1,
+			minOrderAmount,
+		)
+		if err != nil {
+			return "", err
+		}
+		promocode.Campaign = campaign
+
+		created, err := s.storage.TryCreatePromocode(ctx, promocode)
+		if err != nil {
+			return "", err
+		}
+		if created {
+			return code, nil
+		}
+	}
+
+	return "", errx.NewInternal().WithDescription(ErrCodeSpaceExhausted)
+}
+
 func (s *Service) ListPromocodes(ctx context.Context, filter dto.ListPromocodeFilter) (dto.ListPromocodesResponse, error) {
 	promocodes, total, err := s.storage.ListPromocodes(ctx, filter)
 	if err != nil {
@@ -31,6 +161,63 @@ func (s *Service) ListPromocodes(ctx context.Context, filter dto.ListPromocodeFi
 	}, nil
 }
 
+func (s *Service) ExportPromocodesCSV(ctx context.Context, campaign string) ([]byte, error) {
+	campaign = strings.TrimSpace(campaign)
+	if campaign == "" {
+		return nil, errx.NewValidation().WithDescription(ErrCampaignRequired)
+	}
+
+	var promocodes []models.Promocode
+	for page := uint(1); ; page++ {
+		batch, total, err := s.storage.ListPromocodes(ctx, dto.ListPromocodeFilter{
+			Campaign: campaign,
+			Limit:    promocodeExportPageSize,
+			Page:     page,
+		})
+		if err != nil {
+			if errx.IsCode(err, errx.NotFound) {
+				return nil, errx.NewNotFound().WithDescription(ErrNoPromocodesToExport)
+			}
+			return nil, err
+		}
+
+		promocodes = append(promocodes, batch...)
+		if int64(len(promocodes)) >= total || len(batch) == 0 {
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"code", "campaign", "discount_type", "discount", "starts_at", "expires_at", "used"}); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to write csv", err)
+	}
+
+	for _, p := range promocodes {
+		record := []string{
+			p.Code,
+			p.Campaign,
+			string(p.DiscountType),
+			strconv.FormatUint(uint64(p.Discount), 10),
+			p.StartsAt.Format(time.RFC3339),
+			p.ExpiresAt.Format(time.RFC3339),
+			strconv.FormatBool(p.UsedCount > 0),
+		}
+
+		if err := w.Write(record); err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to write csv", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to write csv", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (s *Service) DeletePromocode(ctx context.Context, id string) error {
 	return s.storage.DeletePromocode(ctx, id)
 }
@@ -50,3 +237,69 @@ func (s *Service) DeleteExpiredPromocodes(ctx context.Context) (int64, error) {
 
 	return total, nil
 }
+
+// PreviewPromocode prices the cart exactly like CreateOrder does and reports
+// whether the code would be accepted. Promocode rejections are returned as a
+// reason in the response rather than as an error.
+func (s *Service) PreviewPromocode(
+	ctx context.Context,
+	input dto.PreviewPromocodeRequest,
+) (dto.PromocodePreviewResponse, error) {
+	if len(input.ProductItems) == 0 {
+		return dto.PromocodePreviewResponse{}, errx.NewBadRequest().WithDescription("cart must contain at least one item")
+	}
+
+	productInfo, err := s.prepareProductInfo(ctx, input.ProductItems)
+	if err != nil {
+		return dto.PromocodePreviewResponse{}, err
+	}
+
+	baseData, err := s.calculateOrderData(input.ProductItems, "", productInfo.productByID, nil)
+	if err != nil {
+		return dto.PromocodePreviewResponse{}, err
+	}
+
+	resp := dto.PromocodePreviewResponse{
+		Code:        input.Code,
+		Subtotal:    uint(baseData.Subtotal.IntPart()),
+		AmountToPay: uint(baseData.TotalAmount.IntPart()),
+	}
+
+	orderData, err := s.applyPromocode(ctx, input, productInfo.productByID)
+	if err != nil {
+		if errx.IsCode(err, errx.Internal) {
+			return dto.PromocodePreviewResponse{}, err
+		}
+
+		resp.Reason = errx.GetMessage(err)
+		return resp, nil
+	}
+
+	resp.Valid = true
+	resp.DiscountAmount = uint(orderData.DiscountAmount.IntPart())
+	resp.AmountToPay = uint(orderData.TotalAmount.IntPart())
+
+	return resp, nil
+}
+
+func (s *Service) applyPromocode(
+	ctx context.Context,
+	input dto.PreviewPromocodeRequest,
+	productByID map[string]models.Product,
+) (OrderData, error) {
+	promocode, err := s.validatePromoCode(ctx, input.Code)
+	if err != nil {
+		return OrderData{}, err
+	}
+
+	orderData, err := s.calculateOrderData(input.ProductItems, "", productByID, &promocode)
+	if err != nil {
+		return OrderData{}, err
+	}
+
+	if err := s.checkPromocodeLimits(ctx, promocode, input.PhoneNumber); err != nil {
+		return OrderData{}, err
+	}
+
+	return orderData, nil
+}
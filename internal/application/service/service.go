@@ -5,11 +5,13 @@ import (
 	"aroma-hub/internal/models"
 	"aroma-hub/pkg/auth"
 	"context"
+	"log"
+	"time"
 
 	"github.com/minio/minio-go/v7"
-	stash "github.com/nordew/go-stash"
 
 	pgxtransactor "github.com/nordew/pgx-transactor"
+	"github.com/shopspring/decimal"
 )
 
 type Storage interface {
@@ -21,6 +23,18 @@ type Storage interface {
 	UpdateProduct(ctx context.Context, input dto.UpdateProductRequest) error
 	DeleteProduct(ctx context.Context, id string) error
 
+	CreateProductVariant(ctx context.Context, variant models.ProductVariant) error
+	ListProductVariants(ctx context.Context, filter dto.ListProductVariantFilter) ([]models.ProductVariant, error)
+	UpdateProductVariant(ctx context.Context, input dto.UpdateProductVariantRequest) error
+	ReserveProductVariantStock(ctx context.Context, id string, amount uint) (bool, error)
+	RestockProductVariant(ctx context.Context, id string, amount uint) error
+	LockProductVariant(ctx context.Context, productID, id string) (models.ProductVariant, error)
+
+	CreateInventoryMovement(ctx context.Context, movement models.InventoryMovement) error
+	ListInventoryMovements(ctx context.Context, filter dto.ListInventoryMovementFilter) ([]models.InventoryMovement, int64, error)
+	ListInventoryDrifts(ctx context.Context) ([]models.InventoryDrift, error)
+	DeleteProductVariant(ctx context.Context, productID, id string) error
+
 	CreateCategory(ctx context.Context, category models.Category) error
 	ListCategories(ctx context.Context, filter dto.ListCategoryFilter) ([]models.Category, int64, error)
 	DeleteCategory(ctx context.Context, id string) error
@@ -29,47 +43,156 @@ type Storage interface {
 	ListOrders(ctx context.Context, filter dto.ListOrderFilter) ([]models.Order, int64, error)
 	UpdateOrder(ctx context.Context, input dto.UpdateOrderRequest) error
 	DeleteOrder(ctx context.Context, id string) error
+	LockOrderStatus(ctx context.Context, id string) (models.OrderStatus, error)
+	SetOrderTrackingToken(ctx context.Context, id, token string) error
+	SetOrderWaybill(ctx context.Context, id, carrier, number string) (bool, error)
+	SetOrderPaymentStatus(ctx context.Context, id string, status models.PaymentStatus) error
+	AddOrderRefundedAmount(ctx context.Context, id string, amount decimal.Decimal) error
+	SetOrderAmounts(ctx context.Context, id string, subtotal, discount, amountToPay decimal.Decimal) error
+
+	CreateOrderReturn(ctx context.Context, ret models.OrderReturn) error
+	ListOrderReturns(ctx context.Context, orderID string) ([]models.OrderReturn, error)
+
+	CreatePayment(ctx context.Context, payment models.Payment) error
+	ListPayments(ctx context.Context, orderID string) ([]models.Payment, error)
+	LockPaymentByInvoiceID(ctx context.Context, provider, invoiceID string) (models.Payment, error)
+	UpdatePayment(ctx context.Context, id string, status models.PaymentStatus, refundedAmount decimal.Decimal) error
+
+	CreatePaymentRefund(ctx context.Context, refund models.PaymentRefund) error
+	PendingRefundAmount(ctx context.Context, orderID string) (decimal.Decimal, error)
+	SettlePaymentRefund(ctx context.Context, id string, status models.PaymentRefundStatus) (bool, error)
+	SettlePendingPaymentRefunds(ctx context.Context, paymentID string, status models.PaymentRefundStatus) error
+
+	CreateIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, key string) (models.IdempotencyKey, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+
+	CreateOrderStatusHistory(ctx context.Context, entry models.OrderStatusHistory) error
+	ListOrderStatusHistory(ctx context.Context, orderID string) ([]models.OrderStatusHistory, error)
 
 	CreateOrderProduct(ctx context.Context, orderProduct models.OrderProduct) error
 	ListOrderProducts(ctx context.Context, filter dto.ListOrderProductFilter) ([]models.OrderProduct, int64, error)
+	ListOrderLines(ctx context.Context, orderIDs []string) ([]models.OrderProduct, error)
+	ReturnOrderProduct(ctx context.Context, orderID, variantID string, volume, quantity uint) (bool, error)
+	DeleteOrderProducts(ctx context.Context, orderID string) error
 
 	CreatePromocode(ctx context.Context, promocode models.Promocode) error
+	TryCreatePromocode(ctx context.Context, promocode models.Promocode) (bool, error)
 	ListPromocodes(ctx context.Context, filter dto.ListPromocodeFilter) ([]models.Promocode, int64, error)
+	GetPromocodeByCode(ctx context.Context, code string) (models.Promocode, error)
 	DeletePromocode(ctx context.Context, id string) error
+	LockPromocode(ctx context.Context, id string) error
+
+	CreatePromocodeRedemption(ctx context.Context, redemption models.PromocodeRedemption) error
+	CountPromocodeRedemptions(ctx context.Context, filter dto.CountPromocodeRedemptionFilter) (int64, error)
+	ReleasePromocodeRedemptions(ctx context.Context, orderID string) error
+	UpdatePromocodeRedemptionDiscount(ctx context.Context, orderID string, discount decimal.Decimal) error
 
 	ListAdmins(ctx context.Context, filter dto.ListAdminFilter) ([]models.Admin, error)
+	CreateAdmin(ctx context.Context, admin models.Admin) error
+	UpdateAdminRole(ctx context.Context, id string, role models.AdminRole) error
+	LockAdminsByRole(ctx context.Context, role models.AdminRole) (int64, error)
+	SetAdminDisabled(ctx context.Context, id string, disabled bool) error
+	DeleteAdmin(ctx context.Context, id string) error
+
+	CreateAdminInvite(ctx context.Context, invite models.AdminInvite) error
+	LockAdminInviteByTokenHash(ctx context.Context, tokenHash string) (models.AdminInvite, error)
+	MarkAdminInviteUsed(ctx context.Context, id, adminID string) error
+
+	CreateAdminSession(ctx context.Context, session models.AdminSession) error
+	LockAdminSession(ctx context.Context, id string) (models.AdminSession, error)
+	RotateAdminSession(ctx context.Context, session models.AdminSession) error
+	RevokeAdminSession(ctx context.Context, id string) error
+	RevokeAdminSessions(ctx context.Context, adminID string) (int64, error)
+}
+
+// LoginCodeVerifier checks the one-time codes admins log in with. A code
+// works once.
+type LoginCodeVerifier interface {
+	Verify(account, ip, code string) error
 }
 
 type MessagingProvider interface {
 	BroadcastMessage(ctx context.Context, text string) error
+	// DeepLink returns a link that opens the bot and hands it payload.
+	DeepLink(payload string) string
+}
+
+// Carrier is a delivery service the shop ships parcels with.
+type Carrier interface {
+	Name() string
+	SearchCities(ctx context.Context, query string) ([]models.DeliveryCity, error)
+	ListWarehouses(ctx context.Context, cityRef, query string) ([]models.DeliveryWarehouse, error)
+	CreateWaybill(ctx context.Context, input dto.CarrierWaybillRequest) (models.Waybill, error)
+	DeleteWaybill(ctx context.Context, waybill models.Waybill) error
+	TrackShipments(ctx context.Context, parcels []dto.CarrierTrackingRequest) ([]models.ShipmentTracking, error)
+}
+
+// PaymentProvider is an acquiring service customers pay orders through.
+// VerifyWebhook must reject notifications whose signature does not match.
+type PaymentProvider interface {
+	Name() string
+	CreateInvoice(ctx context.Context, input dto.PaymentInvoiceRequest) (dto.PaymentInvoice, error)
+	VerifyWebhook(ctx context.Context, body []byte, signature string) (dto.PaymentEvent, error)
+	Refund(ctx context.Context, invoiceID string, amount decimal.Decimal) error
+	// CancelInvoice voids an unpaid invoice so its checkout page stops
+	// taking payments.
+	CancelInvoice(ctx context.Context, invoiceID string) error
+}
+
+// InvoiceRenderer lays out a bank transfer invoice as a printable document.
+type InvoiceRenderer interface {
+	RenderInvoice(invoice dto.OrderInvoice) ([]byte, error)
+}
+
+type CodeGenerator interface {
+	GenerateCode(prefix, alphabet string, length int) (string, error)
 }
 
 type Service struct {
 	storage           Storage
 	transactor        *pgxtransactor.Transactor
-	cache             stash.Cache
+	loginCodes        LoginCodeVerifier
 	tokenService      *auth.TokenService
 	messagingProvider MessagingProvider
+	carrier           Carrier
+	paymentProvider   PaymentProvider
+	invoiceRenderer   InvoiceRenderer
+	merchant          dto.MerchantRequisites
+	codeGenerator     CodeGenerator
 	minioClient       *minio.Client
 	minioBucket       string
+	logger            *log.Logger
 }
 
 func NewService(
 	storage Storage,
 	transactor *pgxtransactor.Transactor,
-	cache stash.Cache,
+	loginCodes LoginCodeVerifier,
 	tokenService *auth.TokenService,
 	messagingProvider MessagingProvider,
+	carrier Carrier,
+	paymentProvider PaymentProvider,
+	invoiceRenderer InvoiceRenderer,
+	merchant dto.MerchantRequisites,
+	codeGenerator CodeGenerator,
 	minioClient *minio.Client,
 	minioBucket string,
+	logger *log.Logger,
 ) *Service {
 	return &Service{
 		storage:           storage,
 		transactor:        transactor,
-		cache:             cache,
+		loginCodes:        loginCodes,
 		tokenService:      tokenService,
 		messagingProvider: messagingProvider,
+		carrier:           carrier,
+		paymentProvider:   paymentProvider,
+		invoiceRenderer:   invoiceRenderer,
+		merchant:          merchant,
+		codeGenerator:     codeGenerator,
 		minioClient:       minioClient,
 		minioBucket:       minioBucket,
+		logger:            logger,
 	}
 }
@@ -8,6 +8,13 @@ type Config struct {
 	Telegram Telegram `env-prefix:"TELEGRAM_"`
 	Auth     Auth     `env-prefix:"AUTH_"`
 	Minio    Minio    `env-prefix:"MINIO_"`
+
+	NovaPoshta NovaPoshta `env-prefix:"NOVA_POSHTA_"`
+	Monobank   Monobank   `env-prefix:"MONOBANK_"`
+	Merchant   Merchant   `env-prefix:"MERCHANT_"`
+
+	OrderExpiry OrderExpiry `env-prefix:"ORDER_EXPIRY_"`
+	AdminLogin  AdminLogin  `env-prefix:"ADMIN_LOGIN_"`
 }
 
 type Server struct {
@@ -19,10 +26,21 @@ type Server struct {
 	AllowedHeaders []string `env:"ALLOWED_HEADERS"`
 }
 
+// Auth holds the token signing keys; access and refresh tokens must use
+// different ones. With HS256 a key is a secret of at least 32 bytes, with EdDSA
+// a base64-encoded Ed25519 seed. To rotate a key, sign with a new ID and move
+// the old one to the Previous list as "id:key" so its tokens verify until they
+// expire.
 type Auth struct {
-	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL"`
-	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL"`
-	AuthSecret      string        `env:"AUTH_SECRET"`
+	AccessTokenTTL      time.Duration     `env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL     time.Duration     `env:"REFRESH_TOKEN_TTL"`
+	Algorithm           string            `env:"ALGORITHM" env-default:"HS256"`
+	AccessKeyID         string            `env:"ACCESS_KEY_ID" env-default:"access-1"`
+	AccessKey           string            `env:"ACCESS_KEY"`
+	PreviousAccessKeys  map[string]string `env:"PREVIOUS_ACCESS_KEYS"`
+	RefreshKeyID        string            `env:"REFRESH_KEY_ID" env-default:"refresh-1"`
+	RefreshKey          string            `env:"REFRESH_KEY"`
+	PreviousRefreshKeys map[string]string `env:"PREVIOUS_REFRESH_KEYS"`
 }
 
 type Postgres struct {
@@ -43,3 +61,61 @@ type Minio struct {
 	UseSSL       bool   `env:"USE_SSL"`
 	BucketName   string `env:"BUCKET_NAME"`
 }
+
+// NovaPoshta holds the API key and the sender's references. APIKey is
+// required unless UseFake is set for local development, in which case
+// waybills are kept in memory and never reach the carrier.
+type NovaPoshta struct {
+	APIKey             string `env:"API_KEY"`
+	BaseURL            string `env:"BASE_URL"`
+	SenderRef          string `env:"SENDER_REF"`
+	SenderContactRef   string `env:"SENDER_CONTACT_REF"`
+	SenderCityRef      string `env:"SENDER_CITY_REF"`
+	SenderWarehouseRef string `env:"SENDER_WAREHOUSE_REF"`
+	SenderPhone        string `env:"SENDER_PHONE"`
+	UseFake            bool   `env:"USE_FAKE"`
+}
+
+// Monobank configures card payments through Monobank acquiring. Token is
+// required unless UseFake is set for local development, in which case an
+// in-memory provider signs webhooks with FakeSecret. Anyone who knows
+// FakeSecret can mark orders paid, so it has no default.
+type Monobank struct {
+	Token           string        `env:"TOKEN"`
+	BaseURL         string        `env:"BASE_URL"`
+	WebhookURL      string        `env:"WEBHOOK_URL"`
+	RedirectURL     string        `env:"REDIRECT_URL"`
+	InvoiceValidity time.Duration `env:"INVOICE_VALIDITY" env-default:"24h"`
+	UseFake         bool          `env:"USE_FAKE"`
+	FakeSecret      string        `env:"FAKE_SECRET"`
+}
+
+// Merchant holds the shop's FOP requisites customers pay IBAN orders to.
+// InvoiceFont is a TrueType font with Cyrillic glyphs (e.g. DejaVuSans.ttf)
+// used for PDF invoices; they are unavailable while it is empty.
+type Merchant struct {
+	Name        string `env:"NAME"`
+	TaxID       string `env:"TAX_ID"`
+	IBAN        string `env:"IBAN"`
+	Bank        string `env:"BANK"`
+	InvoiceFont string `env:"INVOICE_FONT"`
+}
+
+// OrderExpiry is how long a pending order may stay unpaid before it is
+// cancelled and its stock released, per payment method. Zero disables expiry.
+type OrderExpiry struct {
+	IBAN           time.Duration `env:"IBAN" env-default:"72h"`
+	Card           time.Duration `env:"CARD" env-default:"24h"`
+	CashOnDelivery time.Duration `env:"CASH_ON_DELIVERY" env-default:"0"`
+}
+
+// AdminLogin limits one-time login codes. An admin who enters MaxFailures
+// wrong codes is locked out for LockoutDuration, and one address may try
+// MaxIPAttempts codes per IPWindow.
+type AdminLogin struct {
+	CodeTTL         time.Duration `env:"CODE_TTL" env-default:"5m"`
+	MaxFailures     int           `env:"MAX_FAILURES" env-default:"5"`
+	LockoutDuration time.Duration `env:"LOCKOUT_DURATION" env-default:"15m"`
+	MaxIPAttempts   int           `env:"MAX_IP_ATTEMPTS" env-default:"20"`
+	IPWindow        time.Duration `env:"IP_WINDOW" env-default:"15m"`
+}
@@ -4,20 +4,34 @@ import (
 	"aroma-hub/internal/application/dto"
 	"context"
 
-	_ "aroma-hub/internal/models"
+	"aroma-hub/internal/models"
 	"github.com/gofiber/fiber/v2"
+	"github.com/nordew/go-errx"
 )
 
 func (h *Handler) initAdminRoutes(api fiber.Router) {
 	admin := api.Group("/admin")
 
 	admin.Post("/login", h.adminLogin)
-	admin.Get("/refresh", h.adminRefresh)
-	admin.Get("/products", h.adminListProducts)
+	admin.Post("/refresh", h.adminRefresh)
+	admin.Post("/logout", h.middleware.Auth(), h.adminLogout)
+	admin.Post("/logout-all", h.middleware.Auth(), h.adminLogoutAll)
+	admin.Get("/products",
+		h.middleware.Auth(),
+		h.middleware.RequirePermission(models.PermissionProducts, models.PermissionStock),
+		h.adminListProducts)
+
+	admins := admin.Group("/admins", h.middleware.Auth(), h.middleware.RequirePermission(models.PermissionAdmins))
+	admins.Get("/", h.listAdmins)
+	admins.Post("/invites", h.createAdminInvite)
+	admins.Put("/:id/role", h.assignAdminRole)
+	admins.Put("/:id/disable", h.disableAdmin)
+	admins.Put("/:id/enable", h.enableAdmin)
+	admins.Delete("/:id", h.deleteAdmin)
 }
 
 // @Summary Admin login
-// @Description Admin login with OTP code
+// @Description Log in with the Telegram ID and one-time code the bot sends on /login. A code works once; repeated wrong codes lock the admin out for a while.
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -25,6 +39,7 @@ func (h *Handler) initAdminRoutes(api fiber.Router) {
 // @Success 200 {object} dto.AdminLoginResponse "Admin login response"
 // @Failure 400 {object} errx.Error "Bad request"
 // @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Locked out after too many failed attempts"
 // @Failure 500 {object} errx.Error "Internal server error"
 // @Router /admin/login [post]
 func (h *Handler) adminLogin(c *fiber.Ctx) error {
@@ -35,6 +50,8 @@ func (h *Handler) adminLogin(c *fiber.Ctx) error {
 		return handleError(c, err, op)
 	}
 
+	input.IP = c.IP()
+
 	resp, err := h.service.AdminLogin(context.Background(), input)
 	if err != nil {
 		return handleError(c, err, op)
@@ -44,7 +61,7 @@ func (h *Handler) adminLogin(c *fiber.Ctx) error {
 }
 
 // @Summary Admin refresh token
-// @Description Refresh admin access token
+// @Description Exchange a refresh token for a new access and refresh token pair. Every refresh token works once; using one again revokes its session.
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -53,13 +70,13 @@ func (h *Handler) adminLogin(c *fiber.Ctx) error {
 // @Failure 400 {object} errx.Error "Bad request"
 // @Failure 401 {object} errx.Error "Unauthorized"
 // @Failure 500 {object} errx.Error "Internal server error"
-// @Router /admin/refresh [get]
+// @Router /admin/refresh [post]
 func (h *Handler) adminRefresh(c *fiber.Ctx) error {
 	const op = "adminRefresh"
 
 	var input dto.AdminRefreshTokenRequest
-	if err := c.QueryParser(&input); err != nil {
-		return handleError(c, err, op)
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
 	}
 
 	resp, err := h.service.AdminRefresh(context.Background(), input)
@@ -70,6 +87,86 @@ func (h *Handler) adminRefresh(c *fiber.Ctx) error {
 	return writeResponse(c, fiber.StatusOK, resp)
 }
 
+// @Summary Admin logout
+// @Description End the session the request is made with. Its refresh token stops working.
+// @Tags admin
+// @Produce json
+// @Success 204 "Logged out"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 404 {object} errx.Error "Session not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/logout [post]
+func (h *Handler) adminLogout(c *fiber.Ctx) error {
+	const op = "adminLogout"
+
+	input := dto.AdminLogoutRequest{
+		AdminID:   adminID(c),
+		SessionID: adminSessionID(c),
+	}
+
+	if err := h.service.AdminLogout(context.Background(), input); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Admin logout from all devices
+// @Description End every session of the admin, so all refresh tokens stop working
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.AdminLogoutAllResponse "Revoked sessions"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/logout-all [post]
+func (h *Handler) adminLogoutAll(c *fiber.Ctx) error {
+	const op = "adminLogoutAll"
+
+	resp, err := h.service.AdminLogoutAll(context.Background(), adminID(c))
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Assign admin role
+// @Description Change what an admin may do: owner, manager, warehouse or marketing. Owner only. The new role applies once the admin's access token is refreshed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Admin ID"
+// @Param input body dto.AssignAdminRoleRequest true "New role"
+// @Success 200 {object} models.Admin "Updated admin"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Forbidden"
+// @Failure 404 {object} errx.Error "Admin not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/admins/{id}/role [put]
+func (h *Handler) assignAdminRole(c *fiber.Ctx) error {
+	const op = "assignAdminRole"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.AssignAdminRoleRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	input.AdminID = id
+
+	resp, err := h.service.AssignAdminRole(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
 // @Summary List products
 // @Description Get a list of products with optional filtering (invisible included)
 // @Tags admin
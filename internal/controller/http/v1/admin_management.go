@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nordew/go-errx"
+)
+
+// @Summary List admins
+// @Description Get every admin with their role, disabled ones included. Owner only.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} []models.Admin "Admins"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Forbidden"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/admins [get]
+func (h *Handler) listAdmins(c *fiber.Ctx) error {
+	const op = "listAdmins"
+
+	resp, err := h.service.ListAdmins(context.Background())
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Invite admin
+// @Description Create a one-time Telegram link, valid for 24 hours, that makes whoever opens it an admin with the given role. Owner only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param input body dto.CreateAdminInviteRequest true "Role of the new admin"
+// @Success 201 {object} dto.AdminInviteResponse "Invite link"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Forbidden"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/admins/invites [post]
+func (h *Handler) createAdminInvite(c *fiber.Ctx) error {
+	const op = "createAdminInvite"
+
+	var input dto.CreateAdminInviteRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	input.CreatedBy = adminID(c)
+
+	resp, err := h.service.CreateAdminInvite(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, resp)
+}
+
+// @Summary Disable admin
+// @Description Stop an admin from logging in and end their sessions. The last active owner cannot be disabled. Owner only.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Admin ID"
+// @Success 200 {object} models.Admin "Disabled admin"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Forbidden"
+// @Failure 404 {object} errx.Error "Admin not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/admins/{id}/disable [put]
+func (h *Handler) disableAdmin(c *fiber.Ctx) error {
+	const op = "disableAdmin"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.DisableAdmin(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Enable admin
+// @Description Let a disabled admin log in again. Owner only.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Admin ID"
+// @Success 200 {object} models.Admin "Enabled admin"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Forbidden"
+// @Failure 404 {object} errx.Error "Admin not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/admins/{id}/enable [put]
+func (h *Handler) enableAdmin(c *fiber.Ctx) error {
+	const op = "enableAdmin"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.EnableAdmin(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Remove admin
+// @Description Delete an admin and their sessions. The last active owner cannot be removed. Owner only.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Admin ID"
+// @Success 204 "Admin removed"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 401 {object} errx.Error "Unauthorized"
+// @Failure 403 {object} errx.Error "Forbidden"
+// @Failure 404 {object} errx.Error "Admin not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/admins/{id} [delete]
+func (h *Handler) deleteAdmin(c *fiber.Ctx) error {
+	const op = "deleteAdmin"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	if err := h.service.DeleteAdmin(context.Background(), id); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
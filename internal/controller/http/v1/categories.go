@@ -5,7 +5,7 @@ import (
 	"context"
 	"errors"
 
-	_ "aroma-hub/internal/models"
+	"aroma-hub/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,7 +13,7 @@ import (
 func (h *Handler) initCategoryRoutes(api fiber.Router) {
 	categories := api.Group("/categories")
 
-	categories.Use(h.middleware.Auth())
+	categories.Use(h.middleware.Auth(), h.middleware.RequirePermission(models.PermissionProducts))
 
 	categories.Get("/", h.listCategories)
 	categories.Post("/", h.createCategory)
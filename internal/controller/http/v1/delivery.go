@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	deliveryRateLimit       = 60
+	deliveryRateLimitWindow = time.Minute
+)
+
+func (h *Handler) initDeliveryRoutes(api fiber.Router) {
+	delivery := api.Group("/delivery")
+
+	delivery.Use(h.middleware.RateLimit(deliveryRateLimit, deliveryRateLimitWindow))
+	delivery.Get("/cities", h.searchDeliveryCities)
+	delivery.Get("/warehouses", h.listDeliveryWarehouses)
+}
+
+// @Summary Search delivery cities
+// @Description Find cities the carrier delivers to, for picking a delivery point at checkout
+// @Tags delivery
+// @Produce json
+// @Param query query string true "Part of the city name, at least 2 characters"
+// @Success 200 {object} dto.DeliveryCitiesResponse "Matching cities"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /delivery/cities [get]
+func (h *Handler) searchDeliveryCities(c *fiber.Ctx) error {
+	const op = "searchDeliveryCities"
+
+	var filter dto.SearchDeliveryCitiesFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return handleError(c, err, op)
+	}
+
+	resp, err := h.service.SearchDeliveryCities(context.Background(), filter)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary List delivery warehouses
+// @Description List the carrier's branches in a city, optionally filtered by number or address
+// @Tags delivery
+// @Produce json
+// @Param cityRef query string true "City reference from the city search"
+// @Param query query string false "Branch number or part of its address"
+// @Success 200 {object} dto.DeliveryWarehousesResponse "Branches"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /delivery/warehouses [get]
+func (h *Handler) listDeliveryWarehouses(c *fiber.Ctx) error {
+	const op = "listDeliveryWarehouses"
+
+	var filter dto.ListDeliveryWarehousesFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return handleError(c, err, op)
+	}
+
+	resp, err := h.service.ListDeliveryWarehouses(context.Background(), filter)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
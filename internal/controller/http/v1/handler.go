@@ -8,6 +8,7 @@ import (
 
 	"aroma-hub/internal/application/dto"
 	"aroma-hub/internal/config"
+	"aroma-hub/internal/models"
 	"aroma-hub/pkg/auth"
 
 	"github.com/gofiber/fiber/v2"
@@ -24,22 +25,60 @@ type Service interface {
 	SetProductImage(ctx context.Context, productID string, imageBytes []byte) error
 	DeleteProduct(ctx context.Context, id string) error
 
+	CreateProductVariant(ctx context.Context, input dto.CreateProductVariantRequest) error
+	UpdateProductVariant(ctx context.Context, input dto.UpdateProductVariantRequest) error
+	DeleteProductVariant(ctx context.Context, productID, id string) error
+
 	CreateCategory(ctx context.Context, input dto.CreateCategoryRequest) error
 	ListCategories(ctx context.Context, filter dto.ListCategoryFilter) (dto.ListCategoryResponse, error)
 	DeleteCategory(ctx context.Context, id string) error
 
-	CreateOrder(ctx context.Context, order dto.CreateOrderRequest) error
+	CreateOrder(ctx context.Context, order dto.CreateOrderRequest) (dto.Order, error)
+	LookupOrder(ctx context.Context, input dto.LookupOrderRequest) (dto.Order, error)
 	ListOrders(ctx context.Context, filter dto.ListOrderFilter) (dto.OrderResponse, error)
 	UpdateOrder(ctx context.Context, input dto.UpdateOrderRequest) error
-	CancelOrder(ctx context.Context, id string) error
-	DeleteOrder(ctx context.Context, id string) error
+	CancelOrder(ctx context.Context, input dto.CancelOrderRequest) error
+	DeleteOrder(ctx context.Context, id, adminID string) error
+	ListOrderStatusHistory(ctx context.Context, orderID string) (dto.OrderStatusHistoryResponse, error)
+	TrackOrder(ctx context.Context, token string) (dto.OrderTrackingResponse, error)
+	ReissueOrderTrackingToken(ctx context.Context, orderID string) (dto.OrderTrackingTokenResponse, error)
+	RevokeOrderTrackingToken(ctx context.Context, orderID string) error
+	CreateOrderWaybill(ctx context.Context, input dto.CreateWaybillRequest) (dto.WaybillResponse, error)
+	CreateOrderPayment(ctx context.Context, orderID string) (dto.OrderPaymentResponse, error)
+	RefundOrderPayment(ctx context.Context, input dto.RefundOrderPaymentRequest) (dto.OrderPaymentResponse, error)
+	HandlePaymentWebhook(ctx context.Context, input dto.PaymentWebhookRequest) error
+	GetOrderInvoice(ctx context.Context, orderID string) (dto.OrderInvoice, error)
+	RenderOrderInvoice(ctx context.Context, orderID string) ([]byte, error)
+	RenderTrackedOrderInvoice(ctx context.Context, token string) ([]byte, error)
+	SendOrderInvoice(ctx context.Context, orderID string) error
+	MarkOrderInvoicePaid(ctx context.Context, input dto.MarkOrderPaidRequest) (dto.OrderPaymentResponse, error)
+	UpdateOrderItems(ctx context.Context, input dto.UpdateOrderItemsRequest) (dto.Order, error)
+	CreateOrderReturn(ctx context.Context, input dto.CreateOrderReturnRequest) (dto.OrderReturnResponse, error)
+	ListOrderReturns(ctx context.Context, orderID string) (dto.ListOrderReturnsResponse, error)
+
+	SearchDeliveryCities(ctx context.Context, filter dto.SearchDeliveryCitiesFilter) (dto.DeliveryCitiesResponse, error)
+	ListDeliveryWarehouses(ctx context.Context, filter dto.ListDeliveryWarehousesFilter) (dto.DeliveryWarehousesResponse, error)
 
 	CreatePromocode(ctx context.Context, input dto.CreatePromocodeRequest) error
+	GeneratePromocodeBatch(ctx context.Context, input dto.GeneratePromocodeBatchRequest) (dto.PromocodeBatchResponse, error)
 	ListPromocodes(ctx context.Context, filter dto.ListPromocodeFilter) (dto.ListPromocodesResponse, error)
+	ExportPromocodesCSV(ctx context.Context, campaign string) ([]byte, error)
+	PreviewPromocode(ctx context.Context, input dto.PreviewPromocodeRequest) (dto.PromocodePreviewResponse, error)
 	DeletePromocode(ctx context.Context, id string) error
 
+	ListInventoryMovements(ctx context.Context, filter dto.ListInventoryMovementFilter) (dto.ListInventoryMovementsResponse, error)
+	ReconcileInventory(ctx context.Context) (dto.InventoryReconciliationResponse, error)
+
 	AdminLogin(ctx context.Context, input dto.AdminLoginRequest) (dto.AdminLoginResponse, error)
 	AdminRefresh(ctx context.Context, input dto.AdminRefreshTokenRequest) (dto.AdminRefreshTokenResponse, error)
+	AdminLogout(ctx context.Context, input dto.AdminLogoutRequest) error
+	AdminLogoutAll(ctx context.Context, adminID string) (dto.AdminLogoutAllResponse, error)
+	AssignAdminRole(ctx context.Context, input dto.AssignAdminRoleRequest) (models.Admin, error)
+	ListAdmins(ctx context.Context) ([]models.Admin, error)
+	CreateAdminInvite(ctx context.Context, input dto.CreateAdminInviteRequest) (dto.AdminInviteResponse, error)
+	DisableAdmin(ctx context.Context, adminID string) (models.Admin, error)
+	EnableAdmin(ctx context.Context, adminID string) (models.Admin, error)
+	DeleteAdmin(ctx context.Context, adminID string) error
 }
 
 type Handler struct {
@@ -80,6 +119,9 @@ func (h *Handler) InitAndServe(router *fiber.App, cfg config.Server) error {
 	h.initOrderRoutes(api)
 	h.initPromocodeRoutes(api)
 	h.initAdminRoutes(api)
+	h.initInventoryRoutes(api)
+	h.initDeliveryRoutes(api)
+	h.initPaymentRoutes(api)
 
 	port := fmt.Sprintf(":%d", cfg.Port)
 	h.middleware.logger.Info("starting server",
@@ -116,11 +158,46 @@ func handleError(c *fiber.Ctx, err error, operation string) error {
 		return writeErrorResponse(c, fiber.StatusUnauthorized, err.Error())
 	case errx.IsCode(err, errx.Forbidden):
 		return writeErrorResponse(c, fiber.StatusForbidden, err.Error())
+	case errx.IsCode(err, errx.AlreadyExists), errx.IsCode(err, errx.Conflict):
+		return writeErrorResponse(c, fiber.StatusConflict, err.Error())
 	default:
 		return writeErrorResponse(c, fiber.StatusInternalServerError, "unexpected error: "+operation)
 	}
 }
 
+// adminID returns the ID of the admin behind an authenticated request, or an
+// empty string on public routes.
+func adminID(c *fiber.Ctx) string {
+	claims, ok := c.Locals("userID").(*auth.Claims)
+	if !ok || claims == nil {
+		return ""
+	}
+
+	return claims.UserID
+}
+
+// adminRole returns the role of the admin behind an authenticated request, or
+// an empty role, which grants nothing, on public routes.
+func adminRole(c *fiber.Ctx) models.AdminRole {
+	claims, ok := c.Locals("userID").(*auth.Claims)
+	if !ok || claims == nil {
+		return ""
+	}
+
+	return models.AdminRole(claims.Role)
+}
+
+// adminSessionID returns the login session an authenticated request was made
+// with, or an empty string on public routes.
+func adminSessionID(c *fiber.Ctx) string {
+	claims, ok := c.Locals("userID").(*auth.Claims)
+	if !ok || claims == nil {
+		return ""
+	}
+
+	return claims.SessionID
+}
+
 func writeErrorResponse(c *fiber.Ctx, status int, message string) error {
 	response := fiber.Map{
 		"success": false,
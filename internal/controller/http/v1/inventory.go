@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+
+	"aroma-hub/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) initInventoryRoutes(api fiber.Router) {
+	inventory := api.Group("/admin/inventory")
+
+	inventory.Use(h.middleware.Auth(), h.middleware.RequirePermission(models.PermissionStock))
+	inventory.Get("/movements", h.listInventoryMovements)
+	inventory.Get("/reconciliation", h.reconcileInventory)
+}
+
+// @Summary List inventory movements
+// @Description Get the stock ledger, newest first, optionally for a single product or variant
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param productId query string false "Product ID"
+// @Param variantId query string false "Variant ID"
+// @Param reason query string false "Reason (sale, cancellation, adjustment, restock, return)"
+// @Param referenceId query string false "Reference ID, e.g. an order ID"
+// @Param limit query integer false "Number of items per page (default: 10, max: 100)"
+// @Param page query integer false "Page number (default: 1)"
+// @Success 200 {object} dto.ListInventoryMovementsResponse "List of inventory movements"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/inventory/movements [get]
+func (h *Handler) listInventoryMovements(c *fiber.Ctx) error {
+	const op = "listInventoryMovements"
+
+	var filter dto.ListInventoryMovementFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return handleError(c, err, op)
+	}
+
+	resp, err := h.service.ListInventoryMovements(context.Background(), filter)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Reconcile inventory
+// @Description Recompute stock from the ledger and report variants whose stock has drifted
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.InventoryReconciliationResponse "Reconciliation report"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /admin/inventory/reconciliation [get]
+func (h *Handler) reconcileInventory(c *fiber.Ctx) error {
+	const op = "reconcileInventory"
+
+	resp, err := h.service.ReconcileInventory(context.Background())
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
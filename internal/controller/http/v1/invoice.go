@@ -0,0 +1,154 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nordew/go-errx"
+)
+
+// @Summary Order invoice
+// @Description Get the bank transfer invoice of an IBAN order: merchant requisites, items, totals and the payment purpose
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.OrderInvoice "Invoice"
+// @Failure 400 {object} errx.Error "Order is not paid by IBAN"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/invoice [get]
+func (h *Handler) getOrderInvoice(c *fiber.Ctx) error {
+	const op = "getOrderInvoice"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.GetOrderInvoice(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Download order invoice
+// @Description Download the bank transfer invoice of an IBAN order as PDF
+// @Tags orders
+// @Produce application/pdf
+// @Param id path string true "Order ID"
+// @Success 200 {file} file "PDF invoice"
+// @Failure 400 {object} errx.Error "Order is not paid by IBAN"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/invoice/pdf [get]
+func (h *Handler) downloadOrderInvoice(c *fiber.Ctx) error {
+	const op = "downloadOrderInvoice"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	data, err := h.service.RenderOrderInvoice(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return sendInvoicePDF(c, fmt.Sprintf("invoice-%s.pdf", id), data)
+}
+
+// @Summary Download invoice by tracking token
+// @Description Let a customer download the PDF invoice of an IBAN order using its tracking token
+// @Tags orders
+// @Produce application/pdf
+// @Param token path string true "Tracking token"
+// @Success 200 {file} file "PDF invoice"
+// @Failure 400 {object} errx.Error "Order is not paid by IBAN"
+// @Failure 404 {object} errx.Error "Tracking token is invalid or has been revoked"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/track/{token}/invoice [get]
+func (h *Handler) downloadTrackedOrderInvoice(c *fiber.Ctx) error {
+	const op = "downloadTrackedOrderInvoice"
+
+	data, err := h.service.RenderTrackedOrderInvoice(context.Background(), c.Params("token"))
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return sendInvoicePDF(c, "invoice.pdf", data)
+}
+
+func sendInvoicePDF(c *fiber.Ctx, filename string, data []byte) error {
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// @Summary Send order invoice to Telegram
+// @Description Post the payment instructions of an IBAN order to the admins' Telegram chat to forward to the customer
+// @Tags orders
+// @Param id path string true "Order ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errx.Error "Order is not paid by IBAN"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/invoice/send [post]
+func (h *Handler) sendOrderInvoice(c *fiber.Ctx) error {
+	const op = "sendOrderInvoice"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	if err := h.service.SendOrderInvoice(context.Background(), id); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusNoContent, id)
+}
+
+// @Summary Mark invoice paid
+// @Description Record that the bank transfer for an IBAN order has arrived. A pending order is confirmed.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param input body dto.MarkOrderPaidRequest false "Optional comment"
+// @Success 201 {object} dto.OrderPaymentResponse "Recorded payment"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 409 {object} errx.Error "Order is already paid"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/invoice/paid [post]
+func (h *Handler) markOrderInvoicePaid(c *fiber.Ctx) error {
+	const op = "markOrderInvoicePaid"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.MarkOrderPaidRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+		}
+	}
+
+	input.OrderID = id
+	input.AdminID = adminID(c)
+
+	resp, err := h.service.MarkOrderInvoicePaid(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, resp)
+}
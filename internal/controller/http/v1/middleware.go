@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"aroma-hub/internal/models"
 	"aroma-hub/pkg/auth"
 	"errors"
 	"log/slog"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/google/uuid"
 )
 
@@ -54,6 +56,34 @@ func (m *Middleware) Auth() fiber.Handler {
 	}
 }
 
+// RequirePermission lets the request through when the admin's role grants any
+// of the permissions. It must run after Auth.
+func (m *Middleware) RequirePermission(permissions ...models.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role := adminRole(c)
+		for _, p := range permissions {
+			if role.Can(p) {
+				return c.Next()
+			}
+		}
+
+		return writeErrorResponse(c, fiber.StatusForbidden, "insufficient permissions")
+	}
+}
+
+func (m *Middleware) RateLimit(max int, window time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return writeErrorResponse(c, fiber.StatusTooManyRequests, "too many requests")
+		},
+	})
+}
+
 func (m *Middleware) RequestLogger() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
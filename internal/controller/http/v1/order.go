@@ -3,24 +3,51 @@ package v1
 import (
 	"aroma-hub/internal/application/dto"
 	"context"
+	"strings"
 	"time"
 
-	_ "aroma-hub/internal/models"
+	"aroma-hub/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nordew/go-errx"
 )
 
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	lookupRateLimit       = 10
+	lookupRateLimitWindow = time.Minute
+
+	trackRateLimit       = 30
+	trackRateLimitWindow = time.Minute
+)
+
 func (h *Handler) initOrderRoutes(api fiber.Router) {
 	orders := api.Group("/orders")
 
 	orders.Post("/", h.createOrder)
+	orders.Post("/lookup", h.middleware.RateLimit(lookupRateLimit, lookupRateLimitWindow), h.lookupOrder)
+	orders.Get("/track/:token", h.middleware.RateLimit(trackRateLimit, trackRateLimitWindow), h.trackOrder)
+	orders.Get("/track/:token/invoice", h.middleware.RateLimit(trackRateLimit, trackRateLimitWindow), h.downloadTrackedOrderInvoice)
 
-	orders.Use(h.middleware.Auth())
+	orders.Use(h.middleware.Auth(), h.middleware.RequirePermission(models.PermissionOrders))
 	orders.Get("/", h.listOrders)
 	orders.Put("/:id", h.updateOrder)
+	orders.Put("/:id/items", h.updateOrderItems)
 	orders.Delete("/:id", h.deleteOrder)
 	orders.Put("/:id/cancel", h.cancelOrder)
+	orders.Get("/:id/history", h.listOrderStatusHistory)
+	orders.Post("/:id/tracking-token", h.reissueOrderTrackingToken)
+	orders.Delete("/:id/tracking-token", h.revokeOrderTrackingToken)
+	orders.Post("/:id/waybill", h.createOrderWaybill)
+	orders.Post("/:id/payment", h.createOrderPayment)
+	orders.Post("/:id/refund", h.refundOrderPayment)
+	orders.Get("/:id/invoice", h.getOrderInvoice)
+	orders.Get("/:id/invoice/pdf", h.downloadOrderInvoice)
+	orders.Post("/:id/invoice/send", h.sendOrderInvoice)
+	orders.Post("/:id/invoice/paid", h.markOrderInvoicePaid)
+	orders.Post("/:id/returns", h.createOrderReturn)
+	orders.Get("/:id/returns", h.listOrderReturns)
 }
 
 // @Summary List orders
@@ -30,9 +57,10 @@ func (h *Handler) initOrderRoutes(api fiber.Router) {
 // @Produce json
 // @Param id query string false "Order ID"
 // @Param userId query string false "User ID"
-// @Param paymentMethod query string false "Payment method (IBAN, сash_on_delivery)"
+// @Param paymentMethod query string false "Payment method (IBAN, сash_on_delivery, card)"
+// @Param paymentStatus query string false "Payment status (unpaid, paid, refunded, failed)"
 // @Param contactType query string false "Contact type (telegram, phone)"
-// @Param status query string false "Order status (pending, processing, completed, cancelled)"
+// @Param status query string false "Order status (pending, confirmed, processing, shipped, delivered, completed, cancelled, returned, partially_returned)"
 // @Param fromDate query string false "Start date for filtering (format: YYYY-MM-DD)"
 // @Param toDate query string false "End date for filtering (format: YYYY-MM-DD)"
 // @Param limit query integer false "Number of items per page (default: 10, max: 100)"
@@ -78,13 +106,15 @@ func (h *Handler) listOrders(c *fiber.Ctx) error {
 }
 
 // @Summary Create order
-// @Description Create a new order
+// @Description Create a new order. Retries that send the same Idempotency-Key and body get the original response without placing a second order. Card orders come back with a paymentUrl to send the customer to.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Client-generated key that deduplicates retries for 24 hours"
 // @Param order body dto.CreateOrderRequest true "Order information"
-// @Success 201 "Created"
+// @Success 201 {object} dto.Order "Created order"
 // @Failure 400 {object} errx.Error "Bad request"
+// @Failure 409 {object} errx.Error "Idempotency-Key reused with a different body"
 // @Failure 500 {object} errx.Error "Internal server error"
 // @Router /orders [post]
 func (h *Handler) createOrder(c *fiber.Ctx) error {
@@ -95,15 +125,67 @@ func (h *Handler) createOrder(c *fiber.Ctx) error {
 		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
 	}
 
-	if err := h.service.CreateOrder(context.Background(), input); err != nil {
+	input.IdempotencyKey = strings.TrimSpace(c.Get(idempotencyKeyHeader))
+
+	order, err := h.service.CreateOrder(context.Background(), input)
+	if err != nil {
 		return handleError(c, err, op)
 	}
 
-	return writeResponse(c, fiber.StatusCreated, input)
+	return writeResponse(c, fiber.StatusCreated, order)
+}
+
+// @Summary Look up order
+// @Description Get an order by its ID and the phone number it was placed with
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param input body dto.LookupOrderRequest true "Order ID and phone number"
+// @Success 200 {object} dto.Order "Order"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/lookup [post]
+func (h *Handler) lookupOrder(c *fiber.Ctx) error {
+	const op = "lookupOrder"
+
+	var input dto.LookupOrderRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	order, err := h.service.LookupOrder(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, order)
+}
+
+// @Summary Track order
+// @Description Follow an order by the tracking token handed out when it was placed. Shows the status history, items and shipment tracking number, without the customer's contact details.
+// @Tags orders
+// @Produce json
+// @Param token path string true "Tracking token"
+// @Success 200 {object} dto.OrderTrackingResponse "Order progress"
+// @Failure 404 {object} errx.Error "Tracking token is invalid or has been revoked"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/track/{token} [get]
+func (h *Handler) trackOrder(c *fiber.Ctx) error {
+	const op = "trackOrder"
+
+	resp, err := h.service.TrackOrder(context.Background(), c.Params("token"))
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
 }
 
 // @Summary Update order
-// @Description Update an existing order
+// @Description Update an existing order. A status change must follow the allowed transitions and is recorded in the order history.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -128,6 +210,7 @@ func (h *Handler) updateOrder(c *fiber.Ctx) error {
 	}
 
 	input.ID = id
+	input.AdminID = adminID(c)
 
 	err := h.service.UpdateOrder(context.Background(), input)
 	if err != nil {
@@ -138,11 +221,12 @@ func (h *Handler) updateOrder(c *fiber.Ctx) error {
 }
 
 // @Summary Cancel order
-// @Description Cancel an order by ID
+// @Description Cancel an order that has not been shipped yet, returning its stock and promocode
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
+// @Param input body dto.CancelOrderRequest false "Optional comment"
 // @Success 200 "Order cancelled"
 // @Failure 400 {object} errx.Error "Bad request"
 // @Failure 404 {object} errx.Error "Order not found"
@@ -156,7 +240,17 @@ func (h *Handler) cancelOrder(c *fiber.Ctx) error {
 		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
 	}
 
-	err := h.service.CancelOrder(context.Background(), id)
+	var input dto.CancelOrderRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+		}
+	}
+
+	input.ID = id
+	input.AdminID = adminID(c)
+
+	err := h.service.CancelOrder(context.Background(), input)
 	if err != nil {
 		return handleError(c, err, op)
 	}
@@ -183,10 +277,178 @@ func (h *Handler) deleteOrder(c *fiber.Ctx) error {
 		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
 	}
 
-	err := h.service.DeleteOrder(context.Background(), id)
+	err := h.service.DeleteOrder(context.Background(), id, adminID(c))
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusNoContent, id)
+}
+
+// @Summary Order status history
+// @Description Get every status change of an order with its actor, time and comment
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.OrderStatusHistoryResponse "Status history"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/history [get]
+func (h *Handler) listOrderStatusHistory(c *fiber.Ctx) error {
+	const op = "listOrderStatusHistory"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.ListOrderStatusHistory(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Reissue tracking token
+// @Description Give an order a new tracking token. The previous token stops working.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.OrderTrackingTokenResponse "New tracking token"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/tracking-token [post]
+func (h *Handler) reissueOrderTrackingToken(c *fiber.Ctx) error {
+	const op = "reissueOrderTrackingToken"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.ReissueOrderTrackingToken(context.Background(), id)
 	if err != nil {
 		return handleError(c, err, op)
 	}
 
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Revoke tracking token
+// @Description Disable tracking for an order until a new token is issued
+// @Tags orders
+// @Param id path string true "Order ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/tracking-token [delete]
+func (h *Handler) revokeOrderTrackingToken(c *fiber.Ctx) error {
+	const op = "revokeOrderTrackingToken"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	if err := h.service.RevokeOrderTrackingToken(context.Background(), id); err != nil {
+		return handleError(c, err, op)
+	}
+
 	return writeResponse(c, fiber.StatusNoContent, id)
 }
+
+// @Summary Create waybill
+// @Description Register the order's parcel with the carrier. The waybill number is stored on the order and its tracking moves the order to shipped and delivered.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param input body dto.CreateWaybillRequest true "Parcel details"
+// @Success 201 {object} dto.WaybillResponse "Created waybill"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 409 {object} errx.Error "Order already has a waybill"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/waybill [post]
+func (h *Handler) createOrderWaybill(c *fiber.Ctx) error {
+	const op = "createOrderWaybill"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.CreateWaybillRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	input.OrderID = id
+	input.AdminID = adminID(c)
+
+	resp, err := h.service.CreateOrderWaybill(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, resp)
+}
+
+// @Summary Issue payment link
+// @Description Create a new checkout page for an unpaid order
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 201 {object} dto.OrderPaymentResponse "New payment"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 409 {object} errx.Error "Order is already paid"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/payment [post]
+func (h *Handler) createOrderPayment(c *fiber.Ctx) error {
+	const op = "createOrderPayment"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.CreateOrderPayment(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, resp)
+}
+
+// @Summary Refund order payment
+// @Description Return the paid amount of an order to the customer's card
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.OrderPaymentResponse "Refunded payment"
+// @Failure 400 {object} errx.Error "Order has no completed payment"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/refund [post]
+func (h *Handler) refundOrderPayment(c *fiber.Ctx) error {
+	const op = "refundOrderPayment"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.RefundOrderPayment(context.Background(), dto.RefundOrderPaymentRequest{
+		OrderID: id,
+		AdminID: adminID(c),
+	})
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nordew/go-errx"
+)
+
+// @Summary Update order items
+// @Description Replace the items of an unpaid order that has not shipped yet. Items already in the order keep their price, stock is adjusted by the difference and the promocode discount and amount to pay are recalculated. An open card checkout page is voided and the response carries a new one as paymentUrl.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param input body dto.UpdateOrderItemsRequest true "New order items"
+// @Success 200 {object} dto.Order "Updated order"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order or product not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/items [put]
+func (h *Handler) updateOrderItems(c *fiber.Ctx) error {
+	const op = "updateOrderItems"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.UpdateOrderItemsRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	input.OrderID = id
+	input.AdminID = adminID(c)
+
+	resp, err := h.service.UpdateOrderItems(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
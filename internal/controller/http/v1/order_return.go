@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nordew/go-errx"
+)
+
+// @Summary Create order return
+// @Description Record items a customer sent back from a shipped order. Items can be restocked, and the refund is sent through the payment provider when the order was paid online. The order becomes partially_returned or returned.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param input body dto.CreateOrderReturnRequest true "Returned items and refund"
+// @Success 201 {object} dto.OrderReturnResponse "Recorded return"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Order not found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/returns [post]
+func (h *Handler) createOrderReturn(c *fiber.Ctx) error {
+	const op = "createOrderReturn"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.CreateOrderReturnRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	input.OrderID = id
+	input.AdminID = adminID(c)
+
+	resp, err := h.service.CreateOrderReturn(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, resp)
+}
+
+// @Summary List order returns
+// @Description Get every return recorded for an order with its items and refund
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.ListOrderReturnsResponse "Returns"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /orders/{id}/returns [get]
+func (h *Handler) listOrderReturns(c *fiber.Ctx) error {
+	const op = "listOrderReturns"
+
+	id := c.Params("id")
+	if id == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	resp, err := h.service.ListOrderReturns(context.Background(), id)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"aroma-hub/internal/application/dto"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// paymentSignatureHeader carries the provider's signature of the webhook body.
+	paymentSignatureHeader = "X-Sign"
+
+	webhookRateLimit       = 120
+	webhookRateLimitWindow = time.Minute
+)
+
+func (h *Handler) initPaymentRoutes(api fiber.Router) {
+	payments := api.Group("/payments")
+
+	payments.Post("/webhook", h.middleware.RateLimit(webhookRateLimit, webhookRateLimitWindow), h.handlePaymentWebhook)
+}
+
+// @Summary Payment webhook
+// @Description Receives payment status notifications from the payment provider. The body must be signed in the X-Sign header.
+// @Tags payments
+// @Accept json
+// @Param X-Sign header string true "Provider signature of the body"
+// @Success 200 "Accepted"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 401 {object} errx.Error "Invalid signature"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /payments/webhook [post]
+func (h *Handler) handlePaymentWebhook(c *fiber.Ctx) error {
+	const op = "handlePaymentWebhook"
+
+	input := dto.PaymentWebhookRequest{
+		Body:      append([]byte(nil), c.Body()...),
+		Signature: c.Get(paymentSignatureHeader),
+	}
+
+	if err := h.service.HandlePaymentWebhook(context.Background(), input); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/nordew/go-errx"
 
-	_ "aroma-hub/internal/models"
+	"aroma-hub/internal/models"
 )
 
 func (h *Handler) initProductRoutes(api fiber.Router) {
@@ -20,10 +20,19 @@ func (h *Handler) initProductRoutes(api fiber.Router) {
 	products.Get("/best-sellers", h.listBestSellers)
 
 	products.Use(h.middleware.Auth())
-	products.Post("/", h.createProduct)
-	products.Delete("/:id", h.deleteProduct)
-	products.Patch("/:id", h.updateProduct)
-	products.Patch("/:id/set-image", h.setImage)
+
+	manageCatalogue := h.middleware.RequirePermission(models.PermissionProducts)
+	products.Post("/", manageCatalogue, h.createProduct)
+	products.Delete("/:id", manageCatalogue, h.deleteProduct)
+	products.Patch("/:id", manageCatalogue, h.updateProduct)
+	products.Patch("/:id/set-image", manageCatalogue, h.setImage)
+	products.Post("/:id/variants", manageCatalogue, h.createProductVariant)
+	products.Delete("/:id/variants/:variantId", manageCatalogue, h.deleteProductVariant)
+	// Warehouse staff may change variant stock; updateProductVariant keeps
+	// them away from the other fields.
+	products.Patch("/:id/variants/:variantId",
+		h.middleware.RequirePermission(models.PermissionProducts, models.PermissionStock),
+		h.updateProductVariant)
 }
 
 // @Summary List products
@@ -106,6 +115,8 @@ func (h *Handler) createProduct(c *fiber.Ctx) error {
 		return handleError(c, err, op)
 	}
 
+	input.AdminID = adminID(c)
+
 	err = h.service.CreateProduct(context.Background(), input)
 	if err != nil {
 		return handleError(c, err, op)
@@ -242,3 +253,111 @@ func (h *Handler) setImage(c *fiber.Ctx) error {
 
 	return writeResponse(c, fiber.StatusNoContent, nil)
 }
+
+// @Summary Create product variant
+// @Description Add a sellable variant (a bottle size or a pre-filled decant) to a product. Volume 0 creates the decant poured to order.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variant body dto.CreateProductVariantRequest true "Variant information"
+// @Success 201 {object} string "Created successfully"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Not found"
+// @Failure 409 {object} errx.Error "SKU already exists"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /products/{id}/variants [post]
+func (h *Handler) createProductVariant(c *fiber.Ctx) error {
+	const op = "createProductVariant"
+
+	productID := c.Params("id")
+	if productID == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.CreateProductVariantRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, err, op)
+	}
+
+	input.ProductID = productID
+	input.AdminID = adminID(c)
+
+	if err := h.service.CreateProductVariant(context.Background(), input); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, "")
+}
+
+// @Summary Update product variant
+// @Description Update the SKU, price, stock or visibility of a product variant
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param variant body dto.UpdateProductVariantRequest true "Variant information"
+// @Success 204 "No Content"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Not found"
+// @Failure 409 {object} errx.Error "SKU already exists"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /products/{id}/variants/{variantId} [patch]
+func (h *Handler) updateProductVariant(c *fiber.Ctx) error {
+	const op = "updateProductVariant"
+
+	productID := c.Params("id")
+	variantID := c.Params("variantId")
+	if productID == "" || variantID == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	var input dto.UpdateProductVariantRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, err, op)
+	}
+
+	if !adminRole(c).Can(models.PermissionProducts) && !input.OnlyChangesStock() {
+		return handleError(c, errx.NewForbidden().WithDescription("only the stock amount can be changed"), op)
+	}
+
+	input.ID = variantID
+	input.ProductID = productID
+	input.AdminID = adminID(c)
+
+	if err := h.service.UpdateProductVariant(context.Background(), input); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusNoContent, "")
+}
+
+// @Summary Delete product variant
+// @Description Remove a variant that has never been ordered. The product's default variant cannot be deleted.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Not found"
+// @Failure 409 {object} errx.Error "Variant has orders"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /products/{id}/variants/{variantId} [delete]
+func (h *Handler) deleteProductVariant(c *fiber.Ctx) error {
+	const op = "deleteProductVariant"
+
+	productID := c.Params("id")
+	variantID := c.Params("variantId")
+	if productID == "" || variantID == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("id is empty"), op)
+	}
+
+	if err := h.service.DeleteProductVariant(context.Background(), productID, variantID); err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusNoContent, variantID)
+}
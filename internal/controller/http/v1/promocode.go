@@ -2,24 +2,36 @@ package v1
 
 import (
 	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nordew/go-errx"
 )
 
+const (
+	previewRateLimit       = 20
+	previewRateLimitWindow = time.Minute
+)
+
 func (h *Handler) initPromocodeRoutes(api fiber.Router) {
 	promocodes := api.Group("/promocodes")
 
-	promocodes.Use(h.middleware.Auth())
+	promocodes.Post("/preview", h.middleware.RateLimit(previewRateLimit, previewRateLimitWindow), h.previewPromocode)
+
+	promocodes.Use(h.middleware.Auth(), h.middleware.RequirePermission(models.PermissionPromocodes))
 
 	promocodes.Post("/", h.createPromocode)
+	promocodes.Post("/batch", h.generatePromocodeBatch)
 	promocodes.Get("/", h.listPromocodes)
+	promocodes.Get("/export", h.exportPromocodes)
 	promocodes.Delete("/:id", h.deletePromocode)
 }
 
 // @Summary Create promocode
-// @Description Create a new promocode with discount
+// @Description Create a new percent or fixed-amount promocode, optionally limited to categories, brands or products
 // @Tags promocodes
 // @Accept json
 // @Produce json
@@ -44,15 +56,71 @@ func (h *Handler) createPromocode(c *fiber.Ctx) error {
 	return writeResponse(c, fiber.StatusCreated, nil)
 }
 
+// @Summary Preview promocode
+// @Description Check a promocode against a cart and get the discounted total without placing an order
+// @Tags promocodes
+// @Accept json
+// @Produce json
+// @Param preview body dto.PreviewPromocodeRequest true "Promocode and cart"
+// @Success 200 {object} dto.PromocodePreviewResponse "Preview result"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "Product not found"
+// @Failure 429 {object} errx.Error "Too many requests"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /promocodes/preview [post]
+func (h *Handler) previewPromocode(c *fiber.Ctx) error {
+	const op = "previewPromocode"
+
+	var input dto.PreviewPromocodeRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	resp, err := h.service.PreviewPromocode(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusOK, resp)
+}
+
+// @Summary Generate promocode batch
+// @Description Generate a batch of unique single-use promocodes that share a campaign, discount and expiry
+// @Tags promocodes
+// @Accept json
+// @Produce json
+// @Param batch body dto.GeneratePromocodeBatchRequest true "Batch parameters"
+// @Success 201 {object} dto.PromocodeBatchResponse "Generated codes"
+// @Failure 400 {object} errx.Error "Validation error"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /promocodes/batch [post]
+func (h *Handler) generatePromocodeBatch(c *fiber.Ctx) error {
+	const op = "generatePromocodeBatch"
+
+	var input dto.GeneratePromocodeBatchRequest
+	if err := c.BodyParser(&input); err != nil {
+		return handleError(c, errx.NewBadRequest().WithDescriptionAndCause("invalid request body", err), op)
+	}
+
+	resp, err := h.service.GeneratePromocodeBatch(context.Background(), input)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	return writeResponse(c, fiber.StatusCreated, resp)
+}
+
 // @Summary List promocodes
-// @Description Get a list of promocodes with optional filtering
+// @Description Get a list of promocodes with optional filtering, including used and remaining redemption counts
 // @Tags promocodes
 // @Accept json
 // @Produce json
 // @Param id query string false "Promocode ID"
 // @Param code query string false "Promocode code"
-// @Param discountFrom query integer false "Minimum discount percentage"
-// @Param discountTo query integer false "Maximum discount percentage"
+// @Param campaign query string false "Campaign name"
+// @Param discountType query string false "Discount type (percent, fixed)"
+// @Param discountFrom query integer false "Minimum discount value"
+// @Param discountTo query integer false "Maximum discount value"
 // @Param active query boolean false "Filter for active promocodes (not expired)"
 // @Param limit query integer false "Number of items per page (default: 10, max: 100)"
 // @Param page query integer false "Page number (default: 1)"
@@ -76,8 +144,37 @@ func (h *Handler) listPromocodes(c *fiber.Ctx) error {
 	return writeResponse(c, fiber.StatusOK, resp)
 }
 
+// @Summary Export promocodes
+// @Description Download the promocodes of a campaign as CSV
+// @Tags promocodes
+// @Produce text/csv
+// @Param campaign query string true "Campaign name"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} errx.Error "Bad request"
+// @Failure 404 {object} errx.Error "No promocodes found"
+// @Failure 500 {object} errx.Error "Internal server error"
+// @Router /promocodes/export [get]
+func (h *Handler) exportPromocodes(c *fiber.Ctx) error {
+	const op = "exportPromocodes"
+
+	campaign := c.Query("campaign")
+	if campaign == "" {
+		return handleError(c, errx.NewBadRequest().WithDescription("campaign is empty"), op)
+	}
+
+	data, err := h.service.ExportPromocodesCSV(context.Background(), campaign)
+	if err != nil {
+		return handleError(c, err, op)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", campaign+".csv"))
+
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
 // @Summary Delete promocode
-// @Description Delete a promocode by its ID
+// @Description Delete a promocode by its ID. It can no longer be used, but orders placed with it keep their discount
 // @Tags promocodes
 // @Accept json
 // @Produce json
@@ -0,0 +1,138 @@
+package fake
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+)
+
+const carrierName = "fake"
+
+// Carrier is an in-memory carrier for tests and local development. Waybills
+// start as created; SetState moves a parcel along.
+type Carrier struct {
+	mu         sync.Mutex
+	cities     []models.DeliveryCity
+	warehouses []models.DeliveryWarehouse
+	waybills   map[string]dto.CarrierWaybillRequest
+	states     map[string]models.ShipmentState
+	nextNumber int
+}
+
+func NewCarrier(cities []models.DeliveryCity, warehouses []models.DeliveryWarehouse) *Carrier {
+	return &Carrier{
+		cities:     cities,
+		warehouses: warehouses,
+		waybills:   make(map[string]dto.CarrierWaybillRequest),
+		states:     make(map[string]models.ShipmentState),
+		nextNumber: 20450000000001,
+	}
+}
+
+func (c *Carrier) Name() string {
+	return carrierName
+}
+
+func (c *Carrier) SearchCities(_ context.Context, query string) ([]models.DeliveryCity, error) {
+	query = strings.ToLower(query)
+
+	cities := make([]models.DeliveryCity, 0)
+	for _, city := range c.cities {
+		if strings.Contains(strings.ToLower(city.Name), query) {
+			cities = append(cities, city)
+		}
+	}
+
+	return cities, nil
+}
+
+func (c *Carrier) ListWarehouses(_ context.Context, cityRef, query string) ([]models.DeliveryWarehouse, error) {
+	query = strings.ToLower(query)
+
+	warehouses := make([]models.DeliveryWarehouse, 0)
+	for _, w := range c.warehouses {
+		if w.CityRef == cityRef && strings.Contains(strings.ToLower(w.Name), query) {
+			warehouses = append(warehouses, w)
+		}
+	}
+
+	return warehouses, nil
+}
+
+func (c *Carrier) CreateWaybill(_ context.Context, input dto.CarrierWaybillRequest) (models.Waybill, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if input.CityRef == "" || input.WarehouseRef == "" {
+		return models.Waybill{}, errx.NewBadRequest().WithDescription("recipient city and warehouse are required")
+	}
+
+	number := fmt.Sprintf("%d", c.nextNumber)
+	c.nextNumber++
+
+	c.waybills[number] = input
+	c.states[number] = models.ShipmentStateCreated
+
+	return models.Waybill{
+		Ref:    uuid.NewString(),
+		Number: number,
+	}, nil
+}
+
+func (c *Carrier) DeleteWaybill(_ context.Context, waybill models.Waybill) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.waybills[waybill.Number]; !ok {
+		return errx.NewNotFound().WithDescription("waybill not found")
+	}
+
+	delete(c.waybills, waybill.Number)
+	delete(c.states, waybill.Number)
+
+	return nil
+}
+
+func (c *Carrier) TrackShipments(_ context.Context, parcels []dto.CarrierTrackingRequest) ([]models.ShipmentTracking, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	trackings := make([]models.ShipmentTracking, 0, len(parcels))
+	for _, p := range parcels {
+		state, ok := c.states[p.Number]
+		if !ok {
+			continue
+		}
+
+		trackings = append(trackings, models.ShipmentTracking{
+			Number:     p.Number,
+			State:      state,
+			StatusText: string(state),
+		})
+	}
+
+	return trackings, nil
+}
+
+// SetState moves a parcel to the given state.
+func (c *Carrier) SetState(number string, state models.ShipmentState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.states[number] = state
+}
+
+// Waybill returns what the waybill with the given number was created with.
+func (c *Carrier) Waybill(number string) (dto.CarrierWaybillRequest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	input, ok := c.waybills[number]
+	return input, ok
+}
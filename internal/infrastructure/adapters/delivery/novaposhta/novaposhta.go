@@ -0,0 +1,365 @@
+package novaposhta
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/config"
+	"aroma-hub/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	carrierName = "nova_poshta"
+
+	defaultBaseURL = "https://api.novaposhta.ua/v2.0/json/"
+	requestTimeout = 15 * time.Second
+	dateLayout     = "02.01.2006"
+	searchLimit    = 20
+)
+
+// Client talks to the Nova Poshta JSON API. Every call is a POST of
+// {apiKey, modelName, calledMethod, methodProperties} to a single URL.
+type Client struct {
+	httpClient *http.Client
+	cfg        config.NovaPoshta
+}
+
+func NewClient(cfg config.NovaPoshta) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		cfg:        cfg,
+	}
+}
+
+func (c *Client) Name() string {
+	return carrierName
+}
+
+type request struct {
+	APIKey           string `json:"apiKey"`
+	ModelName        string `json:"modelName"`
+	CalledMethod     string `json:"calledMethod"`
+	MethodProperties any    `json:"methodProperties"`
+}
+
+type response struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Errors  []string        `json:"errors"`
+}
+
+func (c *Client) call(ctx context.Context, model, method string, props, out any) error {
+	body, err := json.Marshal(request{
+		APIKey:           c.cfg.APIKey,
+		ModelName:        model,
+		CalledMethod:     method,
+		MethodProperties: props,
+	})
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to encode nova poshta request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL, bytes.NewReader(body))
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to build nova poshta request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("nova poshta is unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errx.NewInternal().WithDescription(fmt.Sprintf("nova poshta responded with status %d", resp.StatusCode))
+	}
+
+	var decoded response
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to decode nova poshta response", err)
+	}
+
+	if !decoded.Success {
+		return errx.NewBadRequest().WithDescription(
+			fmt.Sprintf("nova poshta %s.%s failed: %s", model, method, strings.Join(decoded.Errors, "; ")))
+	}
+
+	if err := json.Unmarshal(decoded.Data, out); err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to decode nova poshta data", err)
+	}
+
+	return nil
+}
+
+type city struct {
+	Ref             string `json:"Ref"`
+	Description     string `json:"Description"`
+	AreaDescription string `json:"AreaDescription"`
+}
+
+func (c *Client) SearchCities(ctx context.Context, query string) ([]models.DeliveryCity, error) {
+	var data []city
+
+	err := c.call(ctx, "Address", "getCities", map[string]any{
+		"FindByString": query,
+		"Limit":        searchLimit,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	cities := make([]models.DeliveryCity, 0, len(data))
+	for _, d := range data {
+		cities = append(cities, models.DeliveryCity{
+			Ref:    d.Ref,
+			Name:   d.Description,
+			Region: d.AreaDescription,
+		})
+	}
+
+	return cities, nil
+}
+
+type warehouse struct {
+	Ref         string `json:"Ref"`
+	CityRef     string `json:"CityRef"`
+	Number      string `json:"Number"`
+	Description string `json:"Description"`
+}
+
+func (c *Client) ListWarehouses(ctx context.Context, cityRef, query string) ([]models.DeliveryWarehouse, error) {
+	var data []warehouse
+
+	props := map[string]any{"CityRef": cityRef}
+	if query != "" {
+		props["FindByString"] = query
+	}
+
+	if err := c.call(ctx, "Address", "getWarehouses", props, &data); err != nil {
+		return nil, err
+	}
+
+	warehouses := make([]models.DeliveryWarehouse, 0, len(data))
+	for _, d := range data {
+		warehouses = append(warehouses, models.DeliveryWarehouse{
+			Ref:     d.Ref,
+			CityRef: d.CityRef,
+			Number:  d.Number,
+			Name:    d.Description,
+		})
+	}
+
+	return warehouses, nil
+}
+
+type counterparty struct {
+	Ref           string `json:"Ref"`
+	ContactPerson struct {
+		Data []struct {
+			Ref string `json:"Ref"`
+		} `json:"data"`
+	} `json:"ContactPerson"`
+}
+
+type document struct {
+	Ref                   string      `json:"Ref"`
+	IntDocNumber          string      `json:"IntDocNumber"`
+	CostOnSite            json.Number `json:"CostOnSite"`
+	EstimatedDeliveryDate string      `json:"EstimatedDeliveryDate"`
+}
+
+// CreateWaybill registers the recipient as a private person and creates a
+// warehouse-to-warehouse express waybill paid by the recipient.
+func (c *Client) CreateWaybill(ctx context.Context, input dto.CarrierWaybillRequest) (models.Waybill, error) {
+	recipient, err := c.createRecipient(ctx, input.RecipientName, input.RecipientPhone)
+	if err != nil {
+		return models.Waybill{}, err
+	}
+
+	props := map[string]any{
+		"PayerType":        "Recipient",
+		"PaymentMethod":    "Cash",
+		"DateTime":         time.Now().Format(dateLayout),
+		"CargoType":        "Parcel",
+		"ServiceType":      "WarehouseWarehouse",
+		"Weight":           input.Weight,
+		"SeatsAmount":      input.SeatsAmount,
+		"Description":      input.Description,
+		"Cost":             input.DeclaredValue.StringFixed(0),
+		"CitySender":       c.cfg.SenderCityRef,
+		"Sender":           c.cfg.SenderRef,
+		"SenderAddress":    c.cfg.SenderWarehouseRef,
+		"ContactSender":    c.cfg.SenderContactRef,
+		"SendersPhone":     c.cfg.SenderPhone,
+		"CityRecipient":    input.CityRef,
+		"Recipient":        recipient.Ref,
+		"RecipientAddress": input.WarehouseRef,
+		"ContactRecipient": recipient.ContactPerson.Data[0].Ref,
+		"RecipientsPhone":  internationalPhone(input.RecipientPhone),
+	}
+	if input.CashOnDelivery.IsPositive() {
+		props["BackwardDeliveryData"] = []map[string]string{{
+			"PayerType":        "Recipient",
+			"CargoType":        "Money",
+			"RedeliveryString": input.CashOnDelivery.StringFixed(0),
+		}}
+	}
+
+	var data []document
+	if err := c.call(ctx, "InternetDocument", "save", props, &data); err != nil {
+		return models.Waybill{}, err
+	}
+	if len(data) == 0 {
+		return models.Waybill{}, errx.NewInternal().WithDescription("nova poshta returned no waybill")
+	}
+
+	doc := data[0]
+	waybill := models.Waybill{
+		Ref:    doc.Ref,
+		Number: doc.IntDocNumber,
+	}
+	if cost, err := decimal.NewFromString(doc.CostOnSite.String()); err == nil {
+		waybill.Cost = cost
+	}
+	if date, err := time.Parse(dateLayout, doc.EstimatedDeliveryDate); err == nil {
+		waybill.EstimatedDeliveryDate = &date
+	}
+
+	return waybill, nil
+}
+
+// DeleteWaybill deletes a waybill that was created but not handed over to
+// the carrier yet.
+func (c *Client) DeleteWaybill(ctx context.Context, waybill models.Waybill) error {
+	props := map[string]any{
+		"DocumentRefs": waybill.Ref,
+	}
+
+	var data []struct {
+		Ref string `json:"Ref"`
+	}
+	if err := c.call(ctx, "InternetDocument", "delete", props, &data); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return errx.NewInternal().WithDescription("nova poshta did not delete the waybill")
+	}
+
+	return nil
+}
+
+func (c *Client) createRecipient(ctx context.Context, fullName, phone string) (counterparty, error) {
+	lastName, firstName, middleName := splitFullName(fullName)
+
+	var data []counterparty
+	err := c.call(ctx, "Counterparty", "save", map[string]any{
+		"CounterpartyType":     "PrivatePerson",
+		"CounterpartyProperty": "Recipient",
+		"FirstName":            firstName,
+		"MiddleName":           middleName,
+		"LastName":             lastName,
+		"Phone":                internationalPhone(phone),
+	}, &data)
+	if err != nil {
+		return counterparty{}, err
+	}
+	if len(data) == 0 || len(data[0].ContactPerson.Data) == 0 {
+		return counterparty{}, errx.NewInternal().WithDescription("nova poshta returned no recipient")
+	}
+
+	return data[0], nil
+}
+
+type trackingDocument struct {
+	Number     string `json:"Number"`
+	StatusCode string `json:"StatusCode"`
+	Status     string `json:"Status"`
+}
+
+func (c *Client) TrackShipments(ctx context.Context, parcels []dto.CarrierTrackingRequest) ([]models.ShipmentTracking, error) {
+	documents := make([]map[string]string, len(parcels))
+	for i, p := range parcels {
+		documents[i] = map[string]string{
+			"DocumentNumber": p.Number,
+			"Phone":          internationalPhone(p.Phone),
+		}
+	}
+
+	var data []trackingDocument
+	err := c.call(ctx, "TrackingDocument", "getStatusDocuments", map[string]any{
+		"Documents": documents,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	trackings := make([]models.ShipmentTracking, 0, len(data))
+	for _, d := range data {
+		trackings = append(trackings, models.ShipmentTracking{
+			Number:     d.Number,
+			State:      shipmentState(d.StatusCode),
+			StatusText: d.Status,
+		})
+	}
+
+	return trackings, nil
+}
+
+// shipmentState maps Nova Poshta tracking status codes to shipment states.
+func shipmentState(code string) models.ShipmentState {
+	switch code {
+	case "1":
+		return models.ShipmentStateCreated
+	case "4", "5", "6", "41", "101", "104":
+		return models.ShipmentStateInTransit
+	case "7", "8":
+		return models.ShipmentStateArrived
+	case "9", "10", "11":
+		return models.ShipmentStateDelivered
+	case "102", "103", "105", "108":
+		return models.ShipmentStateReturning
+	default:
+		return models.ShipmentStateUnknown
+	}
+}
+
+// internationalPhone turns a local 0XXXXXXXXX number into the 380XXXXXXXXX
+// form Nova Poshta expects.
+func internationalPhone(phone string) string {
+	phone = strings.TrimPrefix(phone, "+")
+	if strings.HasPrefix(phone, "0") {
+		return "38" + phone
+	}
+
+	return phone
+}
+
+// splitFullName splits "Last First Middle" the way Ukrainian names are
+// usually written.
+func splitFullName(fullName string) (last, first, middle string) {
+	parts := strings.Fields(fullName)
+
+	switch len(parts) {
+	case 0:
+		return "", "", ""
+	case 1:
+		return parts[0], parts[0], ""
+	case 2:
+		return parts[0], parts[1], ""
+	default:
+		return parts[0], parts[1], strings.Join(parts[2:], " ")
+	}
+}
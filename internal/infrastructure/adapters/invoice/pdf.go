@@ -0,0 +1,151 @@
+package invoice
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/pkg/pdf"
+	"fmt"
+	"os"
+
+	"github.com/nordew/go-errx"
+)
+
+const (
+	marginLeft  = 40.0
+	marginRight = pdf.PageWidth - 40
+	pageBottom  = pdf.PageHeight - 60
+
+	titleSize = 16.0
+	textSize  = 10.0
+	lineStep  = 16.0
+)
+
+// Column positions of the items table. Numbers are right aligned to the
+// column's edge.
+const (
+	colIndex    = marginLeft
+	colName     = marginLeft + 25
+	colVolume   = 370.0
+	colQuantity = 420.0
+	colPrice    = 480.0
+	colTotal    = marginRight
+)
+
+// PDFRenderer lays out order invoices as single-font A4 PDF documents.
+type PDFRenderer struct {
+	font *pdf.Font
+}
+
+// NewPDFRenderer loads the TrueType font at fontPath. It must cover Cyrillic
+// as invoices are in Ukrainian.
+func NewPDFRenderer(fontPath string) (*PDFRenderer, error) {
+	data, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading invoice font: %w", err)
+	}
+
+	font, err := pdf.ParseTrueType(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing invoice font: %w", err)
+	}
+
+	return &PDFRenderer{font: font}, nil
+}
+
+func (r *PDFRenderer) RenderInvoice(invoice dto.OrderInvoice) ([]byte, error) {
+	doc := pdf.New(r.font)
+	doc.AddPage()
+
+	y := 60.0
+	doc.Text(marginLeft, y, titleSize, "Рахунок на оплату")
+	y += lineStep
+	doc.Text(marginLeft, y, textSize, fmt.Sprintf("№ %s від %s", invoice.Number, invoice.CreatedAt.Format("02.01.2006")))
+
+	y += lineStep * 2
+	y = r.field(doc, y, "Отримувач", invoice.Merchant.Name)
+	y = r.field(doc, y, "РНОКПП / ЄДРПОУ", invoice.Merchant.TaxID)
+	y = r.field(doc, y, "IBAN", invoice.Merchant.IBAN)
+	if invoice.Merchant.Bank != "" {
+		y = r.field(doc, y, "Банк", invoice.Merchant.Bank)
+	}
+
+	y += lineStep / 2
+	y = r.field(doc, y, "Платник", invoice.FullName)
+	y = r.field(doc, y, "Телефон", invoice.PhoneNumber)
+
+	y += lineStep
+	y = r.tableHeader(doc, y)
+
+	for i, item := range invoice.Items {
+		if y > pageBottom {
+			doc.AddPage()
+			y = r.tableHeader(doc, 60)
+		}
+
+		name := doc.Truncate(fmt.Sprintf("%s %s", item.Brand, item.Name), textSize, colVolume-colName-45)
+
+		doc.Text(colIndex, y, textSize, fmt.Sprintf("%d", i+1))
+		doc.Text(colName, y, textSize, name)
+		doc.TextRight(colVolume, y, textSize, fmt.Sprintf("%d мл", item.Volume))
+		doc.TextRight(colQuantity, y, textSize, fmt.Sprintf("%d", item.Quantity))
+		doc.TextRight(colPrice, y, textSize, formatAmount(item.UnitPrice))
+		doc.TextRight(colTotal, y, textSize, formatAmount(item.LineTotal))
+		y += lineStep
+	}
+
+	if y > pageBottom-lineStep*6 {
+		doc.AddPage()
+		y = 60
+	}
+
+	doc.Line(marginLeft, y-lineStep/2, marginRight, y-lineStep/2, 0.5)
+	y += lineStep / 2
+	y = r.total(doc, y, textSize, "Сума товарів", invoice.Subtotal)
+	if invoice.DiscountAmount > 0 {
+		y = r.total(doc, y, textSize, "Знижка", invoice.DiscountAmount)
+	}
+	y = r.total(doc, y, textSize+2, "До сплати", invoice.AmountToPay)
+
+	y += lineStep
+	doc.Text(marginLeft, y, textSize, "Призначення платежу:")
+	y += lineStep
+	doc.Text(marginLeft, y, textSize, invoice.PaymentPurpose)
+
+	return doc.Bytes()
+}
+
+func (r *PDFRenderer) field(doc *pdf.Document, y float64, label, value string) float64 {
+	doc.Text(marginLeft, y, textSize, label+":")
+	doc.Text(marginLeft+120, y, textSize, value)
+
+	return y + lineStep
+}
+
+func (r *PDFRenderer) tableHeader(doc *pdf.Document, y float64) float64 {
+	doc.Text(colIndex, y, textSize, "№")
+	doc.Text(colName, y, textSize, "Товар")
+	doc.TextRight(colVolume, y, textSize, "Об'єм")
+	doc.TextRight(colQuantity, y, textSize, "К-сть")
+	doc.TextRight(colPrice, y, textSize, "Ціна")
+	doc.TextRight(colTotal, y, textSize, "Сума")
+	doc.Line(marginLeft, y+lineStep/2, marginRight, y+lineStep/2, 0.5)
+
+	return y + lineStep*1.5
+}
+
+func (r *PDFRenderer) total(doc *pdf.Document, y, size float64, label string, amount uint) float64 {
+	doc.TextRight(colPrice, y, size, label+":")
+	doc.TextRight(colTotal, y, size, formatAmount(amount))
+
+	return y + lineStep
+}
+
+func formatAmount(amount uint) string {
+	return fmt.Sprintf("%d грн", amount)
+}
+
+// Unavailable stands in for the renderer when no font is configured.
+type Unavailable struct{}
+
+func (Unavailable) RenderInvoice(dto.OrderInvoice) ([]byte, error) {
+	return nil, errx.NewInternal().WithDescription("PDF invoices are not configured")
+}
@@ -2,20 +2,30 @@ package telegram
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"gopkg.in/telebot.v4"
 )
 
+// BroadcastMessage sends an order notice to every admin who handles orders.
+// A failed send does not stop the others; the failures are returned joined.
 func (p *TelegramProvider) BroadcastMessage(ctx context.Context, text string) error {
-	if len(p.adminIDs) == 0 {
+	adminIDs, err := p.orderAdminIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(adminIDs) == 0 {
 		return ErrInvalidRecipientID
 	}
 
-	for id := range p.adminIDs {
+	var errs []error
+	for _, id := range adminIDs {
 		if _, err := p.bot.Send(telebot.ChatID(id), text); err != nil {
-			return ErrSendingMessage
+			errs = append(errs, fmt.Errorf("%w to %d: %v", ErrSendingMessage, id, err))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
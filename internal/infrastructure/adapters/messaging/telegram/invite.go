@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/nordew/go-errx"
+	"gopkg.in/telebot.v4"
+)
+
+// DeepLink returns a t.me link that opens the bot with /start payload.
+func (p *TelegramProvider) DeepLink(payload string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", p.bot.Me.Username, payload)
+}
+
+func (p *TelegramProvider) registerInviteCommand() {
+	p.bot.Handle("/start", p.handleStart)
+}
+
+// handleStart accepts an admin invite when the bot is opened through an
+// invite link.
+func (p *TelegramProvider) handleStart(c telebot.Context) error {
+	token := c.Message().Payload
+	if token == "" || p.registrar == nil {
+		return c.Send("Hello! Admins can use /login to get a login code.")
+	}
+
+	vendorID := strconv.FormatInt(c.Sender().ID, 10)
+
+	admin, err := p.registrar.AcceptAdminInvite(context.Background(), token, vendorID)
+	if err != nil {
+		switch {
+		case errx.IsCode(err, errx.AlreadyExists):
+			return c.Send("You are already an admin. Use /login to get a login code.")
+		case errx.IsCode(err, errx.NotFound):
+			return c.Send("This invite link is invalid, expired or already used.")
+		default:
+			return c.Send("Could not accept the invite, try again later.")
+		}
+	}
+
+	return c.Send(fmt.Sprintf("✅ You are now an admin (%s). Use /login to get a login code.", admin.Role))
+}
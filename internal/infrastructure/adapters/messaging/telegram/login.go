@@ -1,9 +1,12 @@
 package telegram
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
-	"time"
+
+	"aroma-hub/pkg/otp_store"
 
 	"gopkg.in/telebot.v4"
 )
@@ -16,7 +19,11 @@ func (p *TelegramProvider) handleLogin(c telebot.Context) error {
 	userID := c.Sender().ID
 	vendorID := strconv.FormatInt(userID, 10)
 
-	if ok := p.isAdmin(userID); !ok {
+	ok, err := p.isAdmin(context.Background(), userID)
+	if err != nil {
+		return c.Send("Could not check your access, try again later.")
+	}
+	if !ok {
 		return c.Send("You are not authorized to login.")
 	}
 
@@ -25,8 +32,13 @@ func (p *TelegramProvider) handleLogin(c telebot.Context) error {
 		return c.Send(fmt.Sprintf("Error generating OTP: %v", err))
 	}
 
-	go p.cache.SetWithTTL(otp, vendorID, 5*time.Minute)
+	if err := p.codes.Issue(vendorID, otp); err != nil {
+		if errors.Is(err, otp_store.ErrLocked) {
+			return c.Send(fmt.Sprintf("⛔ Login is locked after too many wrong codes (%v).", err))
+		}
+		return c.Send("Could not issue a login code, try again later.")
+	}
 
-	msg := fmt.Sprintf("🔐 Your login OTP is: `%s`", otp)
+	msg := fmt.Sprintf("🔐 Your login OTP is: `%s`\nTelegram ID: `%s`", otp, vendorID)
 	return c.Send(msg)
 }
@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"time"
 
-	stash "github.com/nordew/go-stash"
 	"gopkg.in/telebot.v4"
 )
 
@@ -19,6 +18,16 @@ type Storage interface {
 	UpdateOrder(ctx context.Context, input dto.UpdateOrderRequest) error
 }
 
+// AdminRegistrar turns an accepted invite into an admin account.
+type AdminRegistrar interface {
+	AcceptAdminInvite(ctx context.Context, token, vendorID string) (models.Admin, error)
+}
+
+// LoginCodeIssuer remembers the one-time login code sent to an admin.
+type LoginCodeIssuer interface {
+	Issue(account, code string) error
+}
+
 type OTPGenerator interface {
 	GenerateOTP(accountName string) (string, error)
 }
@@ -33,15 +42,16 @@ type TelegramProvider struct {
 	apiToken string
 	storage  Storage
 	otpGen   OTPGenerator
-	cache    stash.Cache
-	adminIDs map[int64]struct{}
+	codes    LoginCodeIssuer
+
+	registrar AdminRegistrar
 }
 
 func NewTelegramProvider(
 	apiToken string,
 	storage Storage,
 	otpGen OTPGenerator,
-	cache stash.Cache,
+	codes LoginCodeIssuer,
 ) (*TelegramProvider, error) {
 	pref := telebot.Settings{
 		Token:     apiToken,
@@ -62,18 +72,22 @@ func NewTelegramProvider(
 		apiToken: apiToken,
 		storage:  storage,
 		otpGen:   otpGen,
-		cache:    cache,
-		adminIDs: make(map[int64]struct{}),
+		codes:    codes,
 	}, nil
 }
 
+// SetAdminRegistrar enables admin invite links. It must be called before
+// Start.
+func (p *TelegramProvider) SetAdminRegistrar(registrar AdminRegistrar) {
+	p.registrar = registrar
+}
+
 func (p *TelegramProvider) registerCommands() {
 	p.registerLoginCommand()
+	p.registerInviteCommand()
 }
 
 func (p *TelegramProvider) Start() {
-	p.EnrichAdmins(context.Background())
-
 	p.registerCommands()
 
 	p.bot.Start()
@@ -83,28 +97,42 @@ func (p *TelegramProvider) Stop() {
 	p.bot.Stop()
 }
 
-func (p *TelegramProvider) EnrichAdmins(ctx context.Context) error {
-	admins, err := p.storage.ListAdmins(ctx, dto.ListAdminFilter{})
+// orderAdminIDs returns the Telegram IDs of the active admins allowed to see
+// orders, as broadcasts carry customers' names, phones and addresses. It
+// reads storage every time, so admins added, removed or given another role
+// through the API take effect at once.
+func (p *TelegramProvider) orderAdminIDs(ctx context.Context) ([]int64, error) {
+	admins, err := p.storage.ListAdmins(ctx, dto.ListAdminFilter{Active: true})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	ids := make([]int64, 0, len(admins))
 	for _, admin := range admins {
-		int64AdminVendorID, err := strconv.ParseInt(admin.VendorID, 10, 64)
-		if err != nil {
-			return err
+		if admin.VendorType != models.VendorTelegram || !admin.Role.Can(models.PermissionOrders) {
+			continue
 		}
 
-		log.Printf("Admin ID: %d", int64AdminVendorID)
+		id, err := strconv.ParseInt(admin.VendorID, 10, 64)
+		if err != nil {
+			log.Printf("Skipping admin %s with invalid Telegram ID %q", admin.ID, admin.VendorID)
+			continue
+		}
 
-		p.adminIDs[int64AdminVendorID] = struct{}{}
+		ids = append(ids, id)
 	}
 
-	return nil
+	return ids, nil
 }
 
-func (p *TelegramProvider) isAdmin(userID int64) bool {
-	_, ok := p.adminIDs[userID]
+func (p *TelegramProvider) isAdmin(ctx context.Context, userID int64) (bool, error) {
+	admins, err := p.storage.ListAdmins(ctx, dto.ListAdminFilter{
+		VendorID: strconv.FormatInt(userID, 10),
+		Active:   true,
+	})
+	if err != nil {
+		return false, err
+	}
 
-	return ok
+	return len(admins) > 0, nil
 }
@@ -0,0 +1,152 @@
+package fake
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const providerName = "fake"
+
+// Provider is an in-memory payment provider for tests and local development.
+// Webhook bodies are JSON events signed with a hex HMAC-SHA256 of the body;
+// Webhook builds one for a test to post.
+type Provider struct {
+	mu        sync.Mutex
+	secret    []byte
+	checkout  string
+	invoices  map[string]decimal.Decimal
+	refunds   map[string]decimal.Decimal
+	cancelled map[string]bool
+}
+
+type webhookPayload struct {
+	InvoiceID string               `json:"invoiceId"`
+	Status    models.PaymentStatus `json:"status"`
+	Amount    decimal.Decimal      `json:"amount"`
+}
+
+func NewProvider(secret, checkoutBaseURL string) *Provider {
+	return &Provider{
+		secret:    []byte(secret),
+		checkout:  checkoutBaseURL,
+		invoices:  make(map[string]decimal.Decimal),
+		refunds:   make(map[string]decimal.Decimal),
+		cancelled: make(map[string]bool),
+	}
+}
+
+func (p *Provider) Name() string {
+	return providerName
+}
+
+func (p *Provider) CreateInvoice(_ context.Context, input dto.PaymentInvoiceRequest) (dto.PaymentInvoice, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := uuid.NewString()
+	p.invoices[id] = input.Amount
+
+	return dto.PaymentInvoice{
+		InvoiceID:   id,
+		CheckoutURL: fmt.Sprintf("%s/%s", p.checkout, id),
+	}, nil
+}
+
+func (p *Provider) VerifyWebhook(_ context.Context, body []byte, signature string) (dto.PaymentEvent, error) {
+	if !hmac.Equal([]byte(p.sign(body)), []byte(signature)) {
+		return dto.PaymentEvent{}, errx.NewUnauthorized().WithDescription("invalid payment webhook signature")
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return dto.PaymentEvent{}, errx.NewBadRequest().WithDescriptionAndCause("invalid payment webhook body", err)
+	}
+
+	return dto.PaymentEvent{
+		InvoiceID: payload.InvoiceID,
+		Status:    payload.Status,
+		Amount:    payload.Amount,
+	}, nil
+}
+
+func (p *Provider) Refund(_ context.Context, invoiceID string, amount decimal.Decimal) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	paid, ok := p.invoices[invoiceID]
+	if !ok {
+		return errx.NewNotFound().WithDescription("invoice not found")
+	}
+	if p.refunds[invoiceID].Add(amount).GreaterThan(paid) {
+		return errx.NewBadRequest().WithDescription("refund exceeds the paid amount")
+	}
+
+	p.refunds[invoiceID] = p.refunds[invoiceID].Add(amount)
+
+	return nil
+}
+
+func (p *Provider) CancelInvoice(_ context.Context, invoiceID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.invoices[invoiceID]; !ok {
+		return errx.NewNotFound().WithDescription("invoice not found")
+	}
+	p.cancelled[invoiceID] = true
+
+	return nil
+}
+
+// Cancelled reports whether the invoice was voided.
+func (p *Provider) Cancelled(invoiceID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.cancelled[invoiceID]
+}
+
+// Webhook returns a signed webhook body and signature reporting the invoice
+// in the given status.
+func (p *Provider) Webhook(invoiceID string, status models.PaymentStatus) ([]byte, string, error) {
+	p.mu.Lock()
+	amount := p.invoices[invoiceID]
+	p.mu.Unlock()
+
+	body, err := json.Marshal(webhookPayload{
+		InvoiceID: invoiceID,
+		Status:    status,
+		Amount:    amount,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, p.sign(body), nil
+}
+
+// Refunded returns how much has been refunded on the invoice.
+func (p *Provider) Refunded(invoiceID string) decimal.Decimal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.refunds[invoiceID]
+}
+
+func (p *Provider) sign(body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,307 @@
+package monobank
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/config"
+	"aroma-hub/internal/models"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	providerName = "monobank"
+
+	defaultBaseURL = "https://api.monobank.ua"
+	requestTimeout = 15 * time.Second
+	currencyUAH    = 980
+
+	// publicKeyRefetchInterval bounds how often a bad signature makes the
+	// client refetch the public key, so forged webhooks cannot hammer
+	// Monobank through it.
+	publicKeyRefetchInterval = time.Minute
+)
+
+var kopecksPerHryvnia = decimal.NewFromInt(100)
+
+// Client works with Monobank acquiring. Amounts are sent in kopecks and
+// webhooks are signed with ECDSA over SHA-256 using the merchant public key.
+type Client struct {
+	httpClient *http.Client
+	cfg        config.Monobank
+
+	mu             sync.Mutex
+	publicKey      *ecdsa.PublicKey
+	publicKeyFetch time.Time
+}
+
+func NewClient(cfg config.Monobank) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		cfg:        cfg,
+	}
+}
+
+func (c *Client) Name() string {
+	return providerName
+}
+
+type merchantPaymInfo struct {
+	Reference   string `json:"reference"`
+	Destination string `json:"destination"`
+}
+
+type createInvoiceRequest struct {
+	Amount           int64            `json:"amount"`
+	Ccy              int              `json:"ccy"`
+	MerchantPaymInfo merchantPaymInfo `json:"merchantPaymInfo"`
+	RedirectURL      string           `json:"redirectUrl,omitempty"`
+	WebHookURL       string           `json:"webHookUrl,omitempty"`
+	Validity         int64            `json:"validity,omitempty"`
+}
+
+type createInvoiceResponse struct {
+	InvoiceID string `json:"invoiceId"`
+	PageURL   string `json:"pageUrl"`
+}
+
+func (c *Client) CreateInvoice(ctx context.Context, input dto.PaymentInvoiceRequest) (dto.PaymentInvoice, error) {
+	req := createInvoiceRequest{
+		Amount: toKopecks(input.Amount),
+		Ccy:    currencyUAH,
+		MerchantPaymInfo: merchantPaymInfo{
+			Reference:   input.OrderID,
+			Destination: input.Description,
+		},
+		RedirectURL: c.cfg.RedirectURL,
+		WebHookURL:  c.cfg.WebhookURL,
+		Validity:    int64(c.cfg.InvoiceValidity.Seconds()),
+	}
+
+	var resp createInvoiceResponse
+	if err := c.do(ctx, http.MethodPost, "/api/merchant/invoice/create", req, &resp); err != nil {
+		return dto.PaymentInvoice{}, err
+	}
+
+	return dto.PaymentInvoice{
+		InvoiceID:   resp.InvoiceID,
+		CheckoutURL: resp.PageURL,
+	}, nil
+}
+
+type webhookPayload struct {
+	InvoiceID string `json:"invoiceId"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+}
+
+// VerifyWebhook checks the X-Sign signature of a webhook body. The public key
+// is fetched once and refetched when a signature does not match, in case
+// Monobank rotated it, at most once per publicKeyRefetchInterval.
+func (c *Client) VerifyWebhook(ctx context.Context, body []byte, signature string) (dto.PaymentEvent, error) {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) == 0 {
+		return dto.PaymentEvent{}, errx.NewUnauthorized().WithDescription("invalid payment webhook signature")
+	}
+
+	hash := sha256.Sum256(body)
+
+	key, err := c.merchantPublicKey(ctx, false)
+	if err != nil {
+		return dto.PaymentEvent{}, err
+	}
+	if !ecdsa.VerifyASN1(key, hash[:], sig) {
+		key, err = c.merchantPublicKey(ctx, true)
+		if err != nil {
+			return dto.PaymentEvent{}, err
+		}
+		if !ecdsa.VerifyASN1(key, hash[:], sig) {
+			return dto.PaymentEvent{}, errx.NewUnauthorized().WithDescription("invalid payment webhook signature")
+		}
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return dto.PaymentEvent{}, errx.NewBadRequest().WithDescriptionAndCause("invalid payment webhook body", err)
+	}
+
+	return dto.PaymentEvent{
+		InvoiceID: payload.InvoiceID,
+		Status:    paymentStatus(payload.Status),
+		Amount:    fromKopecks(payload.Amount),
+	}, nil
+}
+
+type cancelInvoiceRequest struct {
+	InvoiceID string `json:"invoiceId"`
+	Amount    int64  `json:"amount,omitempty"`
+}
+
+func (c *Client) Refund(ctx context.Context, invoiceID string, amount decimal.Decimal) error {
+	req := cancelInvoiceRequest{
+		InvoiceID: invoiceID,
+		Amount:    toKopecks(amount),
+	}
+
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/merchant/invoice/cancel", req, &resp); err != nil {
+		return err
+	}
+
+	if resp.Status == "failure" {
+		return errx.NewBadRequest().WithDescription("monobank rejected the refund")
+	}
+
+	return nil
+}
+
+type removeInvoiceRequest struct {
+	InvoiceID string `json:"invoiceId"`
+}
+
+func (c *Client) CancelInvoice(ctx context.Context, invoiceID string) error {
+	return c.do(ctx, http.MethodPost, "/api/merchant/invoice/remove", removeInvoiceRequest{InvoiceID: invoiceID}, nil)
+}
+
+func (c *Client) merchantPublicKey(ctx context.Context, refresh bool) (*ecdsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.publicKey != nil && (!refresh || time.Since(c.publicKeyFetch) < publicKeyRefetchInterval) {
+		return c.publicKey, nil
+	}
+
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/merchant/pubkey", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	key, err := parsePublicKey(resp.Key)
+	if err != nil {
+		return nil, err
+	}
+	c.publicKey = key
+	c.publicKeyFetch = time.Now()
+
+	return key, nil
+}
+
+func parsePublicKey(encoded string) (*ecdsa.PublicKey, error) {
+	pemBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to decode monobank public key", err)
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errx.NewInternal().WithDescription("monobank public key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to parse monobank public key", err)
+	}
+
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errx.NewInternal().WithDescription("monobank public key is not an ECDSA key")
+	}
+
+	return key, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return errx.NewInternal().WithDescriptionAndCause("failed to encode monobank request", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, body)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to build monobank request", err)
+	}
+	req.Header.Set("X-Token", c.cfg.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("monobank is unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			ErrCode string `json:"errCode"`
+			ErrText string `json:"errText"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		description := fmt.Sprintf("monobank responded with status %d: %s %s", resp.StatusCode, apiErr.ErrCode, apiErr.ErrText)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return errx.NewInternal().WithDescription(description)
+		}
+
+		return errx.NewBadRequest().WithDescription(description)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to decode monobank response", err)
+	}
+
+	return nil
+}
+
+// paymentStatus maps Monobank invoice statuses to payment statuses.
+// Intermediate states (created, processing, hold) map to an empty status.
+func paymentStatus(status string) models.PaymentStatus {
+	switch status {
+	case "success":
+		return models.PaymentStatusPaid
+	case "failure", "expired":
+		return models.PaymentStatusFailed
+	case "reversed":
+		return models.PaymentStatusRefunded
+	default:
+		return ""
+	}
+}
+
+func toKopecks(amount decimal.Decimal) int64 {
+	return amount.Mul(kopecksPerHryvnia).Round(0).IntPart()
+}
+
+func fromKopecks(amount int64) decimal.Decimal {
+	return decimal.NewFromInt(amount).Div(kopecksPerHryvnia)
+}
@@ -4,6 +4,7 @@ import (
 	"aroma-hub/internal/application/dto"
 	"aroma-hub/internal/models"
 	"context"
+	"fmt"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
@@ -42,13 +43,26 @@ func (s *Storage) buildSearchAdminQuery(_ context.Context, filter dto.ListAdminF
 		"id",
 		"vendor_id",
 		"vendor_type",
+		"role",
+		"disabled_at",
 		"created_at",
 		"updated_at",
 	).From("admins")
 
+	if filter.ID != "" {
+		query = query.Where(squirrel.Eq{"id": filter.ID})
+	}
 	if filter.VendorID != "" {
 		query = query.Where(squirrel.Eq{"vendor_id": filter.VendorID})
 	}
+	if filter.Role != "" {
+		query = query.Where(squirrel.Eq{"role": filter.Role})
+	}
+	if filter.Active {
+		query = query.Where(squirrel.Eq{"disabled_at": nil})
+	}
+
+	query = query.OrderBy("created_at")
 
 	return query
 }
@@ -62,6 +76,8 @@ func (s *Storage) scanAdmins(rows pgx.Rows) ([]models.Admin, error) {
 			&admin.ID,
 			&admin.VendorID,
 			&admin.VendorType,
+			&admin.Role,
+			&admin.DisabledAt,
 			&admin.CreatedAt,
 			&admin.UpdatedAt,
 		)
@@ -85,3 +101,99 @@ func (s *Storage) scanAdmins(rows pgx.Rows) ([]models.Admin, error) {
 
 	return admins, nil
 }
+
+func (s *Storage) CreateAdmin(ctx context.Context, admin models.Admin) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO admins (id, vendor_id, vendor_type, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		`,
+		admin.ID,
+		admin.VendorID,
+		admin.VendorType,
+		admin.Role,
+		admin.CreatedAt,
+		admin.UpdatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "admin", admin.VendorID)
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateAdminRole(ctx context.Context, id string, role models.AdminRole) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE admins SET role = $2 WHERE id = $1",
+		id,
+		role,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("admin role update failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("admin with id '%s' not found", id))
+	}
+
+	return nil
+}
+
+// LockAdminsByRole holds the rows of every active admin with the role until
+// the surrounding transaction ends and returns how many there are.
+func (s *Storage) LockAdminsByRole(ctx context.Context, role models.AdminRole) (int64, error) {
+	rows, err := s.GetQuerier().Query(
+		ctx,
+		"SELECT id FROM admins WHERE role = $1 AND disabled_at IS NULL FOR UPDATE",
+		role,
+	)
+	if err != nil {
+		return 0, errx.NewInternal().WithDescriptionAndCause(ErrFailedToQueryAdmins, err)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, errx.NewInternal().WithDescriptionAndCause(ErrRowsError, err)
+	}
+
+	return count, nil
+}
+
+// SetAdminDisabled disables an admin, or enables one again when disabled is
+// false.
+func (s *Storage) SetAdminDisabled(ctx context.Context, id string, disabled bool) error {
+	query := "UPDATE admins SET disabled_at = NULL WHERE id = $1"
+	if disabled {
+		query = "UPDATE admins SET disabled_at = COALESCE(disabled_at, NOW()) WHERE id = $1"
+	}
+
+	result, err := s.GetQuerier().Exec(ctx, query, id)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("admin update failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("admin with id '%s' not found", id))
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteAdmin(ctx context.Context, id string) error {
+	result, err := s.GetQuerier().Exec(ctx, "DELETE FROM admins WHERE id = $1", id)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("admin deletion failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("admin with id '%s' not found", id))
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nordew/go-errx"
+)
+
+func (s *Storage) CreateAdminInvite(ctx context.Context, invite models.AdminInvite) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO admin_invites (id, token_hash, role, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		`,
+		invite.ID,
+		invite.TokenHash,
+		invite.Role,
+		invite.CreatedBy,
+		invite.ExpiresAt,
+		invite.CreatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "admin invite", invite.ID)
+	}
+
+	return nil
+}
+
+// LockAdminInviteByTokenHash reads an invite and holds its row until the
+// surrounding transaction ends, so it is accepted only once.
+func (s *Storage) LockAdminInviteByTokenHash(ctx context.Context, tokenHash string) (models.AdminInvite, error) {
+	var (
+		invite      models.AdminInvite
+		usedByAdmin *string
+	)
+
+	err := s.GetQuerier().QueryRow(
+		ctx,
+		`
+		SELECT id, token_hash, role, created_by, expires_at, used_at, used_by_admin_id::TEXT, created_at
+		FROM admin_invites
+		WHERE token_hash = $1
+		FOR UPDATE
+		`,
+		tokenHash,
+	).Scan(
+		&invite.ID,
+		&invite.TokenHash,
+		&invite.Role,
+		&invite.CreatedBy,
+		&invite.ExpiresAt,
+		&invite.UsedAt,
+		&usedByAdmin,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.AdminInvite{}, errx.NewNotFound().WithDescription("admin invite not found")
+		}
+
+		return models.AdminInvite{}, errx.NewInternal().WithDescriptionAndCause("failed to lock admin invite", err)
+	}
+
+	if usedByAdmin != nil {
+		invite.UsedByAdmin = *usedByAdmin
+	}
+
+	return invite, nil
+}
+
+func (s *Storage) MarkAdminInviteUsed(ctx context.Context, id, adminID string) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE admin_invites SET used_at = NOW(), used_by_admin_id = $2 WHERE id = $1 AND used_at IS NULL",
+		id,
+		adminID,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("admin invite update failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription("admin invite not found")
+	}
+
+	return nil
+}
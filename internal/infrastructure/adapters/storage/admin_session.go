@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nordew/go-errx"
+)
+
+func (s *Storage) CreateAdminSession(ctx context.Context, session models.AdminSession) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO admin_sessions (id, admin_id, refresh_token_id, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		`,
+		session.ID,
+		session.AdminID,
+		session.RefreshTokenID,
+		session.ExpiresAt,
+		session.CreatedAt,
+		session.UpdatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "admin session", session.ID)
+	}
+
+	return nil
+}
+
+// LockAdminSession reads a session and holds its row until the surrounding
+// transaction ends, so a refresh token is rotated only once.
+func (s *Storage) LockAdminSession(ctx context.Context, id string) (models.AdminSession, error) {
+	var session models.AdminSession
+
+	err := s.GetQuerier().QueryRow(
+		ctx,
+		`
+		SELECT id, admin_id, refresh_token_id, expires_at, revoked_at, created_at, updated_at
+		FROM admin_sessions
+		WHERE id = $1
+		FOR UPDATE
+		`,
+		id,
+	).Scan(
+		&session.ID,
+		&session.AdminID,
+		&session.RefreshTokenID,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.AdminSession{}, errx.NewNotFound().WithDescription("admin session not found")
+		}
+
+		return models.AdminSession{}, errx.NewInternal().WithDescriptionAndCause("failed to lock admin session", err)
+	}
+
+	return session, nil
+}
+
+// RotateAdminSession makes refreshTokenID the only refresh token the session
+// accepts.
+func (s *Storage) RotateAdminSession(ctx context.Context, session models.AdminSession) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		UPDATE admin_sessions
+		SET refresh_token_id = $2, expires_at = $3, updated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+		`,
+		session.ID,
+		session.RefreshTokenID,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("admin session rotation failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription("admin session not found")
+	}
+
+	return nil
+}
+
+// RevokeAdminSession ends one session. Revoking an already revoked session is
+// a no-op.
+func (s *Storage) RevokeAdminSession(ctx context.Context, id string) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE admin_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL",
+		id,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("admin session revocation failed", err)
+	}
+
+	return nil
+}
+
+// RevokeAdminSessions ends every session of the admin and returns how many
+// were still active.
+func (s *Storage) RevokeAdminSessions(ctx context.Context, adminID string) (int64, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE admin_sessions SET revoked_at = NOW() WHERE admin_id = $1 AND revoked_at IS NULL",
+		adminID,
+	)
+	if err != nil {
+		return 0, errx.NewInternal().WithDescriptionAndCause("admin sessions revocation failed", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nordew/go-errx"
+)
+
+// CreateIdempotencyKey stores the key unless a live one is already taken and
+// reports whether it was stored. An expired key that was not purged yet is
+// taken over. A concurrent insert of the same key waits for the other
+// transaction to finish first.
+func (s *Storage) CreateIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (bool, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO idempotency_keys (key, request_hash, order_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    order_id = EXCLUDED.order_id,
+		    created_at = EXCLUDED.created_at,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		`,
+		key.Key,
+		key.RequestHash,
+		key.OrderID,
+		key.CreatedAt,
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return false, errx.NewInternal().WithDescriptionAndCause("failed to store idempotency key", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// GetIdempotencyKey returns the key unless it has expired.
+func (s *Storage) GetIdempotencyKey(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	var k models.IdempotencyKey
+
+	err := s.GetQuerier().QueryRow(
+		ctx,
+		"SELECT key, request_hash, order_id, created_at, expires_at FROM idempotency_keys WHERE key = $1 AND expires_at > NOW()",
+		key,
+	).Scan(&k.Key, &k.RequestHash, &k.OrderID, &k.CreatedAt, &k.ExpiresAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.IdempotencyKey{}, errx.NewNotFound().WithDescription("idempotency key not found")
+		}
+
+		return models.IdempotencyKey{}, errx.NewInternal().WithDescriptionAndCause("failed to get idempotency key", err)
+	}
+
+	return k, nil
+}
+
+func (s *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.GetQuerier().Exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", now)
+	if err != nil {
+		return 0, errx.NewInternal().WithDescriptionAndCause("failed to delete expired idempotency keys", err)
+	}
+
+	return result.RowsAffected(), nil
+}
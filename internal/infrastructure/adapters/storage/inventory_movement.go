@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/nordew/go-errx"
+)
+
+func (s *Storage) CreateInventoryMovement(ctx context.Context, movement models.InventoryMovement) error {
+	query := `
+		INSERT INTO inventory_movements (
+			id,
+			product_id,
+			variant_id,
+			delta,
+			reason,
+			reference_id,
+			admin_id,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::UUID, $8)
+	`
+	_, err := s.GetQuerier().Exec(ctx, query,
+		movement.ID,
+		movement.ProductID,
+		movement.VariantID,
+		movement.Delta,
+		movement.Reason,
+		movement.ReferenceID,
+		movement.AdminID,
+		movement.CreatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "inventory movement", movement.ID)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListInventoryMovements(
+	ctx context.Context,
+	filter dto.ListInventoryMovementFilter,
+) ([]models.InventoryMovement, int64, error) {
+	baseQuery := s.Builder().Select(
+		"id",
+		"product_id",
+		"variant_id",
+		"delta",
+		"reason",
+		"reference_id",
+		"COALESCE(admin_id::TEXT, '')",
+		"created_at",
+	).From("inventory_movements")
+
+	countQuery := s.Builder().Select("COUNT(*)").From("inventory_movements")
+
+	if filter.ProductID != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"product_id": filter.ProductID})
+		countQuery = countQuery.Where(squirrel.Eq{"product_id": filter.ProductID})
+	}
+	if filter.VariantID != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"variant_id": filter.VariantID})
+		countQuery = countQuery.Where(squirrel.Eq{"variant_id": filter.VariantID})
+	}
+	if filter.Reason != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"reason": filter.Reason})
+		countQuery = countQuery.Where(squirrel.Eq{"reason": filter.Reason})
+	}
+	if filter.ReferenceID != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"reference_id": filter.ReferenceID})
+		countQuery = countQuery.Where(squirrel.Eq{"reference_id": filter.ReferenceID})
+	}
+
+	limit := uint(10)
+	if filter.Limit > 0 && filter.Limit <= 100 {
+		limit = filter.Limit
+	}
+
+	offset := uint(0)
+	if filter.Page > 0 {
+		offset = (filter.Page - 1) * limit
+	}
+
+	baseQuery = baseQuery.OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	var totalCount int64
+	if err := s.squirrelHelper.QueryRow(ctx, s.GetQuerier(), countQuery).Scan(&totalCount); err != nil {
+		return nil, 0, errx.NewInternal().WithDescriptionAndCause("failed to count inventory movements", err)
+	}
+	if totalCount == 0 {
+		return []models.InventoryMovement{}, 0, errx.NewNotFound().WithDescription("no inventory movements found")
+	}
+
+	rows, err := s.squirrelHelper.Query(ctx, s.GetQuerier(), baseQuery)
+	if err != nil {
+		return nil, 0, errx.NewInternal().WithDescriptionAndCause("failed to query inventory movements", err)
+	}
+	defer rows.Close()
+
+	movements, err := s.scanInventoryMovements(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return movements, totalCount, nil
+}
+
+func (s *Storage) scanInventoryMovements(rows pgx.Rows) ([]models.InventoryMovement, error) {
+	var movements []models.InventoryMovement
+
+	for rows.Next() {
+		var m models.InventoryMovement
+
+		err := rows.Scan(
+			&m.ID,
+			&m.ProductID,
+			&m.VariantID,
+			&m.Delta,
+			&m.Reason,
+			&m.ReferenceID,
+			&m.AdminID,
+			&m.CreatedAt,
+		)
+		if err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan inventory movement", err)
+		}
+
+		movements = append(movements, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("rows error", err)
+	}
+
+	return movements, nil
+}
+
+// ListInventoryDrifts returns the variants whose stock differs from the sum
+// of their ledger entries.
+func (s *Storage) ListInventoryDrifts(ctx context.Context) ([]models.InventoryDrift, error) {
+	rows, err := s.GetQuerier().Query(
+		ctx,
+		`
+		SELECT pv.product_id, pv.id, pv.sku, pv.stock_amount, COALESCE(SUM(im.delta), 0) AS ledger_amount
+		FROM product_variants pv
+		LEFT JOIN inventory_movements im ON im.variant_id = pv.id
+		GROUP BY pv.id
+		HAVING pv.stock_amount <> COALESCE(SUM(im.delta), 0)
+		ORDER BY pv.product_id, pv.sku
+		`,
+	)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to reconcile inventory", err)
+	}
+	defer rows.Close()
+
+	drifts := make([]models.InventoryDrift, 0)
+	for rows.Next() {
+		var d models.InventoryDrift
+
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.SKU, &d.StockAmount, &d.LedgerAmount); err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan inventory drift", err)
+		}
+
+		d.Drift = d.StockAmount - d.LedgerAmount
+		drifts = append(drifts, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("rows error", err)
+	}
+
+	return drifts, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
 )
 
 func (s *Storage) CreateOrder(ctx context.Context, order models.Order) (models.Order, error) {
@@ -22,13 +23,21 @@ func (s *Storage) CreateOrder(ctx context.Context, order models.Order) (models.O
 			payment_method,
 			promo_code,
 			contact_type,
+			subtotal,
+			discount_amount,
 			amount_to_pay,
 			status,
+			payment_status,
+			tracking_token,
+			delivery_carrier,
+			delivery_city_ref,
+			delivery_warehouse_ref,
+			tracking_number,
 			created_at,
 			updated_at
 		)
 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, ''), $14, $15, $16, $17, $18, $19)
 
 		RETURNING
 
@@ -39,8 +48,16 @@ func (s *Storage) CreateOrder(ctx context.Context, order models.Order) (models.O
 		payment_method,
 		promo_code,
 		contact_type,
+		subtotal,
+		discount_amount,
 		amount_to_pay,
 		status,
+		payment_status,
+		COALESCE(tracking_token, ''),
+		delivery_carrier,
+		delivery_city_ref,
+		delivery_warehouse_ref,
+		tracking_number,
 		created_at,
 		updated_at
 	`
@@ -54,8 +71,16 @@ func (s *Storage) CreateOrder(ctx context.Context, order models.Order) (models.O
 		order.PaymentMethod,
 		order.PromoCode,
 		order.ContactType,
+		order.Subtotal,
+		order.DiscountAmount,
 		order.AmountToPay,
 		order.Status,
+		order.PaymentStatus,
+		order.TrackingToken,
+		order.DeliveryCarrier,
+		order.DeliveryCityRef,
+		order.DeliveryWarehouseRef,
+		order.TrackingNumber,
 		order.CreatedAt,
 		order.UpdatedAt,
 	).Scan(
@@ -66,8 +91,16 @@ func (s *Storage) CreateOrder(ctx context.Context, order models.Order) (models.O
 		&result.PaymentMethod,
 		&result.PromoCode,
 		&result.ContactType,
+		&result.Subtotal,
+		&result.DiscountAmount,
 		&result.AmountToPay,
 		&result.Status,
+		&result.PaymentStatus,
+		&result.TrackingToken,
+		&result.DeliveryCarrier,
+		&result.DeliveryCityRef,
+		&result.DeliveryWarehouseRef,
+		&result.TrackingNumber,
 		&result.CreatedAt,
 		&result.UpdatedAt,
 	)
@@ -134,8 +167,17 @@ func (s *Storage) buildSearchOrderQuery(filter dto.ListOrderFilter) (squirrel.Se
 		"payment_method",
 		"promo_code",
 		"contact_type",
+		"subtotal",
+		"discount_amount",
 		"amount_to_pay",
+		"refunded_amount",
 		"status",
+		"payment_status",
+		"COALESCE(tracking_token, '')",
+		"delivery_carrier",
+		"delivery_city_ref",
+		"delivery_warehouse_ref",
+		"tracking_number",
 		"created_at",
 		"updated_at",
 	).From("orders")
@@ -146,6 +188,10 @@ func (s *Storage) buildSearchOrderQuery(filter dto.ListOrderFilter) (squirrel.Se
 		baseQuery = baseQuery.Where(squirrel.Eq{"id": filter.IDs})
 		countQuery = countQuery.Where(squirrel.Eq{"id": filter.IDs})
 	}
+	if filter.TrackingToken != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"tracking_token": filter.TrackingToken})
+		countQuery = countQuery.Where(squirrel.Eq{"tracking_token": filter.TrackingToken})
+	}
 	if filter.UserID != "" {
 		baseQuery = baseQuery.Where(squirrel.Eq{"user_id": filter.UserID})
 		countQuery = countQuery.Where(squirrel.Eq{"user_id": filter.UserID})
@@ -162,6 +208,18 @@ func (s *Storage) buildSearchOrderQuery(filter dto.ListOrderFilter) (squirrel.Se
 		baseQuery = baseQuery.Where(squirrel.Eq{"status": filter.Status})
 		countQuery = countQuery.Where(squirrel.Eq{"status": filter.Status})
 	}
+	if filter.PaymentStatus != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"payment_status": filter.PaymentStatus})
+		countQuery = countQuery.Where(squirrel.Eq{"payment_status": filter.PaymentStatus})
+	}
+	if len(filter.Statuses) > 0 {
+		baseQuery = baseQuery.Where(squirrel.Eq{"status": filter.Statuses})
+		countQuery = countQuery.Where(squirrel.Eq{"status": filter.Statuses})
+	}
+	if filter.HasTrackingNumber {
+		baseQuery = baseQuery.Where(squirrel.NotEq{"tracking_number": ""})
+		countQuery = countQuery.Where(squirrel.NotEq{"tracking_number": ""})
+	}
 	if filter.FromDate != nil {
 		baseQuery = baseQuery.Where(squirrel.GtOrEq{"created_at": filter.FromDate})
 		countQuery = countQuery.Where(squirrel.GtOrEq{"created_at": filter.FromDate})
@@ -188,8 +246,17 @@ func (s *Storage) scanOrders(rows pgx.Rows) ([]models.Order, error) {
 			&order.PaymentMethod,
 			&order.PromoCode,
 			&order.ContactType,
+			&order.Subtotal,
+			&order.DiscountAmount,
 			&order.AmountToPay,
+			&order.RefundedAmount,
 			&order.Status,
+			&order.PaymentStatus,
+			&order.TrackingToken,
+			&order.DeliveryCarrier,
+			&order.DeliveryCityRef,
+			&order.DeliveryWarehouseRef,
+			&order.TrackingNumber,
 			&order.CreatedAt,
 			&order.UpdatedAt,
 		)
@@ -259,6 +326,12 @@ func (s *Storage) UpdateOrder(ctx context.Context, input dto.UpdateOrderRequest)
 		paramCount++
 	}
 
+	if input.TrackingNumber != "" {
+		setClauses = append(setClauses, fmt.Sprintf("tracking_number = $%d", paramCount))
+		args = append(args, input.TrackingNumber)
+		paramCount++
+	}
+
 	setClauses = append(setClauses, "updated_at = NOW()")
 
 	if len(setClauses) == 0 {
@@ -293,6 +366,127 @@ func (s *Storage) orderExists(ctx context.Context, orderID string) (bool, error)
 	return exists, nil
 }
 
+// SetOrderTrackingToken replaces the order's tracking token. An empty token
+// revokes tracking until a new one is issued.
+func (s *Storage) SetOrderTrackingToken(ctx context.Context, id, token string) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE orders SET tracking_token = NULLIF($2, ''), updated_at = NOW() WHERE id = $1",
+		id,
+		token,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to set order tracking token", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("order with id '%s' not found", id))
+	}
+
+	return nil
+}
+
+// SetOrderWaybill stores the waybill a carrier issued for the order. It
+// reports false when the order already has one.
+func (s *Storage) SetOrderWaybill(ctx context.Context, id, carrier, number string) (bool, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		UPDATE orders
+		SET delivery_carrier = $2, tracking_number = $3, updated_at = NOW()
+		WHERE id = $1 AND tracking_number = ''
+		`,
+		id,
+		carrier,
+		number,
+	)
+	if err != nil {
+		return false, errx.NewInternal().WithDescriptionAndCause("failed to set order waybill", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (s *Storage) SetOrderPaymentStatus(ctx context.Context, id string, status models.PaymentStatus) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE orders SET payment_status = $2, updated_at = NOW() WHERE id = $1",
+		id,
+		status,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to set order payment status", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("order with id '%s' not found", id))
+	}
+
+	return nil
+}
+
+// SetOrderAmounts stores the totals of an order whose lines changed.
+func (s *Storage) SetOrderAmounts(ctx context.Context, id string, subtotal, discount, amountToPay decimal.Decimal) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		UPDATE orders
+		SET subtotal = $2, discount_amount = $3, amount_to_pay = $4, updated_at = NOW()
+		WHERE id = $1
+		`,
+		id,
+		subtotal,
+		discount,
+		amountToPay,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to set order amounts", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("order with id '%s' not found", id))
+	}
+
+	return nil
+}
+
+// AddOrderRefundedAmount adds money given back to the customer to the order's
+// refunded total.
+func (s *Storage) AddOrderRefundedAmount(ctx context.Context, id string, amount decimal.Decimal) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE orders SET refunded_amount = refunded_amount + $2, updated_at = NOW() WHERE id = $1",
+		id,
+		amount,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to add order refunded amount", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription(fmt.Sprintf("order with id '%s' not found", id))
+	}
+
+	return nil
+}
+
+// LockOrderStatus returns the order's status and holds its row until the
+// surrounding transaction ends, so concurrent status changes are serialised.
+func (s *Storage) LockOrderStatus(ctx context.Context, id string) (models.OrderStatus, error) {
+	var status models.OrderStatus
+
+	err := s.GetQuerier().QueryRow(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", errx.NewNotFound().WithDescription(fmt.Sprintf("order with id '%s' not found", id))
+		}
+
+		return "", errx.NewInternal().WithDescriptionAndCause("failed to lock order", err)
+	}
+
+	return status, nil
+}
+
 func (s *Storage) DeleteOrder(ctx context.Context, id string) error {
 	result, err := s.GetQuerier().Exec(ctx, "DELETE FROM orders WHERE id = $1", id)
 	if err != nil {
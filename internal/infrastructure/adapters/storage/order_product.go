@@ -18,24 +18,36 @@ func (s *Storage) CreateOrderProduct(ctx context.Context, orderProduct models.Or
 		INSERT INTO order_products (
 			order_id,
 			product_id,
+			variant_id,
+			brand,
+			name,
+			sku,
 			quantity,
-			volume
+			volume,
+			unit_price,
+			line_total
 		)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 	_, err := s.GetQuerier().Exec(ctx, query,
 		orderProduct.OrderID,
 		orderProduct.ProductID,
+		orderProduct.VariantID,
+		orderProduct.Brand,
+		orderProduct.Name,
+		orderProduct.SKU,
 		orderProduct.Quantity,
 		orderProduct.Volume,
+		orderProduct.UnitPrice,
+		orderProduct.LineTotal,
 	)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			if pgErr.Code == uniqueViolationCode {
 				if strings.Contains(pgErr.ConstraintName, "order_products_pkey") {
 					return errx.NewAlreadyExists().WithDescriptionAndCause(
-						fmt.Sprintf("order product with order_id '%s' and product_id '%s' already exists",
-							orderProduct.OrderID, orderProduct.ProductID),
+						fmt.Sprintf("order product with order_id '%s' and variant_id '%s' already exists",
+							orderProduct.OrderID, orderProduct.VariantID),
 						err,
 					)
 				}
@@ -102,12 +114,35 @@ func (s *Storage) ListOrderProducts(ctx context.Context, filter dto.ListOrderPro
 	return orderProducts, totalCount, nil
 }
 
+// ListOrderLines returns every line of the given orders, unpaginated.
+func (s *Storage) ListOrderLines(ctx context.Context, orderIDs []string) ([]models.OrderProduct, error) {
+	query, _ := s.buildSearchOrderProductQuery(dto.ListOrderProductFilter{OrderIDs: orderIDs})
+
+	rows, err := s.squirrelHelper.Query(ctx, s.GetQuerier(), query.OrderBy("order_id", "brand", "name", "volume"))
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause(
+			"failed to query order products",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	return s.scanOrderProducts(rows)
+}
+
 func (s *Storage) buildSearchOrderProductQuery(filter dto.ListOrderProductFilter) (squirrel.SelectBuilder, squirrel.SelectBuilder) {
 	baseQuery := s.Builder().Select(
 		"order_id",
 		"product_id",
+		"variant_id",
+		"brand",
+		"name",
+		"sku",
 		"quantity",
 		"volume",
+		"unit_price",
+		"line_total",
+		"returned_quantity",
 	).From("order_products")
 
 	countQuery := s.Builder().Select("COUNT(*)").From("order_products")
@@ -134,8 +169,15 @@ func (s *Storage) scanOrderProducts(rows pgx.Rows) ([]models.OrderProduct, error
 		err := rows.Scan(
 			&orderProduct.OrderID,
 			&orderProduct.ProductID,
+			&orderProduct.VariantID,
+			&orderProduct.Brand,
+			&orderProduct.Name,
+			&orderProduct.SKU,
 			&orderProduct.Quantity,
 			&orderProduct.Volume,
+			&orderProduct.UnitPrice,
+			&orderProduct.LineTotal,
+			&orderProduct.ReturnedQuantity,
 		)
 
 		if err != nil {
@@ -158,6 +200,28 @@ func (s *Storage) scanOrderProducts(rows pgx.Rows) ([]models.OrderProduct, error
 	return orderProducts, nil
 }
 
+// ReturnOrderProduct counts quantity more items of the order line as returned.
+// It reports false when the line does not have that many items left to return.
+func (s *Storage) ReturnOrderProduct(ctx context.Context, orderID, variantID string, volume, quantity uint) (bool, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		UPDATE order_products
+		SET returned_quantity = returned_quantity + $4
+		WHERE order_id = $1 AND variant_id = $2 AND volume = $3 AND returned_quantity + $4 <= quantity
+		`,
+		orderID,
+		variantID,
+		volume,
+		quantity,
+	)
+	if err != nil {
+		return false, errx.NewInternal().WithDescriptionAndCause("failed to return order product", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
 func (s *Storage) DeleteOrderProduct(ctx context.Context, orderID, productID string) error {
 	result, err := s.GetQuerier().Exec(
 		ctx,
@@ -180,3 +244,16 @@ func (s *Storage) DeleteOrderProduct(ctx context.Context, orderID, productID str
 
 	return nil
 }
+
+// DeleteOrderProducts removes every line of the order.
+func (s *Storage) DeleteOrderProducts(ctx context.Context, orderID string) error {
+	_, err := s.GetQuerier().Exec(ctx, "DELETE FROM order_products WHERE order_id = $1", orderID)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause(
+			"order products deletion failed",
+			err,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/nordew/go-errx"
+)
+
+func (s *Storage) CreateOrderReturn(ctx context.Context, ret models.OrderReturn) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO order_returns (id, order_id, admin_id, restock, refund_amount, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+		ret.ID,
+		ret.OrderID,
+		ret.AdminID,
+		ret.Restock,
+		ret.RefundAmount,
+		ret.Comment,
+		ret.CreatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "order return", ret.ID)
+	}
+
+	for _, item := range ret.Items {
+		_, err := s.GetQuerier().Exec(
+			ctx,
+			`
+			INSERT INTO order_return_items (return_id, variant_id, volume, quantity)
+			VALUES ($1, $2, $3, $4)
+			`,
+			ret.ID,
+			item.VariantID,
+			item.Volume,
+			item.Quantity,
+		)
+		if err != nil {
+			return handleSQLError(err, "order return item", item.VariantID)
+		}
+	}
+
+	return nil
+}
+
+// ListOrderReturns returns the order's returns with their items, oldest
+// first.
+func (s *Storage) ListOrderReturns(ctx context.Context, orderID string) ([]models.OrderReturn, error) {
+	rows, err := s.GetQuerier().Query(
+		ctx,
+		`
+		SELECT id, order_id, admin_id, restock, refund_amount, comment, created_at
+		FROM order_returns
+		WHERE order_id = $1
+		ORDER BY created_at
+		`,
+		orderID,
+	)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to query order returns", err)
+	}
+	defer rows.Close()
+
+	returns := make([]models.OrderReturn, 0)
+	indexByID := make(map[string]int)
+	for rows.Next() {
+		var ret models.OrderReturn
+		if err := rows.Scan(
+			&ret.ID,
+			&ret.OrderID,
+			&ret.AdminID,
+			&ret.Restock,
+			&ret.RefundAmount,
+			&ret.Comment,
+			&ret.CreatedAt,
+		); err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan order return", err)
+		}
+
+		ret.Items = make([]models.OrderReturnItem, 0)
+		indexByID[ret.ID] = len(returns)
+		returns = append(returns, ret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("rows error", err)
+	}
+
+	if len(returns) == 0 {
+		return returns, nil
+	}
+
+	itemRows, err := s.GetQuerier().Query(
+		ctx,
+		`
+		SELECT ri.return_id, ri.variant_id, ri.volume, ri.quantity
+		FROM order_return_items ri
+		JOIN order_returns r ON r.id = ri.return_id
+		WHERE r.order_id = $1
+		`,
+		orderID,
+	)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to query order return items", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			returnID string
+			item     models.OrderReturnItem
+		)
+		if err := itemRows.Scan(&returnID, &item.VariantID, &item.Volume, &item.Quantity); err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan order return item", err)
+		}
+
+		if i, ok := indexByID[returnID]; ok {
+			returns[i].Items = append(returns[i].Items, item)
+		}
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("rows error", err)
+	}
+
+	return returns, nil
+}
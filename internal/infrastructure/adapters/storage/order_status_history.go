@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/nordew/go-errx"
+)
+
+func (s *Storage) CreateOrderStatusHistory(ctx context.Context, entry models.OrderStatusHistory) error {
+	query := `
+		INSERT INTO order_status_history (
+			id,
+			order_id,
+			from_status,
+			to_status,
+			actor_type,
+			actor_id,
+			comment,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.GetQuerier().Exec(ctx, query,
+		entry.ID,
+		entry.OrderID,
+		entry.FromStatus,
+		entry.ToStatus,
+		entry.ActorType,
+		entry.ActorID,
+		entry.Comment,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "order status history", entry.ID)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListOrderStatusHistory(ctx context.Context, orderID string) ([]models.OrderStatusHistory, error) {
+	rows, err := s.GetQuerier().Query(
+		ctx,
+		`
+		SELECT id, order_id, from_status, to_status, actor_type, actor_id, comment, created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY created_at
+		`,
+		orderID,
+	)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to query order status history", err)
+	}
+	defer rows.Close()
+
+	history := make([]models.OrderStatusHistory, 0)
+	for rows.Next() {
+		var entry models.OrderStatusHistory
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OrderID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.ActorType,
+			&entry.ActorID,
+			&entry.Comment,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan order status history", err)
+		}
+
+		history = append(history, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("rows error", err)
+	}
+
+	return history, nil
+}
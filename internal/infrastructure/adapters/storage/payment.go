@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Storage) CreatePayment(ctx context.Context, payment models.Payment) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO payments (id, order_id, provider, invoice_id, checkout_url, amount, refunded_amount, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+		payment.ID,
+		payment.OrderID,
+		payment.Provider,
+		payment.InvoiceID,
+		payment.CheckoutURL,
+		payment.Amount,
+		payment.RefundedAmount,
+		payment.Status,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "payment", payment.InvoiceID)
+	}
+
+	return nil
+}
+
+// ListPayments returns the order's payments, newest first.
+func (s *Storage) ListPayments(ctx context.Context, orderID string) ([]models.Payment, error) {
+	rows, err := s.GetQuerier().Query(
+		ctx,
+		`
+		SELECT id, order_id, provider, invoice_id, checkout_url, amount, refunded_amount, status, created_at, updated_at
+		FROM payments
+		WHERE order_id = $1
+		ORDER BY created_at DESC
+		`,
+		orderID,
+	)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to query payments", err)
+	}
+	defer rows.Close()
+
+	payments := make([]models.Payment, 0)
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan payment", err)
+		}
+
+		payments = append(payments, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("rows error", err)
+	}
+
+	return payments, nil
+}
+
+// LockPaymentByInvoiceID reads a payment and holds its row until the
+// surrounding transaction ends, so repeated webhooks are applied in turn.
+func (s *Storage) LockPaymentByInvoiceID(ctx context.Context, provider, invoiceID string) (models.Payment, error) {
+	row := s.GetQuerier().QueryRow(
+		ctx,
+		`
+		SELECT id, order_id, provider, invoice_id, checkout_url, amount, refunded_amount, status, created_at, updated_at
+		FROM payments
+		WHERE provider = $1 AND invoice_id = $2
+		FOR UPDATE
+		`,
+		provider,
+		invoiceID,
+	)
+
+	p, err := scanPayment(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Payment{}, errx.NewNotFound().WithDescription("payment not found")
+		}
+
+		return models.Payment{}, errx.NewInternal().WithDescriptionAndCause("failed to lock payment", err)
+	}
+
+	return p, nil
+}
+
+func (s *Storage) UpdatePayment(
+	ctx context.Context,
+	id string,
+	status models.PaymentStatus,
+	refundedAmount decimal.Decimal,
+) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE payments SET status = $2, refunded_amount = $3, updated_at = NOW() WHERE id = $1",
+		id,
+		status,
+		refundedAmount,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("payment update failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription("payment not found")
+	}
+
+	return nil
+}
+
+func scanPayment(row pgx.Row) (models.Payment, error) {
+	var p models.Payment
+
+	err := row.Scan(
+		&p.ID,
+		&p.OrderID,
+		&p.Provider,
+		&p.InvoiceID,
+		&p.CheckoutURL,
+		&p.Amount,
+		&p.RefundedAmount,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	return p, err
+}
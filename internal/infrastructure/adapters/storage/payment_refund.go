@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Storage) CreatePaymentRefund(ctx context.Context, refund models.PaymentRefund) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO payment_refunds (id, payment_id, order_id, return_id, amount, status, actor_type, actor_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, '')::UUID, $5, $6, $7, $8, $9, $10)
+		`,
+		refund.ID,
+		refund.PaymentID,
+		refund.OrderID,
+		refund.ReturnID,
+		refund.Amount,
+		refund.Status,
+		refund.ActorType,
+		refund.ActorID,
+		refund.CreatedAt,
+		refund.UpdatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "payment refund", refund.ID)
+	}
+
+	return nil
+}
+
+// PendingRefundAmount sums the order's refunds the provider has not answered
+// yet. Callers hold the order lock so the sum cannot change underneath them.
+func (s *Storage) PendingRefundAmount(ctx context.Context, orderID string) (decimal.Decimal, error) {
+	var amount decimal.Decimal
+
+	err := s.GetQuerier().QueryRow(
+		ctx,
+		"SELECT COALESCE(SUM(amount), 0) FROM payment_refunds WHERE order_id = $1 AND status = $2",
+		orderID,
+		models.PaymentRefundStatusPending,
+	).Scan(&amount)
+	if err != nil {
+		return decimal.Zero, errx.NewInternal().WithDescriptionAndCause("failed to sum pending refunds", err)
+	}
+
+	return amount, nil
+}
+
+// SettlePaymentRefund moves a pending refund to status. It reports false when
+// the refund was no longer pending, e.g. because a webhook settled it first.
+func (s *Storage) SettlePaymentRefund(ctx context.Context, id string, status models.PaymentRefundStatus) (bool, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE payment_refunds SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3",
+		id,
+		status,
+		models.PaymentRefundStatusPending,
+	)
+	if err != nil {
+		return false, errx.NewInternal().WithDescriptionAndCause("payment refund update failed", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// SettlePendingPaymentRefunds moves every pending refund of a payment to
+// status.
+func (s *Storage) SettlePendingPaymentRefunds(
+	ctx context.Context,
+	paymentID string,
+	status models.PaymentRefundStatus,
+) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE payment_refunds SET status = $2, updated_at = NOW() WHERE payment_id = $1 AND status = $3",
+		paymentID,
+		status,
+		models.PaymentRefundStatusPending,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("payment refund update failed", err)
+	}
+
+	return nil
+}
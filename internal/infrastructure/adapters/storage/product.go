@@ -19,8 +19,8 @@ func (s *Storage) CreateProduct(ctx context.Context, product models.Product) err
 	_, err := s.GetQuerier().Exec(
 		ctx,
 		`
-		INSERT INTO products (id, category_id, brand, name, image_url, description, composition, characteristics, price, stock_amount, is_best_seller)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO products (id, category_id, brand, name, image_url, description, composition, characteristics, price, price_per_ml, bottle_fee, is_best_seller)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		`,
 		product.ID,
 		product.CategoryID,
@@ -31,7 +31,8 @@ func (s *Storage) CreateProduct(ctx context.Context, product models.Product) err
 		product.Composition,
 		product.Characteristics,
 		product.Price,
-		product.StockAmount,
+		product.PricePerMl,
+		product.BottleFee,
 		product.IsBestSeller,
 	)
 	if err != nil {
@@ -89,9 +90,46 @@ func (s *Storage) ListProducts(ctx context.Context, filter dto.ListProductFilter
 		return nil, 0, errx.NewInternal().WithDescriptionAndCause("failed to scan products", err)
 	}
 
+	if err := s.attachProductVariants(ctx, products, filter.ShowInvisible); err != nil {
+		return nil, 0, err
+	}
+
 	return products, totalCount, nil
 }
 
+func (s *Storage) attachProductVariants(ctx context.Context, products []models.Product, showInvisible bool) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]string, 0, len(products))
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+
+	variants, err := s.ListProductVariants(ctx, dto.ListProductVariantFilter{
+		ProductIDs:    productIDs,
+		ShowInvisible: showInvisible,
+	})
+	if err != nil {
+		return err
+	}
+
+	variantsByProduct := make(map[string][]models.ProductVariant, len(products))
+	for _, v := range variants {
+		variantsByProduct[v.ProductID] = append(variantsByProduct[v.ProductID], v)
+	}
+
+	for i := range products {
+		products[i].Variants = variantsByProduct[products[i].ID]
+		if products[i].Variants == nil {
+			products[i].Variants = []models.ProductVariant{}
+		}
+	}
+
+	return nil
+}
+
 func (s *Storage) buildProductSearchQuery(filter dto.ListProductFilter) (squirrel.SelectBuilder, squirrel.SelectBuilder) {
 	baseQuery := s.Builder().Select(
 		"p.id",
@@ -104,7 +142,8 @@ func (s *Storage) buildProductSearchQuery(filter dto.ListProductFilter) (squirre
 		"p.composition",
 		"p.characteristics",
 		"p.price",
-		"p.stock_amount",
+		"p.price_per_ml",
+		"p.bottle_fee",
 		"p.is_best_seller",
 		"p.visible",
 		"p.created_at",
@@ -150,8 +189,12 @@ func (s *Storage) buildProductSearchQuery(filter dto.ListProductFilter) (squirre
 		countQuery = countQuery.Where(squirrel.Eq{"p.visible": true})
 	}
 	if filter.StockAmount > 0 {
-		baseQuery = baseQuery.Where(squirrel.GtOrEq{"p.stock_amount": filter.StockAmount})
-		countQuery = countQuery.Where(squirrel.GtOrEq{"p.stock_amount": filter.StockAmount})
+		inStock := squirrel.Expr(
+			"EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.visible AND pv.stock_amount >= ?)",
+			filter.StockAmount,
+		)
+		baseQuery = baseQuery.Where(inStock)
+		countQuery = countQuery.Where(inStock)
 	}
 	if filter.OnlyBestSellers {
 		baseQuery = baseQuery.Where(squirrel.Eq{"p.is_best_seller": true})
@@ -181,7 +224,8 @@ func (s *Storage) scanProducts(rows pgx.Rows) ([]models.Product, error) {
 			&p.Composition,
 			&p.Characteristics,
 			&p.Price,
-			&p.StockAmount,
+			&p.PricePerMl,
+			&p.BottleFee,
 			&p.IsBestSeller,
 			&p.Visible,
 			&p.CreatedAt,
@@ -273,8 +317,11 @@ func (s *Storage) UpdateProduct(ctx context.Context, input dto.UpdateProductRequ
 
 		query = query.Set("price", reqPriceDecimal)
 	}
-	{
-		query = query.Set("stock_amount", input.StockAmount)
+	if input.PricePerMl > 0 {
+		query = query.Set("price_per_ml", decimal.NewFromFloat(input.PricePerMl))
+	}
+	if input.BottleFee > 0 {
+		query = query.Set("bottle_fee", decimal.NewFromFloat(input.BottleFee))
 	}
 	if input.MakeVisible {
 		query = query.Set("visible", true)
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Storage) CreateProductVariant(ctx context.Context, variant models.ProductVariant) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		INSERT INTO product_variants (id, product_id, sku, volume, price, stock_amount, visible, is_default, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+		variant.ID,
+		variant.ProductID,
+		variant.SKU,
+		variant.Volume,
+		variant.Price,
+		variant.StockAmount,
+		variant.Visible,
+		variant.IsDefault,
+		variant.CreatedAt,
+		variant.UpdatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return errx.NewAlreadyExists().WithDescriptionAndCause(
+				fmt.Sprintf("product variant with sku '%s' already exists", variant.SKU),
+				err,
+			)
+		}
+
+		return errx.NewInternal().WithDescriptionAndCause("product variant creation failed", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListProductVariants(ctx context.Context, filter dto.ListProductVariantFilter) ([]models.ProductVariant, error) {
+	query := s.Builder().Select(
+		"id",
+		"product_id",
+		"sku",
+		"volume",
+		"price",
+		"stock_amount",
+		"visible",
+		"is_default",
+		"created_at",
+		"updated_at",
+	).
+		From("product_variants").
+		OrderBy("is_default DESC", "volume ASC")
+
+	if len(filter.IDs) > 0 {
+		query = query.Where(squirrel.Eq{"id": filter.IDs})
+	}
+	if len(filter.ProductIDs) > 0 {
+		query = query.Where(squirrel.Eq{"product_id": filter.ProductIDs})
+	}
+	if !filter.ShowInvisible {
+		query = query.Where(squirrel.Eq{"visible": true})
+	}
+
+	rows, err := s.squirrelHelper.Query(ctx, s.GetQuerier(), query)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to query product variants", err)
+	}
+	defer rows.Close()
+
+	variants, err := s.scanProductVariants(rows)
+	if err != nil {
+		return nil, errx.NewInternal().WithDescriptionAndCause("failed to scan product variants", err)
+	}
+
+	return variants, nil
+}
+
+func (s *Storage) scanProductVariants(rows pgx.Rows) ([]models.ProductVariant, error) {
+	variants := make([]models.ProductVariant, 0)
+
+	for rows.Next() {
+		var v models.ProductVariant
+
+		err := rows.Scan(
+			&v.ID,
+			&v.ProductID,
+			&v.SKU,
+			&v.Volume,
+			&v.Price,
+			&v.StockAmount,
+			&v.Visible,
+			&v.IsDefault,
+			&v.CreatedAt,
+			&v.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product variant: %w", err)
+		}
+
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return variants, nil
+}
+
+func (s *Storage) UpdateProductVariant(ctx context.Context, input dto.UpdateProductVariantRequest) error {
+	query := s.Builder().Update("product_variants").
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": input.ID})
+
+	if input.ProductID != "" {
+		query = query.Where(squirrel.Eq{"product_id": input.ProductID})
+	}
+	if input.SKU != "" {
+		query = query.Set("sku", input.SKU)
+	}
+	if input.Price > 0 {
+		query = query.Set("price", decimal.NewFromFloat(input.Price))
+	}
+	if input.StockAmount != nil {
+		query = query.Set("stock_amount", *input.StockAmount)
+	}
+	if input.MakeVisible {
+		query = query.Set("visible", true)
+	}
+	if input.Hide {
+		query = query.Set("visible", false)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to build product variant update", err)
+	}
+
+	result, err := s.GetQuerier().Exec(ctx, sql, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return errx.NewAlreadyExists().WithDescriptionAndCause(
+				fmt.Sprintf("product variant with sku '%s' already exists", input.SKU),
+				err,
+			)
+		}
+
+		return errx.NewInternal().WithDescriptionAndCause("product variant update failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription("product variant not found")
+	}
+
+	return nil
+}
+
+// LockProductVariant reads a variant and holds its row until the surrounding
+// transaction ends, so a stock adjustment can compute its delta safely.
+func (s *Storage) LockProductVariant(ctx context.Context, productID, id string) (models.ProductVariant, error) {
+	var v models.ProductVariant
+
+	err := s.GetQuerier().QueryRow(
+		ctx,
+		`
+		SELECT id, product_id, sku, volume, price, stock_amount, visible, is_default, created_at, updated_at
+		FROM product_variants
+		WHERE id = $1 AND product_id = $2
+		FOR UPDATE
+		`,
+		id,
+		productID,
+	).Scan(
+		&v.ID,
+		&v.ProductID,
+		&v.SKU,
+		&v.Volume,
+		&v.Price,
+		&v.StockAmount,
+		&v.Visible,
+		&v.IsDefault,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.ProductVariant{}, errx.NewNotFound().WithDescription("product variant not found")
+		}
+
+		return models.ProductVariant{}, errx.NewInternal().WithDescriptionAndCause("failed to lock product variant", err)
+	}
+
+	return v, nil
+}
+
+// ReserveProductVariantStock takes amount off the variant's stock in a single
+// conditional update, so concurrent orders cannot oversell. It reports false
+// when there is not enough stock left.
+func (s *Storage) ReserveProductVariantStock(ctx context.Context, id string, amount uint) (bool, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		`
+		UPDATE product_variants
+		SET stock_amount = stock_amount - $2, updated_at = NOW()
+		WHERE id = $1 AND stock_amount >= $2
+		`,
+		id,
+		amount,
+	)
+	if err != nil {
+		return false, errx.NewInternal().WithDescriptionAndCause("failed to reserve product variant stock", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (s *Storage) RestockProductVariant(ctx context.Context, id string, amount uint) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE product_variants SET stock_amount = stock_amount + $2, updated_at = NOW() WHERE id = $1",
+		id,
+		amount,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause("failed to restock product variant", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteProductVariant(ctx context.Context, productID, id string) error {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"DELETE FROM product_variants WHERE id = $1 AND product_id = $2",
+		id,
+		productID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return errx.NewConflict().WithDescriptionAndCause(
+				"product variant has orders and cannot be deleted, hide it instead",
+				err,
+			)
+		}
+
+		return errx.NewInternal().WithDescriptionAndCause("product variant deletion failed", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errx.NewNotFound().WithDescription("product variant not found")
+	}
+
+	return nil
+}
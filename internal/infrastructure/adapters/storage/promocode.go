@@ -14,26 +14,49 @@ import (
 	"github.com/nordew/go-errx"
 )
 
-func (s *Storage) CreatePromocode(ctx context.Context, promocode models.Promocode) error {
-	query := `
-		INSERT INTO promocodes (
-			id,
-			code,
-			discount,
-			expires_at,
-			created_at,
-			updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	_, err := s.GetQuerier().Exec(ctx, query,
+const insertPromocodeQuery = `
+	INSERT INTO promocodes (
+		id,
+		code,
+		campaign,
+		discount_type,
+		discount,
+		category_ids,
+		brands,
+		product_ids,
+		starts_at,
+		expires_at,
+		max_redemptions,
+		per_customer_limit,
+		min_order_amount,
+		created_at,
+		updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+`
+
+func promocodeInsertArgs(promocode models.Promocode) []any {
+	return []any{
 		promocode.ID,
 		promocode.Code,
+		promocode.Campaign,
+		promocode.DiscountType,
 		promocode.Discount,
+		promocode.Scope.CategoryIDs,
+		promocode.Scope.Brands,
+		promocode.Scope.ProductIDs,
+		promocode.StartsAt,
 		promocode.ExpiresAt,
+		promocode.MaxRedemptions,
+		promocode.PerCustomerLimit,
+		promocode.MinOrderAmount,
 		promocode.CreatedAt,
 		promocode.UpdatedAt,
-	)
+	}
+}
+
+func (s *Storage) CreatePromocode(ctx context.Context, promocode models.Promocode) error {
+	_, err := s.GetQuerier().Exec(ctx, insertPromocodeQuery, promocodeInsertArgs(promocode)...)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			if pgErr.Code == uniqueViolationCode {
@@ -59,6 +82,24 @@ func (s *Storage) CreatePromocode(ctx context.Context, promocode models.Promocod
 	return nil
 }
 
+// TryCreatePromocode inserts the promocode unless its code is already taken and
+// reports whether a row was written.
+func (s *Storage) TryCreatePromocode(ctx context.Context, promocode models.Promocode) (bool, error) {
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		insertPromocodeQuery+" ON CONFLICT (code) DO NOTHING",
+		promocodeInsertArgs(promocode)...,
+	)
+	if err != nil {
+		return false, errx.NewInternal().WithDescriptionAndCause(
+			"failed to create promocode",
+			err,
+		)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
 func (s *Storage) ListPromocodes(ctx context.Context, filter dto.ListPromocodeFilter) ([]models.Promocode, int64, error) {
 	baseQuery, countQuery := s.buildSearchPromocodeQuery(filter)
 	limit := uint(10)
@@ -105,17 +146,67 @@ func (s *Storage) ListPromocodes(ctx context.Context, filter dto.ListPromocodeFi
 	return promocodes, totalCount, nil
 }
 
-func (s *Storage) buildSearchPromocodeQuery(filter dto.ListPromocodeFilter) (squirrel.SelectBuilder, squirrel.SelectBuilder) {
-	baseQuery := s.Builder().Select(
+// GetPromocodeByCode finds the promocode whose code equals code, ignoring
+// case. Deleted promocodes are found too, so orders placed with them can
+// still be priced.
+func (s *Storage) GetPromocodeByCode(ctx context.Context, code string) (models.Promocode, error) {
+	query := s.selectPromocodes().
+		Where("lower(code) = lower(?)", code).
+		OrderBy("created_at DESC").
+		Limit(1)
+
+	rows, err := s.squirrelHelper.Query(ctx, s.GetQuerier(), query)
+	if err != nil {
+		return models.Promocode{}, errx.NewInternal().WithDescriptionAndCause(
+			"failed to query promocode",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	promocodes, err := s.scanPromocodes(rows)
+	if err != nil {
+		return models.Promocode{}, err
+	}
+	if len(promocodes) == 0 {
+		return models.Promocode{}, errx.NewNotFound().WithDescription(
+			fmt.Sprintf("promocode '%s' not found", code))
+	}
+
+	return promocodes[0], nil
+}
+
+// selectPromocodes selects promocodes with their redemption counts, deleted
+// ones included.
+func (s *Storage) selectPromocodes() squirrel.SelectBuilder {
+	return s.Builder().Select(
 		"id",
 		"code",
+		"campaign",
+		"discount_type",
 		"discount",
+		"category_ids",
+		"brands",
+		"product_ids",
+		"starts_at",
 		"expires_at",
+		"max_redemptions",
+		"per_customer_limit",
+		"min_order_amount",
+		`(
+			SELECT COUNT(*)
+			FROM promocode_redemptions r
+			WHERE r.promocode_id = promocodes.id AND r.released_at IS NULL
+		) AS used_count`,
+		"deleted_at",
 		"created_at",
 		"updated_at",
 	).From("promocodes")
+}
 
-	countQuery := s.Builder().Select("COUNT(*)").From("promocodes")
+func (s *Storage) buildSearchPromocodeQuery(filter dto.ListPromocodeFilter) (squirrel.SelectBuilder, squirrel.SelectBuilder) {
+	baseQuery := s.selectPromocodes().Where(squirrel.Eq{"deleted_at": nil})
+	countQuery := s.Builder().Select("COUNT(*)").From("promocodes").Where(squirrel.Eq{"deleted_at": nil})
 
 	if filter.ID != "" {
 		baseQuery = baseQuery.Where(squirrel.Eq{"id": filter.ID})
@@ -125,6 +216,14 @@ func (s *Storage) buildSearchPromocodeQuery(filter dto.ListPromocodeFilter) (squ
 		baseQuery = baseQuery.Where(squirrel.ILike{"code": "%" + filter.Code + "%"})
 		countQuery = countQuery.Where(squirrel.ILike{"code": "%" + filter.Code + "%"})
 	}
+	if filter.Campaign != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"campaign": filter.Campaign})
+		countQuery = countQuery.Where(squirrel.Eq{"campaign": filter.Campaign})
+	}
+	if filter.DiscountType != "" {
+		baseQuery = baseQuery.Where(squirrel.Eq{"discount_type": filter.DiscountType})
+		countQuery = countQuery.Where(squirrel.Eq{"discount_type": filter.DiscountType})
+	}
 	if filter.DiscountFrom > 0 {
 		baseQuery = baseQuery.Where(squirrel.GtOrEq{"discount": filter.DiscountFrom})
 		countQuery = countQuery.Where(squirrel.GtOrEq{"discount": filter.DiscountFrom})
@@ -135,8 +234,8 @@ func (s *Storage) buildSearchPromocodeQuery(filter dto.ListPromocodeFilter) (squ
 	}
 	if filter.Active {
 		now := time.Now()
-		baseQuery = baseQuery.Where(squirrel.GtOrEq{"expires_at": now})
-		countQuery = countQuery.Where(squirrel.GtOrEq{"expires_at": now})
+		baseQuery = baseQuery.Where(squirrel.GtOrEq{"expires_at": now}).Where(squirrel.LtOrEq{"starts_at": now})
+		countQuery = countQuery.Where(squirrel.GtOrEq{"expires_at": now}).Where(squirrel.LtOrEq{"starts_at": now})
 	}
 	if filter.Expired {
 		now := time.Now()
@@ -151,13 +250,27 @@ func (s *Storage) scanPromocodes(rows pgx.Rows) ([]models.Promocode, error) {
 	var promocodes []models.Promocode
 
 	for rows.Next() {
-		var promocode models.Promocode
+		var (
+			promocode models.Promocode
+			usedCount uint
+		)
 
 		err := rows.Scan(
 			&promocode.ID,
 			&promocode.Code,
+			&promocode.Campaign,
+			&promocode.DiscountType,
 			&promocode.Discount,
+			&promocode.Scope.CategoryIDs,
+			&promocode.Scope.Brands,
+			&promocode.Scope.ProductIDs,
+			&promocode.StartsAt,
 			&promocode.ExpiresAt,
+			&promocode.MaxRedemptions,
+			&promocode.PerCustomerLimit,
+			&promocode.MinOrderAmount,
+			&usedCount,
+			&promocode.DeletedAt,
 			&promocode.CreatedAt,
 			&promocode.UpdatedAt,
 		)
@@ -169,6 +282,8 @@ func (s *Storage) scanPromocodes(rows pgx.Rows) ([]models.Promocode, error) {
 			)
 		}
 
+		promocode.SetUsedCount(usedCount)
+
 		promocodes = append(promocodes, promocode)
 	}
 
@@ -182,8 +297,32 @@ func (s *Storage) scanPromocodes(rows pgx.Rows) ([]models.Promocode, error) {
 	return promocodes, nil
 }
 
+func (s *Storage) LockPromocode(ctx context.Context, id string) error {
+	var lockedID string
+	err := s.GetQuerier().QueryRow(
+		ctx,
+		"SELECT id FROM promocodes WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+		id,
+	).Scan(&lockedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errx.NewNotFound().WithDescription(fmt.Sprintf("promocode with id '%s' not found", id))
+		}
+
+		return errx.NewInternal().WithDescriptionAndCause("failed to lock promocode", err)
+	}
+
+	return nil
+}
+
+// DeletePromocode hides the promocode from listings and new orders. The row
+// stays, since the redemption ledger and orders placed with it refer to it.
 func (s *Storage) DeletePromocode(ctx context.Context, id string) error {
-	result, err := s.GetQuerier().Exec(ctx, "DELETE FROM promocodes WHERE id = $1", id)
+	result, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE promocodes SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL",
+		id,
+	)
 	if err != nil {
 		return errx.NewInternal().WithDescriptionAndCause(
 			"promocode deletion failed",
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Storage) CreatePromocodeRedemption(ctx context.Context, redemption models.PromocodeRedemption) error {
+	query := `
+		INSERT INTO promocode_redemptions (
+			id,
+			promocode_id,
+			order_id,
+			phone_number,
+			discount_amount,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := s.GetQuerier().Exec(ctx, query,
+		redemption.ID,
+		redemption.PromocodeID,
+		redemption.OrderID,
+		redemption.PhoneNumber,
+		redemption.DiscountAmount,
+		redemption.CreatedAt,
+	)
+	if err != nil {
+		return handleSQLError(err, "promocode redemption", redemption.ID)
+	}
+
+	return nil
+}
+
+func (s *Storage) CountPromocodeRedemptions(ctx context.Context, filter dto.CountPromocodeRedemptionFilter) (int64, error) {
+	query := s.Builder().Select("COUNT(*)").
+		From("promocode_redemptions").
+		Where(squirrel.Eq{"released_at": nil})
+
+	if filter.PromocodeID != "" {
+		query = query.Where(squirrel.Eq{"promocode_id": filter.PromocodeID})
+	}
+	if filter.PhoneNumber != "" {
+		query = query.Where(squirrel.Eq{"phone_number": models.NormalizePhoneNumber(filter.PhoneNumber)})
+	}
+
+	var count int64
+	if err := s.squirrelHelper.QueryRow(ctx, s.GetQuerier(), query).Scan(&count); err != nil {
+		return 0, errx.NewInternal().WithDescriptionAndCause(
+			"failed to count promocode redemptions",
+			err,
+		)
+	}
+
+	return count, nil
+}
+
+func (s *Storage) ReleasePromocodeRedemptions(ctx context.Context, orderID string) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE promocode_redemptions SET released_at = NOW() WHERE order_id = $1 AND released_at IS NULL",
+		orderID,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause(
+			"failed to release promocode redemption",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// UpdatePromocodeRedemptionDiscount changes the discount recorded for the
+// order's active redemption after the order was edited.
+func (s *Storage) UpdatePromocodeRedemptionDiscount(ctx context.Context, orderID string, discount decimal.Decimal) error {
+	_, err := s.GetQuerier().Exec(
+		ctx,
+		"UPDATE promocode_redemptions SET discount_amount = $2 WHERE order_id = $1 AND released_at IS NULL",
+		orderID,
+		discount,
+	)
+	if err != nil {
+		return errx.NewInternal().WithDescriptionAndCause(
+			"failed to update promocode redemption discount",
+			err,
+		)
+	}
+
+	return nil
+}
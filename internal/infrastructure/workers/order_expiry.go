@@ -0,0 +1,100 @@
+package workers
+
+import (
+	"aroma-hub/internal/application/dto"
+	"aroma-hub/internal/models"
+	"context"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const every10Min = "0 */10 * * * *"
+
+type OrderExpiryService interface {
+	ExpirePendingOrders(ctx context.Context, input dto.ExpirePendingOrdersRequest) (int, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// OrderExpiryWorker cancels pending orders that stayed unpaid for longer than
+// their payment method allows, releasing the stock they reserved. It also
+// purges idempotency keys clients can no longer retry with.
+type OrderExpiryWorker struct {
+	cron    *cron.Cron
+	service OrderExpiryService
+	ttls    map[models.PaymentMethod]time.Duration
+	logger  *log.Logger
+}
+
+// NewOrderExpiryWorker takes the time to pay per payment method. Methods
+// missing from ttls or with a zero TTL never expire.
+func NewOrderExpiryWorker(
+	service OrderExpiryService,
+	ttls map[models.PaymentMethod]time.Duration,
+	logger *log.Logger,
+) *OrderExpiryWorker {
+	cronOptions := cron.WithParser(
+		cron.NewParser(
+			cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow,
+		),
+	)
+
+	return &OrderExpiryWorker{
+		cron:    cron.New(cronOptions, cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		service: service,
+		ttls:    ttls,
+		logger:  logger,
+	}
+}
+
+func (w *OrderExpiryWorker) Start() {
+	_, err := w.cron.AddFunc(every10Min, w.expireOrders)
+	if err != nil {
+		w.logger.Printf("Failed to schedule order expiry job: %v", err)
+	}
+
+	w.cron.Start()
+	w.logger.Println("Order expiry worker started successfully")
+}
+
+func (w *OrderExpiryWorker) Stop() {
+	w.logger.Println("Stopping order expiry worker...")
+
+	ctx := w.cron.Stop()
+	<-ctx.Done()
+
+	w.logger.Println("Order expiry worker stopped successfully")
+}
+
+func (w *OrderExpiryWorker) expireOrders() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+
+	for method, ttl := range w.ttls {
+		if ttl <= 0 {
+			continue
+		}
+
+		expired, err := w.service.ExpirePendingOrders(ctx, dto.ExpirePendingOrdersRequest{
+			PaymentMethod: method,
+			CreatedBefore: now.Add(-ttl),
+		})
+		if err != nil {
+			w.logger.Printf("Error expiring unpaid %s orders: %v", method, err)
+		}
+		if expired > 0 {
+			w.logger.Printf("Cancelled %d unpaid %s orders", expired, method)
+		}
+	}
+
+	purged, err := w.service.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		w.logger.Printf("Error purging expired idempotency keys: %v", err)
+	}
+	if purged > 0 {
+		w.logger.Printf("Purged %d expired idempotency keys", purged)
+	}
+}
@@ -0,0 +1,69 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const every30Min = "0 */30 * * * *"
+
+type ShipmentService interface {
+	SyncShipmentStatuses(ctx context.Context) (int, error)
+}
+
+// ShipmentWorker polls the carrier for parcel statuses and moves orders to
+// shipped or delivered.
+type ShipmentWorker struct {
+	cron    *cron.Cron
+	service ShipmentService
+	logger  *log.Logger
+}
+
+func NewShipmentWorker(service ShipmentService, logger *log.Logger) *ShipmentWorker {
+	cronOptions := cron.WithParser(
+		cron.NewParser(
+			cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow,
+		),
+	)
+
+	return &ShipmentWorker{
+		cron:    cron.New(cronOptions, cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (w *ShipmentWorker) Start() {
+	_, err := w.cron.AddFunc(every30Min, w.syncShipments)
+	if err != nil {
+		w.logger.Printf("Failed to schedule shipment tracking job: %v", err)
+	}
+
+	w.cron.Start()
+	w.logger.Println("Shipment worker started successfully")
+}
+
+func (w *ShipmentWorker) Stop() {
+	w.logger.Println("Stopping shipment worker...")
+
+	ctx := w.cron.Stop()
+	<-ctx.Done()
+
+	w.logger.Println("Shipment worker stopped successfully")
+}
+
+func (w *ShipmentWorker) syncShipments() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	updated, err := w.service.SyncShipmentStatuses(ctx)
+	if err != nil {
+		w.logger.Printf("Error syncing shipment statuses: %v", err)
+	}
+	if updated > 0 {
+		w.logger.Printf("Updated %d orders from shipment tracking", updated)
+	}
+}
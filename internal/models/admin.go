@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/nordew/go-errx"
+)
 
 type Vendor string
 
@@ -8,19 +12,94 @@ const (
 	VendorTelegram Vendor = "telegram"
 )
 
+const (
+	ErrInvalidAdminRole = "Invalid admin role"
+)
+
+// AdminRole decides which parts of the admin API an admin may use.
+type AdminRole string
+
+const (
+	AdminRoleOwner     AdminRole = "owner"
+	AdminRoleManager   AdminRole = "manager"
+	AdminRoleWarehouse AdminRole = "warehouse"
+	AdminRoleMarketing AdminRole = "marketing"
+)
+
+// Permission is an area of the admin API.
+type Permission string
+
+const (
+	PermissionOrders     Permission = "orders"
+	PermissionProducts   Permission = "products"
+	PermissionStock      Permission = "stock"
+	PermissionPromocodes Permission = "promocodes"
+	PermissionAdmins     Permission = "admins"
+)
+
+var rolePermissions = map[AdminRole][]Permission{
+	AdminRoleOwner: {
+		PermissionOrders,
+		PermissionProducts,
+		PermissionStock,
+		PermissionPromocodes,
+		PermissionAdmins,
+	},
+	AdminRoleManager: {
+		PermissionOrders,
+		PermissionProducts,
+		PermissionStock,
+		PermissionPromocodes,
+	},
+	AdminRoleWarehouse: {
+		PermissionStock,
+	},
+	AdminRoleMarketing: {
+		PermissionPromocodes,
+	},
+}
+
+func (r AdminRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the permission.
+func (r AdminRole) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Admin struct {
-	ID         string    `json:"id"`
-	VendorID   string    `json:"vendorId"`
-	VendorType Vendor    `json:"vendorType"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	ID         string     `json:"id"`
+	VendorID   string     `json:"vendorId"`
+	VendorType Vendor     `json:"vendorType"`
+	Role       AdminRole  `json:"role"`
+	DisabledAt *time.Time `json:"disabledAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
 }
 
-func NewAdmin(id string, vendorID string, vendorType Vendor) (Admin, error) {
+// IsActive reports whether the admin may log in.
+func (a Admin) IsActive() bool {
+	return a.DisabledAt == nil
+}
+
+func NewAdmin(id string, vendorID string, vendorType Vendor, role AdminRole) (Admin, error) {
+	if !role.IsValid() {
+		return Admin{}, errx.NewValidation().WithDescription(ErrInvalidAdminRole)
+	}
+
 	return Admin{
 		ID:         id,
 		VendorID:   vendorID,
 		VendorType: vendorType,
+		Role:       role,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}, nil
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+)
+
+// adminInviteTokenBytes keeps the encoded token within the 64 characters a
+// Telegram start parameter allows.
+const adminInviteTokenBytes = 32
+
+// AdminInvite lets whoever opens its link once become an admin with Role.
+// Only a hash of the token is stored.
+type AdminInvite struct {
+	ID          string     `json:"id"`
+	TokenHash   string     `json:"-"`
+	Role        AdminRole  `json:"role"`
+	CreatedBy   string     `json:"createdBy"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	UsedAt      *time.Time `json:"usedAt,omitempty"`
+	UsedByAdmin string     `json:"usedByAdmin,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// NewAdminInvite creates an invite valid for ttl and returns it with the
+// token to hand out.
+func NewAdminInvite(role AdminRole, createdBy string, ttl time.Duration) (AdminInvite, string, error) {
+	if !role.IsValid() {
+		return AdminInvite{}, "", errx.NewValidation().WithDescription(ErrInvalidAdminRole)
+	}
+
+	b := make([]byte, adminInviteTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return AdminInvite{}, "", errx.NewInternal().WithDescriptionAndCause("failed to generate invite token", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+
+	return AdminInvite{
+		ID:        uuid.NewString(),
+		TokenHash: HashAdminInviteToken(token),
+		Role:      role,
+		CreatedBy: createdBy,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, token, nil
+}
+
+func HashAdminInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsUsable reports whether the invite can still be accepted at now.
+func (i AdminInvite) IsUsable(now time.Time) bool {
+	return i.UsedAt == nil && now.Before(i.ExpiresAt)
+}
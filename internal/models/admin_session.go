@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AdminSession is one admin login. Its refresh token rotates on every use and
+// only the latest one, RefreshTokenID, is accepted. A session is revoked on
+// logout or when an older refresh token shows up again.
+type AdminSession struct {
+	ID             string     `json:"id"`
+	AdminID        string     `json:"adminId"`
+	RefreshTokenID string     `json:"-"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func NewAdminSession(id, adminID, refreshTokenID string, expiresAt time.Time) AdminSession {
+	now := time.Now()
+
+	return AdminSession{
+		ID:             id,
+		AdminID:        adminID,
+		RefreshTokenID: refreshTokenID,
+		ExpiresAt:      expiresAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// IsActive reports whether the session can still be refreshed at now.
+func (s AdminSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ShipmentState is a carrier-independent summary of where a parcel is.
+type ShipmentState string
+
+const (
+	ShipmentStateCreated   ShipmentState = "created"
+	ShipmentStateInTransit ShipmentState = "in_transit"
+	ShipmentStateArrived   ShipmentState = "arrived"
+	ShipmentStateDelivered ShipmentState = "delivered"
+	ShipmentStateReturning ShipmentState = "returning"
+	ShipmentStateUnknown   ShipmentState = "unknown"
+)
+
+// OrderStatus returns the order status a parcel in this state implies, or an
+// empty status when the order should be left alone.
+func (s ShipmentState) OrderStatus() OrderStatus {
+	switch s {
+	case ShipmentStateInTransit, ShipmentStateArrived:
+		return OrderStatusShipped
+	case ShipmentStateDelivered:
+		return OrderStatusDelivered
+	default:
+		return ""
+	}
+}
+
+type DeliveryCity struct {
+	Ref    string `json:"ref"`
+	Name   string `json:"name"`
+	Region string `json:"region"`
+}
+
+type DeliveryWarehouse struct {
+	Ref     string `json:"ref"`
+	CityRef string `json:"cityRef"`
+	Number  string `json:"number"`
+	Name    string `json:"name"`
+}
+
+// Waybill is a shipment registered with a carrier. Number is what customers
+// use to track the parcel (a TTN for Nova Poshta).
+type Waybill struct {
+	Ref                   string          `json:"ref"`
+	Number                string          `json:"number"`
+	Cost                  decimal.Decimal `json:"cost"`
+	EstimatedDeliveryDate *time.Time      `json:"estimatedDeliveryDate,omitempty"`
+}
+
+type ShipmentTracking struct {
+	Number     string        `json:"number"`
+	State      ShipmentState `json:"state"`
+	StatusText string        `json:"statusText"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nordew/go-errx"
+)
+
+const (
+	IdempotencyKeyMaxLength = 255
+
+	// IdempotencyKeyTTL is how long a client may retry with the same key.
+	IdempotencyKeyTTL = 24 * time.Hour
+
+	ErrInvalidIdempotencyKey = "Idempotency-Key must be between 1 and 255 characters"
+)
+
+// IdempotencyKey remembers which order a client request produced, so a retry
+// with the same key returns that order instead of placing a new one.
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"requestHash"`
+	OrderID     string    `json:"orderId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+func NewIdempotencyKey(key, requestHash, orderID string) (IdempotencyKey, error) {
+	if key == "" || len(key) > IdempotencyKeyMaxLength {
+		return IdempotencyKey{}, errx.NewBadRequest().WithDescription(ErrInvalidIdempotencyKey)
+	}
+
+	now := time.Now()
+
+	return IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash,
+		OrderID:     orderID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(IdempotencyKeyTTL),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+)
+
+const (
+	ErrInvalidMovementReason = "movement reason must be one of: sale, cancellation, adjustment, restock, return"
+	ErrEmptyMovementDelta    = "movement delta cannot be zero"
+)
+
+type MovementReason string
+
+const (
+	MovementReasonSale         MovementReason = "sale"
+	MovementReasonCancellation MovementReason = "cancellation"
+	MovementReasonAdjustment   MovementReason = "adjustment"
+	MovementReasonRestock      MovementReason = "restock"
+	MovementReasonReturn       MovementReason = "return"
+)
+
+// InventoryMovement records one change of a variant's stock. Delta is in the
+// variant's stock unit and is negative when stock leaves the shelf.
+type InventoryMovement struct {
+	ID          string         `json:"id"`
+	ProductID   string         `json:"productId"`
+	VariantID   string         `json:"variantId"`
+	Delta       int64          `json:"delta"`
+	Reason      MovementReason `json:"reason"`
+	ReferenceID string         `json:"referenceId,omitempty"`
+	AdminID     string         `json:"adminId,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+// InventoryDrift is a variant whose stock no longer matches the sum of its
+// ledger entries.
+type InventoryDrift struct {
+	ProductID    string `json:"productId"`
+	VariantID    string `json:"variantId"`
+	SKU          string `json:"sku"`
+	StockAmount  int64  `json:"stockAmount"`
+	LedgerAmount int64  `json:"ledgerAmount"`
+	Drift        int64  `json:"drift"`
+}
+
+func NewInventoryMovement(
+	productID, variantID string,
+	delta int64,
+	reason MovementReason,
+	referenceID, adminID string,
+) (InventoryMovement, error) {
+	switch reason {
+	case MovementReasonSale, MovementReasonCancellation, MovementReasonAdjustment,
+		MovementReasonRestock, MovementReasonReturn:
+	default:
+		return InventoryMovement{}, errx.NewValidation().WithDescription(ErrInvalidMovementReason)
+	}
+	if delta == 0 {
+		return InventoryMovement{}, errx.NewValidation().WithDescription(ErrEmptyMovementDelta)
+	}
+
+	return InventoryMovement{
+		ID:          uuid.NewString(),
+		ProductID:   productID,
+		VariantID:   variantID,
+		Delta:       delta,
+		Reason:      reason,
+		ReferenceID: referenceID,
+		AdminID:     adminID,
+		CreatedAt:   time.Now(),
+	}, nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"regexp"
 	"time"
@@ -19,18 +21,27 @@ var (
 	ErrPromoCodeRequired     = "PromoCode is required"
 	ErrContactTypeRequired   = "ContactType is required"
 	ErrAmountToPayInvalid    = "AmountToPay must be greater than 0"
+	ErrDiscountAmountInvalid = "DiscountAmount must not be negative or exceed Subtotal"
 	ErrItemAlreadyExists     = "Item already exists"
+	ErrDeliveryPointInvalid  = "Delivery warehouse requires a delivery city"
 )
 
 const (
 	RegexUkrainianPhone = `^(\+?38)?(0\d{9})$`
 )
 
+// trackingTokenBytes is the entropy of a tracking token; 24 bytes encode to
+// 32 URL-safe characters.
+const trackingTokenBytes = 24
+
+var ukrainianPhoneRegexp = regexp.MustCompile(RegexUkrainianPhone)
+
 type PaymentMethod string
 
 const (
 	PaymentMethodIBAN           PaymentMethod = "IBAN"
 	PaymentMethodCashOnDelivery PaymentMethod = "сash_on_delivery"
+	PaymentMethodCard           PaymentMethod = "card"
 )
 
 type ContactType string
@@ -45,24 +56,100 @@ type OrderStatus string
 
 const (
 	OrderStatusPending    OrderStatus = "pending"
+	OrderStatusConfirmed  OrderStatus = "confirmed"
 	OrderStatusProcessing OrderStatus = "processing"
+	OrderStatusShipped    OrderStatus = "shipped"
+	OrderStatusDelivered  OrderStatus = "delivered"
 	OrderStatusCompleted  OrderStatus = "completed"
 	OrderStatusCancelled  OrderStatus = "cancelled"
+	OrderStatusReturned   OrderStatus = "returned"
+
+	OrderStatusPartiallyReturned OrderStatus = "partially_returned"
 )
 
+// orderStatusTransitions lists where an order may go from each status.
+// Cancelled and returned orders are final; a partially returned order can
+// only have the rest of its lines returned.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:    {OrderStatusConfirmed, OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusConfirmed:  {OrderStatusProcessing, OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:    {OrderStatusDelivered, OrderStatusReturned, OrderStatusPartiallyReturned},
+	OrderStatusDelivered:  {OrderStatusCompleted, OrderStatusReturned, OrderStatusPartiallyReturned},
+	OrderStatusCompleted:  {OrderStatusReturned, OrderStatusPartiallyReturned},
+	OrderStatusCancelled:  {},
+	OrderStatusReturned:   {},
+
+	OrderStatusPartiallyReturned: {OrderStatusReturned},
+}
+
+func (s OrderStatus) IsValid() bool {
+	_, ok := orderStatusTransitions[s]
+	return ok
+}
+
+// IsEditable reports whether the lines of an order in status s may still
+// change, which is until it ships.
+func (s OrderStatus) IsEditable() bool {
+	return s.CanTransitionTo(OrderStatusCancelled)
+}
+
+// IsReturnable reports whether goods of an order in status s may be returned.
+func (s OrderStatus) IsReturnable() bool {
+	return s.CanTransitionTo(OrderStatusPartiallyReturned) || s == OrderStatusPartiallyReturned
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateTransition returns a validation error when the order cannot move
+// from status s to next.
+func (s OrderStatus) ValidateTransition(next OrderStatus) error {
+	if !next.IsValid() {
+		return errx.NewValidation().WithDescription(fmt.Sprintf("unknown order status %q", next))
+	}
+	if !s.CanTransitionTo(next) {
+		return errx.NewValidation().WithDescription(
+			fmt.Sprintf("order status cannot change from %s to %s", s, next))
+	}
+
+	return nil
+}
+
 type Order struct {
-	ID            string          `json:"id"`
-	FullName      string          `json:"fullName"`
-	PhoneNumber   string          `json:"phoneNumber"`
-	Address       string          `json:"address"`
-	PaymentMethod PaymentMethod   `json:"paymentMethod"`
-	PromoCode     string          `json:"promoCode"`
-	ContactType   ContactType     `json:"contactType"`
-	AmountToPay   decimal.Decimal `json:"amountToPay"`
-	Status        OrderStatus     `json:"status"`
-	Products      []Product       `json:"products"`
-	CreatedAt     time.Time       `json:"createdAt"`
-	UpdatedAt     time.Time       `json:"updatedAt"`
+	ID             string          `json:"id"`
+	FullName       string          `json:"fullName"`
+	PhoneNumber    string          `json:"phoneNumber"`
+	Address        string          `json:"address"`
+	PaymentMethod  PaymentMethod   `json:"paymentMethod"`
+	PromoCode      string          `json:"promoCode"`
+	ContactType    ContactType     `json:"contactType"`
+	Subtotal       decimal.Decimal `json:"subtotal"`
+	DiscountAmount decimal.Decimal `json:"discountAmount"`
+	AmountToPay    decimal.Decimal `json:"amountToPay"`
+	RefundedAmount decimal.Decimal `json:"refundedAmount"`
+	Status         OrderStatus     `json:"status"`
+	PaymentStatus  PaymentStatus   `json:"paymentStatus"`
+	TrackingToken  string          `json:"trackingToken"`
+
+	// DeliveryCityRef and DeliveryWarehouseRef are the carrier's references
+	// for the branch the parcel goes to. TrackingNumber holds the waybill
+	// number once DeliveryCarrier has registered the shipment.
+	DeliveryCarrier      string `json:"deliveryCarrier"`
+	DeliveryCityRef      string `json:"deliveryCityRef"`
+	DeliveryWarehouseRef string `json:"deliveryWarehouseRef"`
+	TrackingNumber       string `json:"trackingNumber"`
+
+	Products  []Product `json:"products"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 func NewOrder(
@@ -73,21 +160,32 @@ func NewOrder(
 	paymentMethod PaymentMethod,
 	promoCode string,
 	contactType ContactType,
-	amountToPay decimal.Decimal) (Order, error) {
+	subtotal decimal.Decimal,
+	discountAmount decimal.Decimal) (Order, error) {
 	now := time.Now()
 
+	trackingToken, err := NewTrackingToken()
+	if err != nil {
+		return Order{}, err
+	}
+
 	order := Order{
-		ID:            id,
-		FullName:      fullName,
-		PhoneNumber:   phoneNumber,
-		Address:       address,
-		PaymentMethod: paymentMethod,
-		PromoCode:     promoCode,
-		ContactType:   contactType,
-		AmountToPay:   amountToPay,
-		Status:        OrderStatusPending,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+		ID:             id,
+		FullName:       fullName,
+		PhoneNumber:    phoneNumber,
+		Address:        address,
+		PaymentMethod:  paymentMethod,
+		PromoCode:      promoCode,
+		ContactType:    contactType,
+		Subtotal:       subtotal,
+		DiscountAmount: discountAmount,
+		AmountToPay:    subtotal.Sub(discountAmount),
+		RefundedAmount: decimal.Zero,
+		Status:         OrderStatusPending,
+		PaymentStatus:  PaymentStatusUnpaid,
+		TrackingToken:  trackingToken,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
 	if err := order.validate(); err != nil {
@@ -104,7 +202,7 @@ func (o Order) validate() error {
 	if o.FullName == "" {
 		return errx.NewValidation().WithDescription(ErrFullNameRequired)
 	}
-	if o.PhoneNumber == "" || !regexp.MustCompile(RegexUkrainianPhone).MatchString(o.PhoneNumber) {
+	if o.PhoneNumber == "" || !ukrainianPhoneRegexp.MatchString(o.PhoneNumber) {
 		return errx.NewValidation().WithDescription(ErrPhoneNumberInvalid)
 	}
 	if o.Address == "" {
@@ -116,6 +214,9 @@ func (o Order) validate() error {
 	if o.ContactType == "" {
 		return errx.NewValidation().WithDescription(ErrContactTypeRequired)
 	}
+	if o.DiscountAmount.IsNegative() || o.DiscountAmount.GreaterThan(o.Subtotal) {
+		return errx.NewValidation().WithDescription(ErrDiscountAmountInvalid)
+	}
 	if o.AmountToPay.LessThanOrEqual(decimal.Zero) {
 		return errx.NewValidation().WithDescription(ErrAmountToPayInvalid)
 	}
@@ -123,6 +224,41 @@ func (o Order) validate() error {
 	return nil
 }
 
+// NewTrackingToken returns a random URL-safe token that lets a customer follow
+// their order without signing in.
+func NewTrackingToken() (string, error) {
+	b := make([]byte, trackingTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errx.NewInternal().WithDescriptionAndCause("failed to generate tracking token", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IsTrackingTokenFormat reports whether token could have been produced by
+// NewTrackingToken, so malformed input can be rejected without a lookup.
+func IsTrackingTokenFormat(token string) bool {
+	if len(token) != base64.RawURLEncoding.EncodedLen(trackingTokenBytes) {
+		return false
+	}
+
+	_, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil
+}
+
+// SetDeliveryPoint records the carrier branch the order ships to. Both
+// references may be empty for orders delivered by other means.
+func (o *Order) SetDeliveryPoint(cityRef, warehouseRef string) error {
+	if warehouseRef != "" && cityRef == "" {
+		return errx.NewValidation().WithDescription(ErrDeliveryPointInvalid)
+	}
+
+	o.DeliveryCityRef = cityRef
+	o.DeliveryWarehouseRef = warehouseRef
+
+	return nil
+}
+
 func (o *Order) AddProduct(product Product) {
 	for _, existingProduct := range o.Products {
 		if existingProduct.ID == product.ID {
@@ -156,3 +292,14 @@ func (ct *ContactType) Scan(value any) error {
 	}
 	return nil
 }
+
+// NormalizePhoneNumber strips the optional +38 country prefix so that the same
+// customer is recognised regardless of how the number was typed.
+func NormalizePhoneNumber(phoneNumber string) string {
+	matches := ukrainianPhoneRegexp.FindStringSubmatch(phoneNumber)
+	if len(matches) != 3 {
+		return phoneNumber
+	}
+
+	return matches[2]
+}
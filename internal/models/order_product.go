@@ -1,31 +1,64 @@
 package models
 
-import "github.com/nordew/go-errx"
+import (
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
 
 var (
 	ErrInvalidQuantity = errx.NewValidation().WithDescription("quantity must be greater than zero")
 	ErrInvalidVolume   = errx.NewValidation().WithDescription("volume must be between 2 and 10")
+	ErrMissingVolume   = errx.NewValidation().WithDescription("volume must be greater than zero")
 )
 
+// OrderProduct is an order line. Name, brand, SKU and prices are copied from
+// the catalogue when the order is placed so the line never changes afterwards.
 type OrderProduct struct {
-	OrderID   string `json:"orderId"`
-	ProductID string `json:"productId"`
-	Quantity  uint   `json:"quantity"`
-	Volume    uint   `json:"volume"`
+	OrderID   string          `json:"orderId"`
+	ProductID string          `json:"productId"`
+	VariantID string          `json:"variantId"`
+	Brand     string          `json:"brand"`
+	Name      string          `json:"name"`
+	SKU       string          `json:"sku"`
+	Quantity  uint            `json:"quantity"`
+	Volume    uint            `json:"volume"`
+	UnitPrice decimal.Decimal `json:"unitPrice"`
+	LineTotal decimal.Decimal `json:"lineTotal"`
+
+	// ReturnedQuantity is how many of Quantity the customer has sent back.
+	ReturnedQuantity uint `json:"returnedQuantity"`
 }
 
-func NewOrderProduct(orderID, productID string, quantity, volume uint) (OrderProduct, error) {
+// ReturnableQuantity is how many items of the line can still be returned.
+func (op OrderProduct) ReturnableQuantity() uint {
+	return op.Quantity - op.ReturnedQuantity
+}
+
+func NewOrderProduct(
+	orderID string,
+	product Product,
+	variant ProductVariant,
+	quantity, volume uint,
+) (OrderProduct, error) {
 	if quantity <= 0 {
 		return OrderProduct{}, ErrInvalidQuantity
 	}
-	if volume < 2 || volume > 10 {
-		return OrderProduct{}, ErrInvalidVolume
+	if volume == 0 {
+		return OrderProduct{}, ErrMissingVolume
 	}
 
+	unitPrice := variant.UnitPrice(product, volume)
+
 	return OrderProduct{
 		OrderID:   orderID,
-		ProductID: productID,
+		ProductID: product.ID,
+		VariantID: variant.ID,
+		Brand:     product.Brand,
+		Name:      product.Name,
+		SKU:       variant.SKU,
 		Quantity:  quantity,
 		Volume:    volume,
+		UnitPrice: unitPrice,
+		LineTotal: unitPrice.Mul(decimal.NewFromInt(int64(quantity))),
 	}, nil
 }
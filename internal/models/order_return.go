@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ErrReturnItemsRequired   = "Return must contain at least one item"
+	ErrReturnQuantityInvalid = "Returned quantity must be greater than 0"
+	ErrReturnRefundNegative  = "Refund amount must not be negative"
+	ErrReturnVariantRequired = "Returned item must reference an order line"
+)
+
+// OrderReturnItem is how many items of one order line came back. An order
+// line is identified by its variant and volume.
+type OrderReturnItem struct {
+	VariantID string `json:"variantId"`
+	Volume    uint   `json:"volume"`
+	Quantity  uint   `json:"quantity"`
+}
+
+// OrderReturn is goods a customer sent back from a shipped order and the
+// money given back for them. Restocked returns put the goods back on sale.
+type OrderReturn struct {
+	ID           string            `json:"id"`
+	OrderID      string            `json:"orderId"`
+	AdminID      string            `json:"adminId"`
+	Items        []OrderReturnItem `json:"items"`
+	Restock      bool              `json:"restock"`
+	RefundAmount decimal.Decimal   `json:"refundAmount"`
+	Comment      string            `json:"comment,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+}
+
+func NewOrderReturn(
+	orderID, adminID string,
+	items []OrderReturnItem,
+	restock bool,
+	refundAmount decimal.Decimal,
+	comment string,
+) (OrderReturn, error) {
+	if len(items) == 0 {
+		return OrderReturn{}, errx.NewValidation().WithDescription(ErrReturnItemsRequired)
+	}
+	// The same line listed twice is counted once with the quantities summed.
+	merged := make([]OrderReturnItem, 0, len(items))
+	indexByLine := make(map[OrderReturnItem]int, len(items))
+	for _, item := range items {
+		if item.VariantID == "" {
+			return OrderReturn{}, errx.NewValidation().WithDescription(ErrReturnVariantRequired)
+		}
+		if item.Quantity == 0 {
+			return OrderReturn{}, errx.NewValidation().WithDescription(ErrReturnQuantityInvalid)
+		}
+
+		line := OrderReturnItem{VariantID: item.VariantID, Volume: item.Volume}
+		if i, ok := indexByLine[line]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		indexByLine[line] = len(merged)
+		merged = append(merged, item)
+	}
+	if refundAmount.IsNegative() {
+		return OrderReturn{}, errx.NewValidation().WithDescription(ErrReturnRefundNegative)
+	}
+
+	return OrderReturn{
+		ID:           uuid.NewString(),
+		OrderID:      orderID,
+		AdminID:      adminID,
+		Items:        merged,
+		Restock:      restock,
+		RefundAmount: refundAmount,
+		Comment:      comment,
+		CreatedAt:    time.Now(),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ActorType string
+
+const (
+	ActorTypeCustomer ActorType = "customer"
+	ActorTypeAdmin    ActorType = "admin"
+	ActorTypeSystem   ActorType = "system"
+)
+
+// OrderStatusHistory is one status change of an order. FromStatus is empty
+// for the entry written when the order is placed.
+type OrderStatusHistory struct {
+	ID         string      `json:"id"`
+	OrderID    string      `json:"orderId"`
+	FromStatus OrderStatus `json:"fromStatus,omitempty"`
+	ToStatus   OrderStatus `json:"toStatus"`
+	ActorType  ActorType   `json:"actorType"`
+	ActorID    string      `json:"actorId,omitempty"`
+	Comment    string      `json:"comment,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+func NewOrderStatusHistory(
+	orderID string,
+	from, to OrderStatus,
+	actorType ActorType,
+	actorID, comment string,
+) OrderStatusHistory {
+	return OrderStatusHistory{
+		ID:         uuid.NewString(),
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  actorType,
+		ActorID:    actorID,
+		Comment:    comment,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ErrPaymentAmountInvalid = "Payment amount must be greater than 0"
+	ErrPaymentInvoiceEmpty  = "Payment invoice ID is required"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusUnpaid   PaymentStatus = "unpaid"
+	PaymentStatusPaid     PaymentStatus = "paid"
+	PaymentStatusRefunded PaymentStatus = "refunded"
+	PaymentStatusFailed   PaymentStatus = "failed"
+	// PaymentStatusCancelled is an invoice the shop voided before it was
+	// paid, e.g. because the order changed or expired.
+	PaymentStatusCancelled PaymentStatus = "cancelled"
+)
+
+// paymentStatusTransitions lists where a payment may go from each status. A
+// failed payment can still be paid when the customer retries on the same
+// checkout page. A cancelled one is recorded as paid if the money arrives
+// anyway, so it can be refunded.
+var paymentStatusTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusUnpaid:    {PaymentStatusPaid, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusFailed:    {PaymentStatusPaid, PaymentStatusCancelled},
+	PaymentStatusCancelled: {PaymentStatusPaid},
+	PaymentStatusPaid:      {PaymentStatusRefunded},
+	PaymentStatusRefunded:  {},
+}
+
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Payment is one invoice issued by a payment provider for an order.
+type Payment struct {
+	ID             string          `json:"id"`
+	OrderID        string          `json:"orderId"`
+	Provider       string          `json:"provider"`
+	InvoiceID      string          `json:"invoiceId"`
+	CheckoutURL    string          `json:"checkoutUrl"`
+	Amount         decimal.Decimal `json:"amount"`
+	RefundedAmount decimal.Decimal `json:"refundedAmount"`
+	Status         PaymentStatus   `json:"status"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+func NewPayment(orderID, provider, invoiceID, checkoutURL string, amount decimal.Decimal) (Payment, error) {
+	if invoiceID == "" {
+		return Payment{}, errx.NewValidation().WithDescription(ErrPaymentInvoiceEmpty)
+	}
+	if !amount.IsPositive() {
+		return Payment{}, errx.NewValidation().WithDescription(ErrPaymentAmountInvalid)
+	}
+
+	now := time.Now()
+
+	return Payment{
+		ID:             uuid.NewString(),
+		OrderID:        orderID,
+		Provider:       provider,
+		InvoiceID:      invoiceID,
+		CheckoutURL:    checkoutURL,
+		Amount:         amount,
+		RefundedAmount: decimal.Zero,
+		Status:         PaymentStatusUnpaid,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ErrRefundAmountInvalid = "Refund amount must be greater than 0"
+)
+
+type PaymentRefundStatus string
+
+const (
+	PaymentRefundStatusPending   PaymentRefundStatus = "pending"
+	PaymentRefundStatusSucceeded PaymentRefundStatus = "succeeded"
+	PaymentRefundStatusFailed    PaymentRefundStatus = "failed"
+)
+
+// PaymentRefund is money sent back through the payment provider. It is
+// recorded as pending before the provider is asked, so concurrent refunds
+// see it, and settled once the provider answers.
+type PaymentRefund struct {
+	ID        string              `json:"id"`
+	PaymentID string              `json:"paymentId"`
+	OrderID   string              `json:"orderId"`
+	ReturnID  string              `json:"returnId,omitempty"`
+	Amount    decimal.Decimal     `json:"amount"`
+	Status    PaymentRefundStatus `json:"status"`
+	ActorType ActorType           `json:"actorType"`
+	ActorID   string              `json:"actorId"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+func NewPaymentRefund(
+	payment Payment,
+	returnID string,
+	amount decimal.Decimal,
+	actorType ActorType,
+	actorID string,
+) (PaymentRefund, error) {
+	if !amount.IsPositive() {
+		return PaymentRefund{}, errx.NewValidation().WithDescription(ErrRefundAmountInvalid)
+	}
+
+	now := time.Now()
+
+	return PaymentRefund{
+		ID:        uuid.NewString(),
+		PaymentID: payment.ID,
+		OrderID:   payment.OrderID,
+		ReturnID:  returnID,
+		Amount:    amount,
+		Status:    PaymentRefundStatusPending,
+		ActorType: actorType,
+		ActorID:   actorID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
@@ -13,30 +13,33 @@ const (
 	ErrEmptyCategoryID     = "category id cannot be empty"
 	ErrProductEmptyName    = "name cannot be empty"
 	ErrInvalidImageURL     = "image URL is invalid"
-	ErrInvalidProductPrice = "price must be greater than zero"
+	ErrInvalidProductPrice = "price or price per ml must be greater than zero"
+	ErrNegativeProductFee  = "price per ml and bottle fee cannot be negative"
 )
 
 type Product struct {
-	ID              string          `json:"id"`
-	CategoryID      string          `json:"-"`
-	CategoryName    string          `json:"categoryName"`
-	Brand           string          `json:"brand"`
-	Name            string          `json:"name"`
-	ImageURL        string          `json:"imageUrl"`
-	Description     string          `json:"description"`
-	Composition     string          `json:"composition"`
-	Characteristics string          `json:"characteristics"`
-	Price           decimal.Decimal `json:"price"`
-	StockAmount     uint            `json:"stockAmount"`
-	Visible         bool            `json:"visible"`
-	IsBestSeller    bool            `json:"isBestSeller"`
-	CreatedAt       time.Time       `json:"createdAt"`
-	UpdatedAt       time.Time       `json:"updatedAt"`
+	ID              string           `json:"id"`
+	CategoryID      string           `json:"-"`
+	CategoryName    string           `json:"categoryName"`
+	Brand           string           `json:"brand"`
+	Name            string           `json:"name"`
+	ImageURL        string           `json:"imageUrl"`
+	Description     string           `json:"description"`
+	Composition     string           `json:"composition"`
+	Characteristics string           `json:"characteristics"`
+	Price           decimal.Decimal  `json:"price"`
+	PricePerMl      decimal.Decimal  `json:"pricePerMl"`
+	BottleFee       decimal.Decimal  `json:"bottleFee"`
+	Variants        []ProductVariant `json:"variants"`
+	Visible         bool             `json:"visible"`
+	IsBestSeller    bool             `json:"isBestSeller"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	UpdatedAt       time.Time        `json:"updatedAt"`
 }
 
 func NewProduct(
 	id, categoryID, brand, name, description, composition, characteristics string,
-	price decimal.Decimal, stockAmount uint,
+	price, pricePerMl, bottleFee decimal.Decimal,
 	isBestSeller bool,
 ) (Product, error) {
 	p := Product{
@@ -48,7 +51,8 @@ func NewProduct(
 		Composition:     composition,
 		Characteristics: characteristics,
 		Price:           price,
-		StockAmount:     stockAmount,
+		PricePerMl:      pricePerMl,
+		BottleFee:       bottleFee,
 		Visible:         false,
 		IsBestSeller:    isBestSeller,
 		CreatedAt:       time.Now(),
@@ -75,9 +79,45 @@ func (p *Product) Validate() error {
 	if p.ImageURL != "" && !strings.HasPrefix(p.ImageURL, "http") {
 		return errx.NewValidation().WithDescription(ErrInvalidImageURL)
 	}
-	if p.Price == decimal.Zero {
+	if !p.Price.IsPositive() && !p.PricePerMl.IsPositive() {
 		return errx.NewValidation().WithDescription(ErrInvalidProductPrice)
 	}
+	if p.PricePerMl.IsNegative() || p.BottleFee.IsNegative() {
+		return errx.NewValidation().WithDescription(ErrNegativeProductFee)
+	}
 
 	return nil
 }
+
+// UnitPrice returns the price of a single decant of the given volume. Products
+// without a per-ml price are sold at their flat price regardless of volume.
+func (p Product) UnitPrice(volume uint) decimal.Decimal {
+	if !p.PricePerMl.IsPositive() {
+		return p.Price
+	}
+
+	return p.PricePerMl.Mul(decimal.NewFromInt(int64(volume))).Add(p.BottleFee)
+}
+
+// LooseVariant returns the decant poured to order, if the product is sold as
+// one. It need not be the default variant: products that existed before
+// variants default to the bottle they were sold as.
+func (p Product) LooseVariant() (ProductVariant, bool) {
+	for _, v := range p.Variants {
+		if v.IsLoose() {
+			return v, true
+		}
+	}
+
+	return ProductVariant{}, false
+}
+
+func (p Product) DefaultVariant() (ProductVariant, bool) {
+	for _, v := range p.Variants {
+		if v.IsDefault {
+			return v, true
+		}
+	}
+
+	return ProductVariant{}, false
+}
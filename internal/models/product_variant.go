@@ -0,0 +1,138 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	MinLooseDecantVolume = 2
+	MaxLooseDecantVolume = 10
+)
+
+const (
+	ErrVariantProductRequired = "variant must belong to a product"
+	ErrVariantPriceRequired   = "fixed-volume variant must have a price greater than zero"
+	ErrVariantNegativePrice   = "variant price cannot be negative"
+)
+
+// ProductVariant is a sellable form of a product. A variant with zero Volume is
+// a loose decant: the customer picks the volume at checkout, the price comes
+// from the product's per-ml pricing and the stock is kept in millilitres.
+// Any other variant is a fixed size (a pre-filled decant or a full bottle)
+// with its own price and stock counted in pieces.
+type ProductVariant struct {
+	ID          string          `json:"id"`
+	ProductID   string          `json:"productId"`
+	SKU         string          `json:"sku"`
+	Volume      uint            `json:"volume"`
+	Price       decimal.Decimal `json:"price"`
+	StockAmount uint            `json:"stockAmount"`
+	Visible     bool            `json:"visible"`
+	IsDefault   bool            `json:"isDefault"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func NewProductVariant(
+	productID, sku string,
+	volume uint,
+	price decimal.Decimal,
+	stockAmount uint,
+	isDefault bool,
+) (ProductVariant, error) {
+	now := time.Now()
+	id := uuid.NewString()
+
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		sku = generateSKU(productID, volume)
+	}
+
+	v := ProductVariant{
+		ID:          id,
+		ProductID:   productID,
+		SKU:         sku,
+		Volume:      volume,
+		Price:       price,
+		StockAmount: stockAmount,
+		Visible:     true,
+		IsDefault:   isDefault,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := v.Validate(); err != nil {
+		return ProductVariant{}, err
+	}
+
+	return v, nil
+}
+
+func (v *ProductVariant) Validate() error {
+	if strings.TrimSpace(v.ProductID) == "" {
+		return errx.NewValidation().WithDescription(ErrVariantProductRequired)
+	}
+	if v.Price.IsNegative() {
+		return errx.NewValidation().WithDescription(ErrVariantNegativePrice)
+	}
+	if !v.IsLoose() && !v.Price.IsPositive() {
+		return errx.NewValidation().WithDescription(ErrVariantPriceRequired)
+	}
+
+	return nil
+}
+
+func (v ProductVariant) IsLoose() bool {
+	return v.Volume == 0
+}
+
+// LineVolume returns the volume of one piece of the line. Fixed-size variants
+// ignore the requested volume.
+func (v ProductVariant) LineVolume(requested uint) (uint, error) {
+	if !v.IsLoose() {
+		return v.Volume, nil
+	}
+
+	if requested < MinLooseDecantVolume || requested > MaxLooseDecantVolume {
+		return 0, ErrInvalidVolume
+	}
+
+	return requested, nil
+}
+
+func (v ProductVariant) UnitPrice(product Product, volume uint) decimal.Decimal {
+	if v.IsLoose() {
+		return product.UnitPrice(volume)
+	}
+
+	return v.Price
+}
+
+// StockUnits returns how much stock a line consumes: millilitres for loose
+// decants and pieces for fixed-size variants.
+func (v ProductVariant) StockUnits(volume, quantity uint) uint {
+	if v.IsLoose() {
+		return volume * quantity
+	}
+
+	return quantity
+}
+
+func generateSKU(productID string, volume uint) string {
+	prefix := strings.ToUpper(strings.ReplaceAll(productID, "-", ""))
+	if len(prefix) > 12 {
+		prefix = prefix[:12]
+	}
+
+	if volume == 0 {
+		return "AH-" + prefix
+	}
+
+	return fmt.Sprintf("AH-%s-%d", prefix, volume)
+}
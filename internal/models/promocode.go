@@ -1,45 +1,223 @@
 package models
 
 import (
+	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/nordew/go-errx"
+	"github.com/shopspring/decimal"
 )
 
 var (
-	ErrInvalidPromocodeLength     = "code cannot be empty or less than 3 characters or more than 10 characters"
+	ErrInvalidPromocodeLength     = "code cannot be empty or less than 3 characters or more than 20 characters"
 	ErrInvalidPromocodeDiscount   = "discount cannot be greater than 100 or less than 0"
+	ErrInvalidFixedDiscount       = "fixed discount must be greater than 0"
+	ErrInvalidDiscountType        = "discount type must be either percent or fixed"
+	ErrInvalidPromocodeScopeID    = "promocode scope contains an invalid ID"
 	ErrInvalidPromocodeExpiration = "expiration cannot be in the past"
+	ErrInvalidPromocodeStart      = "start date must be before expiration"
+	ErrInvalidPromocodeMinAmount  = "minimum order amount cannot be negative"
 )
 
+const (
+	PromocodeMinLength = 3
+	PromocodeMaxLength = 20
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercent DiscountType = "percent"
+	DiscountTypeFixed   DiscountType = "fixed"
+)
+
+// PromocodeScope restricts a promocode to matching products. Every non-empty
+// list must match; within a list any entry is enough. An empty scope covers
+// the whole order.
+type PromocodeScope struct {
+	CategoryIDs []string `json:"categoryIds"`
+	Brands      []string `json:"brands"`
+	ProductIDs  []string `json:"productIds"`
+}
+
 type Promocode struct {
-	ID        string    `json:"id"`
-	Code      string    `json:"code"`
-	Discount  uint      `json:"discount"`
-	ExpiresAt time.Time `json:"expiresAt"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               string          `json:"id"`
+	Code             string          `json:"code"`
+	Campaign         string          `json:"campaign"`
+	DiscountType     DiscountType    `json:"discountType"`
+	Discount         uint            `json:"discount"`
+	Scope            PromocodeScope  `json:"scope"`
+	StartsAt         time.Time       `json:"startsAt"`
+	ExpiresAt        time.Time       `json:"expiresAt"`
+	MaxRedemptions   uint            `json:"maxRedemptions"`
+	PerCustomerLimit uint            `json:"perCustomerLimit"`
+	MinOrderAmount   decimal.Decimal `json:"minOrderAmount"`
+	UsedCount        uint            `json:"usedCount"`
+	RemainingCount   *uint           `json:"remainingCount"`
+	DeletedAt        *time.Time      `json:"-"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
 }
 
-func NewPromocode(code string, discount uint, expiresAt time.Time) (Promocode, error) {
-	if code == "" || utf8.RuneCountInString(code) < 3 || utf8.RuneCountInString(code) > 10 {
+func NewPromocode(
+	code string,
+	discountType DiscountType,
+	discount uint,
+	scope PromocodeScope,
+	startsAt time.Time,
+	expiresAt time.Time,
+	maxRedemptions uint,
+	perCustomerLimit uint,
+	minOrderAmount decimal.Decimal,
+) (Promocode, error) {
+	now := time.Now()
+
+	if code == "" || utf8.RuneCountInString(code) < PromocodeMinLength || utf8.RuneCountInString(code) > PromocodeMaxLength {
 		return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeLength)
 	}
-	if discount <= 0 || discount >= 100 {
-		return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeDiscount)
+	if discountType == "" {
+		discountType = DiscountTypePercent
+	}
+	switch discountType {
+	case DiscountTypePercent:
+		if discount <= 0 || discount >= 100 {
+			return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeDiscount)
+		}
+	case DiscountTypeFixed:
+		if discount == 0 {
+			return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidFixedDiscount)
+		}
+	default:
+		return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidDiscountType)
+	}
+	scope, err := normalizeScope(scope)
+	if err != nil {
+		return Promocode{}, err
 	}
-	if expiresAt.Before(time.Now()) {
+	if expiresAt.Before(now) {
 		return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeExpiration)
 	}
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+	if !startsAt.Before(expiresAt) {
+		return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeStart)
+	}
+	if minOrderAmount.IsNegative() {
+		return Promocode{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeMinAmount)
+	}
+
+	p := Promocode{
+		ID:               uuid.NewString(),
+		Code:             code,
+		DiscountType:     discountType,
+		Discount:         discount,
+		Scope:            scope,
+		StartsAt:         startsAt,
+		ExpiresAt:        expiresAt,
+		MaxRedemptions:   maxRedemptions,
+		PerCustomerLimit: perCustomerLimit,
+		MinOrderAmount:   minOrderAmount,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	p.SetUsedCount(0)
+
+	return p, nil
+}
+
+func (p Promocode) IsExpired() bool {
+	return p.ExpiresAt.Before(time.Now())
+}
+
+func (p Promocode) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+func (p Promocode) IsStarted() bool {
+	return !p.StartsAt.After(time.Now())
+}
+
+// SetUsedCount records the number of active redemptions and derives the remaining
+// count from it. RemainingCount stays nil for codes without a total limit.
+func (p *Promocode) SetUsedCount(used uint) {
+	p.UsedCount = used
+	p.RemainingCount = nil
+
+	if p.MaxRedemptions == 0 {
+		return
+	}
+
+	remaining := uint(0)
+	if used < p.MaxRedemptions {
+		remaining = p.MaxRedemptions - used
+	}
+	p.RemainingCount = &remaining
+}
+
+func (p Promocode) AppliesTo(product Product) bool {
+	if len(p.Scope.ProductIDs) > 0 && !containsFold(p.Scope.ProductIDs, product.ID) {
+		return false
+	}
+	if len(p.Scope.CategoryIDs) > 0 && !containsFold(p.Scope.CategoryIDs, product.CategoryID) {
+		return false
+	}
+	if len(p.Scope.Brands) > 0 && !containsFold(p.Scope.Brands, product.Brand) {
+		return false
+	}
+
+	return true
+}
+
+// DiscountFor returns the discount granted on the eligible amount, rounded to
+// whole hryvnias. A fixed discount never exceeds the amount it applies to.
+func (p Promocode) DiscountFor(amount decimal.Decimal) decimal.Decimal {
+	if p.DiscountType == DiscountTypeFixed {
+		return decimal.Min(decimal.NewFromInt(int64(p.Discount)), amount)
+	}
+
+	return amount.
+		Mul(decimal.NewFromInt(int64(p.Discount))).
+		Div(decimal.NewFromInt(100)).
+		Round(0)
+}
+
+func normalizeScope(scope PromocodeScope) (PromocodeScope, error) {
+	result := PromocodeScope{
+		CategoryIDs: make([]string, 0, len(scope.CategoryIDs)),
+		Brands:      make([]string, 0, len(scope.Brands)),
+		ProductIDs:  make([]string, 0, len(scope.ProductIDs)),
+	}
+
+	for _, id := range scope.CategoryIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return PromocodeScope{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeScopeID)
+		}
+		result.CategoryIDs = append(result.CategoryIDs, id)
+	}
+	for _, id := range scope.ProductIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return PromocodeScope{}, errx.NewValidation().WithDescription(ErrInvalidPromocodeScopeID)
+		}
+		result.ProductIDs = append(result.ProductIDs, id)
+	}
+	for _, brand := range scope.Brands {
+		if brand = strings.TrimSpace(brand); brand != "" {
+			result.Brands = append(result.Brands, brand)
+		}
+	}
+
+	return result, nil
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
 
-	return Promocode{
-		ID:        uuid.NewString(),
-		Code:      code,
-		Discount:  discount,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}, nil
+	return false
 }
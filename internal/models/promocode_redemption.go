@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type PromocodeRedemption struct {
+	ID             string          `json:"id"`
+	PromocodeID    string          `json:"promocodeId"`
+	OrderID        string          `json:"orderId"`
+	PhoneNumber    string          `json:"phoneNumber"`
+	DiscountAmount decimal.Decimal `json:"discountAmount"`
+	ReleasedAt     *time.Time      `json:"releasedAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+func NewPromocodeRedemption(
+	promocodeID string,
+	orderID string,
+	phoneNumber string,
+	discountAmount decimal.Decimal,
+) PromocodeRedemption {
+	return PromocodeRedemption{
+		ID:             uuid.NewString(),
+		PromocodeID:    promocodeID,
+		OrderID:        orderID,
+		PhoneNumber:    NormalizePhoneNumber(phoneNumber),
+		DiscountAmount: discountAmount,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN subtotal DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+UPDATE orders SET subtotal = amount_to_pay;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS subtotal;
+
+-- +goose StatementEnd
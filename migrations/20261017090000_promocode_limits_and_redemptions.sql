@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE promocodes
+    ADD COLUMN starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN max_redemptions INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN per_customer_limit INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN min_order_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE promocodes SET starts_at = created_at;
+
+CREATE TABLE promocode_redemptions (
+    id UUID PRIMARY KEY,
+    -- Promocodes are soft-deleted so the ledger outlives them.
+    promocode_id UUID NOT NULL REFERENCES promocodes (id) ON DELETE RESTRICT,
+    order_id UUID NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_promocode_redemptions_promocode_id ON promocode_redemptions (promocode_id);
+
+CREATE INDEX idx_promocode_redemptions_phone_number ON promocode_redemptions (promocode_id, phone_number);
+
+CREATE UNIQUE INDEX idx_promocode_redemptions_active_order ON promocode_redemptions (order_id)
+WHERE
+    released_at IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS promocode_redemptions;
+
+DELETE FROM promocodes WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE promocodes
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS min_order_amount,
+    DROP COLUMN IF EXISTS per_customer_limit,
+    DROP COLUMN IF EXISTS max_redemptions,
+    DROP COLUMN IF EXISTS starts_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE promocodes
+    ADD COLUMN discount_type VARCHAR(20) NOT NULL DEFAULT 'percent',
+    ADD COLUMN category_ids TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN brands TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN product_ids TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE promocodes
+    DROP COLUMN IF EXISTS product_ids,
+    DROP COLUMN IF EXISTS brands,
+    DROP COLUMN IF EXISTS category_ids,
+    DROP COLUMN IF EXISTS discount_type;
+
+-- +goose StatementEnd
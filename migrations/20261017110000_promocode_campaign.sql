@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE promocodes
+    ADD COLUMN campaign VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_promocodes_campaign ON promocodes (campaign);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_promocodes_campaign;
+
+ALTER TABLE promocodes
+    DROP COLUMN IF EXISTS campaign;
+
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+    ADD COLUMN price_per_ml DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN bottle_fee DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Stock was counted in pieces and is taken out in millilitres from now on.
+-- A piece is taken to be the volume the product was most often ordered in;
+-- products never ordered count 100 ml a piece.
+UPDATE products p
+SET stock_amount = p.stock_amount * COALESCE(
+        (
+            SELECT op.volume
+            FROM order_products op
+            WHERE op.product_id = p.id AND op.volume > 0
+            GROUP BY op.volume
+            ORDER BY COUNT(*) DESC, op.volume DESC
+            LIMIT 1
+        ),
+        100
+    );
+
+COMMENT ON COLUMN products.stock_amount IS 'Available stock in millilitres';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+COMMENT ON COLUMN products.stock_amount IS NULL;
+
+UPDATE products p
+SET stock_amount = p.stock_amount / COALESCE(
+        (
+            SELECT op.volume
+            FROM order_products op
+            WHERE op.product_id = p.id AND op.volume > 0
+            GROUP BY op.volume
+            ORDER BY COUNT(*) DESC, op.volume DESC
+            LIMIT 1
+        ),
+        100
+    );
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS bottle_fee,
+    DROP COLUMN IF EXISTS price_per_ml;
+
+-- +goose StatementEnd
@@ -0,0 +1,96 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE product_variants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    volume INTEGER NOT NULL DEFAULT 0,
+    price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    stock_amount INTEGER NOT NULL DEFAULT 0,
+    visible BOOLEAN NOT NULL DEFAULT TRUE,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN product_variants.volume IS 'Bottle size in millilitres, 0 for decants poured to order';
+COMMENT ON COLUMN product_variants.stock_amount IS 'Millilitres for decants poured to order, pieces otherwise';
+
+CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
+CREATE UNIQUE INDEX idx_product_variants_default ON product_variants (product_id) WHERE is_default;
+CREATE UNIQUE INDEX idx_product_variants_loose ON product_variants (product_id) WHERE volume = 0;
+
+CREATE TRIGGER trigger_update_product_variants_updated_at
+BEFORE UPDATE ON product_variants
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing products were sold by the piece at a flat price, so each becomes a
+-- fixed-size variant keeping its price, with its stock turned back from
+-- millilitres into pieces. The bottle size is the volume the product was most
+-- often ordered in, as when its stock was turned into millilitres; products
+-- never ordered get 100 ml, which admins can correct on the variant. The
+-- bottle is the product's default variant; a decant poured to order can be
+-- added next to it.
+INSERT INTO product_variants (product_id, sku, volume, price, stock_amount, visible, is_default)
+SELECT
+    p.id,
+    'AH-' || UPPER(LEFT(REPLACE(p.id::TEXT, '-', ''), 12)),
+    COALESCE(
+        (
+            SELECT op.volume
+            FROM order_products op
+            WHERE op.product_id = p.id AND op.volume > 0
+            GROUP BY op.volume
+            ORDER BY COUNT(*) DESC, op.volume DESC
+            LIMIT 1
+        ),
+        100
+    ),
+    p.price,
+    0,
+    TRUE,
+    TRUE
+FROM products p;
+
+UPDATE product_variants pv
+SET stock_amount = p.stock_amount / pv.volume
+FROM products p
+WHERE p.id = pv.product_id AND pv.is_default;
+
+ALTER TABLE order_products ADD COLUMN variant_id UUID REFERENCES product_variants (id);
+
+UPDATE order_products op
+SET variant_id = pv.id
+FROM product_variants pv
+WHERE pv.product_id = op.product_id AND pv.is_default;
+
+ALTER TABLE order_products ALTER COLUMN variant_id SET NOT NULL;
+ALTER TABLE order_products DROP CONSTRAINT order_products_pkey;
+ALTER TABLE order_products ADD PRIMARY KEY (order_id, variant_id, volume);
+
+CREATE INDEX idx_order_products_variant_id ON order_products (variant_id);
+
+ALTER TABLE products DROP COLUMN stock_amount;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN stock_amount INTEGER NOT NULL DEFAULT 0;
+
+UPDATE products p
+SET stock_amount = CASE WHEN pv.volume = 0 THEN pv.stock_amount ELSE pv.stock_amount * pv.volume END
+FROM product_variants pv
+WHERE pv.product_id = p.id AND pv.is_default;
+
+DELETE FROM order_products op
+USING product_variants pv
+WHERE pv.id = op.variant_id AND NOT pv.is_default;
+
+ALTER TABLE order_products DROP CONSTRAINT order_products_pkey;
+ALTER TABLE order_products ADD PRIMARY KEY (order_id, product_id);
+DROP INDEX IF EXISTS idx_order_products_variant_id;
+ALTER TABLE order_products DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants;
+
+-- +goose StatementEnd
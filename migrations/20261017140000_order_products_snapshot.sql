@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE order_products
+    ADD COLUMN brand VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN sku VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN unit_price DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN line_total DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- Existing lines get the catalogue values as they are at migration time.
+UPDATE order_products op
+SET brand = p.brand,
+    name = p.name,
+    sku = pv.sku,
+    unit_price = CASE
+        WHEN pv.volume > 0 THEN pv.price
+        WHEN p.price_per_ml > 0 THEN p.price_per_ml * op.volume + p.bottle_fee
+        ELSE p.price
+    END
+FROM products p, product_variants pv
+WHERE p.id = op.product_id AND pv.id = op.variant_id;
+
+UPDATE order_products SET line_total = unit_price * quantity;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_products
+    DROP COLUMN IF EXISTS line_total,
+    DROP COLUMN IF EXISTS unit_price,
+    DROP COLUMN IF EXISTS sku,
+    DROP COLUMN IF EXISTS name,
+    DROP COLUMN IF EXISTS brand;
+
+-- +goose StatementEnd
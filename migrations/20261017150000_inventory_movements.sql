@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE inventory_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id UUID NOT NULL REFERENCES product_variants (id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    reference_id VARCHAR(100) NOT NULL DEFAULT '',
+    admin_id UUID REFERENCES admins (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inventory_movements_product_id ON inventory_movements (product_id, created_at);
+CREATE INDEX idx_inventory_movements_variant_id ON inventory_movements (variant_id);
+CREATE INDEX idx_inventory_movements_reference_id ON inventory_movements (reference_id);
+
+-- Opening balance, so the ledger of existing variants reconciles from day one.
+INSERT INTO inventory_movements (product_id, variant_id, delta, reason, reference_id)
+SELECT product_id, id, stock_amount, 'adjustment', 'opening-balance'
+FROM product_variants
+WHERE stock_amount <> 0;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS inventory_movements;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN tracking_token VARCHAR(64) UNIQUE,
+    ADD COLUMN tracking_number VARCHAR(100) NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS tracking_number,
+    DROP COLUMN IF EXISTS tracking_token;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN delivery_carrier VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN delivery_city_ref VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN delivery_warehouse_ref VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_orders_tracking_number ON orders(tracking_number) WHERE tracking_number <> '';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_tracking_number;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_warehouse_ref,
+    DROP COLUMN IF EXISTS delivery_city_ref,
+    DROP COLUMN IF EXISTS delivery_carrier;
+
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid';
+
+CREATE INDEX IF NOT EXISTS idx_orders_payment_status ON orders(payment_status);
+
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    invoice_id VARCHAR(255) NOT NULL,
+    checkout_url TEXT NOT NULL DEFAULT '',
+    amount DECIMAL(15,2) NOT NULL,
+    refunded_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'unpaid',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, invoice_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id, created_at);
+
+CREATE TRIGGER trigger_update_payments_updated_at
+BEFORE UPDATE ON payments
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payments;
+
+DROP INDEX IF EXISTS idx_orders_payment_status;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_status;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN refunded_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE order_products ADD COLUMN returned_quantity INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE order_returns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    admin_id VARCHAR(255) NOT NULL DEFAULT '',
+    restock BOOLEAN NOT NULL DEFAULT FALSE,
+    refund_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_returns_order_id ON order_returns(order_id, created_at);
+
+CREATE TABLE order_return_items (
+    return_id UUID NOT NULL REFERENCES order_returns(id) ON DELETE CASCADE,
+    variant_id UUID NOT NULL,
+    volume INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (return_id, variant_id, volume)
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_return_items;
+
+DROP TABLE IF EXISTS order_returns;
+
+ALTER TABLE order_products DROP COLUMN IF EXISTS returned_quantity;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS refunded_amount;
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE admin_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    refresh_token_id UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_sessions_admin_id ON admin_sessions(admin_id) WHERE revoked_at IS NULL;
+
+CREATE TRIGGER trigger_update_admin_sessions_updated_at
+BEFORE UPDATE ON admin_sessions
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_update_admin_sessions_updated_at ON admin_sessions;
+
+DROP TABLE IF EXISTS admin_sessions;
+
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Admins that exist already could do everything, so they become owners.
+ALTER TABLE admins ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'owner';
+
+ALTER TABLE admins ALTER COLUMN role SET DEFAULT 'manager';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE admins DROP COLUMN IF EXISTS role;
+
+-- +goose StatementEnd
@@ -31,7 +31,8 @@ CREATE TABLE IF NOT EXISTS products (
     composition TEXT,
     characteristics TEXT,
     price INTEGER NOT NULL,
-    stock_amount INTEGER NOT NULL DEFAULT 0,
+    price_per_ml DECIMAL(10,2) NOT NULL DEFAULT 0,
+    bottle_fee DECIMAL(10,2) NOT NULL DEFAULT 0,
     visible BOOLEAN NOT NULL DEFAULT FALSE,
     is_best_seller BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
@@ -48,6 +49,30 @@ CREATE INDEX IF NOT EXISTS idx_products_best_seller    ON products(is_best_selle
 CREATE TRIGGER trigger_update_products_updated_at
 BEFORE UPDATE ON products
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    volume INTEGER NOT NULL DEFAULT 0,
+    price DECIMAL(10,2) NOT NULL DEFAULT 0,
+    stock_amount INTEGER NOT NULL DEFAULT 0,
+    visible BOOLEAN NOT NULL DEFAULT TRUE,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN product_variants.volume IS 'Bottle size in millilitres, 0 for decants poured to order';
+COMMENT ON COLUMN product_variants.stock_amount IS 'Millilitres for decants poured to order, pieces otherwise';
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants(product_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_default ON product_variants(product_id) WHERE is_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_loose ON product_variants(product_id) WHERE volume = 0;
+
+CREATE TRIGGER trigger_update_product_variants_updated_at
+BEFORE UPDATE ON product_variants
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
   
 CREATE TABLE IF NOT EXISTS orders (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -58,8 +83,17 @@ CREATE TABLE IF NOT EXISTS orders (
     payment_method VARCHAR(50) NOT NULL,
     promo_code VARCHAR(100),
     contact_type VARCHAR(50) NOT NULL,
+    subtotal DECIMAL(15,2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
     amount_to_pay DECIMAL(15,2) NOT NULL,
+    refunded_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
     status VARCHAR(50) NOT NULL,
+    payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid',
+    tracking_token VARCHAR(64) UNIQUE,
+    delivery_carrier VARCHAR(50) NOT NULL DEFAULT '',
+    delivery_city_ref VARCHAR(64) NOT NULL DEFAULT '',
+    delivery_warehouse_ref VARCHAR(64) NOT NULL DEFAULT '',
+    tracking_number VARCHAR(100) NOT NULL DEFAULT '',
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
@@ -67,6 +101,8 @@ CREATE TABLE IF NOT EXISTS orders (
 CREATE INDEX IF NOT EXISTS idx_orders_user_id     ON orders(user_id);
 CREATE INDEX IF NOT EXISTS idx_orders_status      ON orders(status);
 CREATE INDEX IF NOT EXISTS idx_orders_created_at  ON orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_payment_status ON orders(payment_status);
+CREATE INDEX IF NOT EXISTS idx_orders_tracking_number ON orders(tracking_number) WHERE tracking_number <> '';
 
 CREATE TRIGGER trigger_update_orders_updated_at
 BEFORE UPDATE ON orders
@@ -75,13 +111,24 @@ FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TABLE IF NOT EXISTS promocodes (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     code VARCHAR(50) UNIQUE NOT NULL,
+    campaign VARCHAR(100) NOT NULL DEFAULT '',
+    discount_type VARCHAR(20) NOT NULL DEFAULT 'percent',
     discount INTEGER NOT NULL,
+    category_ids TEXT[] NOT NULL DEFAULT '{}',
+    brands TEXT[] NOT NULL DEFAULT '{}',
+    product_ids TEXT[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     expires_at TIMESTAMPTZ NOT NULL,
+    max_redemptions INTEGER NOT NULL DEFAULT 0,
+    per_customer_limit INTEGER NOT NULL DEFAULT 0,
+    min_order_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    deleted_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE INDEX IF NOT EXISTS idx_promocodes_expires_at ON promocodes(expires_at);
+CREATE INDEX IF NOT EXISTS idx_promocodes_campaign   ON promocodes(campaign);
 
 CREATE TRIGGER trigger_update_promocodes_updated_at
 BEFORE UPDATE ON promocodes
@@ -90,17 +137,41 @@ FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TABLE IF NOT EXISTS order_products (
     order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
     product_id UUID NOT NULL REFERENCES products(id),
+    variant_id UUID NOT NULL REFERENCES product_variants(id),
+    brand VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    sku VARCHAR(64) NOT NULL DEFAULT '',
     quantity INTEGER NOT NULL,
     volume INTEGER NOT NULL,
-    PRIMARY KEY (order_id, product_id)
+    unit_price DECIMAL(15,2) NOT NULL DEFAULT 0,
+    line_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    returned_quantity INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (order_id, variant_id, volume)
 );
 
 CREATE INDEX IF NOT EXISTS idx_order_products_product_id ON order_products(product_id);
+CREATE INDEX IF NOT EXISTS idx_order_products_variant_id ON order_products(variant_id);
+
+CREATE TABLE IF NOT EXISTS promocode_redemptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    promocode_id UUID NOT NULL REFERENCES promocodes(id) ON DELETE RESTRICT,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    released_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promocode_redemptions_promocode_id ON promocode_redemptions(promocode_id);
+CREATE INDEX IF NOT EXISTS idx_promocode_redemptions_phone_number ON promocode_redemptions(promocode_id, phone_number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promocode_redemptions_active_order ON promocode_redemptions(order_id) WHERE released_at IS NULL;
 
 CREATE TABLE IF NOT EXISTS admins (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     vendor_id VARCHAR(255) NOT NULL,
     vendor_type VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'manager',
+    disabled_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     UNIQUE (vendor_id, vendor_type)
@@ -110,3 +181,131 @@ CREATE TRIGGER trigger_update_admins_updated_at
 BEFORE UPDATE ON admins
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    reference_id VARCHAR(100) NOT NULL DEFAULT '',
+    admin_id UUID REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id   ON inventory_movements(product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_variant_id   ON inventory_movements(variant_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_reference_id ON inventory_movements(reference_id);
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NOT NULL DEFAULT '',
+    to_status VARCHAR(50) NOT NULL,
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history(order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    invoice_id VARCHAR(255) NOT NULL,
+    checkout_url TEXT NOT NULL DEFAULT '',
+    amount DECIMAL(15,2) NOT NULL,
+    refunded_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'unpaid',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, invoice_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id, created_at);
+
+CREATE TRIGGER trigger_update_payments_updated_at
+BEFORE UPDATE ON payments
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS order_returns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    admin_id VARCHAR(255) NOT NULL DEFAULT '',
+    restock BOOLEAN NOT NULL DEFAULT FALSE,
+    refund_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_returns_order_id ON order_returns(order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS order_return_items (
+    return_id UUID NOT NULL REFERENCES order_returns(id) ON DELETE CASCADE,
+    variant_id UUID NOT NULL,
+    volume INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (return_id, variant_id, volume)
+);
+
+CREATE TABLE IF NOT EXISTS admin_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    refresh_token_id UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_sessions_admin_id ON admin_sessions(admin_id) WHERE revoked_at IS NULL;
+
+CREATE TRIGGER trigger_update_admin_sessions_updated_at
+BEFORE UPDATE ON admin_sessions
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS admin_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    used_by_admin_id UUID REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    return_id UUID REFERENCES order_returns(id) ON DELETE SET NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_order_id ON payment_refunds(order_id) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_payment_id ON payment_refunds(payment_id);
+
+CREATE TRIGGER trigger_update_payment_refunds_updated_at
+BEFORE UPDATE ON payment_refunds
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_promocodes_lower_code ON promocodes(lower(code));
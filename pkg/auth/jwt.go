@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 const (
@@ -26,16 +27,32 @@ const (
 	RefreshToken TokenType = "refresh"
 )
 
+// Identity is who a token is issued to.
+type Identity struct {
+	UserID   string
+	VendorID string
+	// SessionID ties the tokens issued to one login together.
+	SessionID string
+	// Role is what the user may do; the token service does not interpret it.
+	Role string
+}
+
+// Claims are carried by every token. RegisteredClaims.ID is the token's
+// unique ID (jti).
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID    string    `json:"userId"`
 	VendorID  string    `json:"vendorId,omitempty"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Role      string    `json:"role,omitempty"`
 	TokenType TokenType `json:"tokenType"`
 }
 
+// Config holds the keys of each token type. Access and refresh tokens never
+// share a key, so one can't be passed off as the other.
 type Config struct {
-	AccessTokenSecret    string
-	RefreshTokenSecret   string
+	AccessKeys           KeySet
+	RefreshKeys          KeySet
 	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
 	Issuer               string
@@ -43,8 +60,8 @@ type Config struct {
 
 func DefaultConfig() Config {
 	return Config{
-		AccessTokenSecret:    "default_access_token_secret",
-		RefreshTokenSecret:   "default_refresh_token_secret",
+		AccessKeys:           NewKeySet(hmacKey("default-access", "default_access_token_secret")),
+		RefreshKeys:          NewKeySet(hmacKey("default-refresh", "default_refresh_token_secret")),
 		AccessTokenDuration:  15 * time.Minute,
 		RefreshTokenDuration: 24 * time.Hour * 7,
 		Issuer:               Issuer,
@@ -55,65 +72,106 @@ type TokenService struct {
 	config Config
 }
 
-func NewTokenService(config Config) *TokenService {
+func NewTokenService(config Config) (*TokenService, error) {
+	if err := config.AccessKeys.validate(); err != nil {
+		return nil, fmt.Errorf("access keys: %w", err)
+	}
+	if err := config.RefreshKeys.validate(); err != nil {
+		return nil, fmt.Errorf("refresh keys: %w", err)
+	}
+	if config.AccessKeys.shares(config.RefreshKeys) {
+		return nil, ErrKeysNotDistinct
+	}
+
 	return &TokenService{
 		config: config,
-	}
+	}, nil
 }
 
-func NewDefaultTokenService() *TokenService {
+func NewDefaultTokenService() (*TokenService, error) {
 	return NewTokenService(DefaultConfig())
 }
 
-func (s *TokenService) GenerateAccessToken(userID, vendorID string) (string, error) {
-	return s.generateToken(userID, vendorID, AccessToken, s.config.AccessTokenSecret, s.config.AccessTokenDuration)
+func (s *TokenService) GenerateAccessToken(identity Identity) (string, error) {
+	token, _, err := s.generateToken(identity, AccessToken, s.config.AccessKeys.Signing, s.config.AccessTokenDuration)
+	return token, err
 }
 
-func (s *TokenService) GenerateRefreshToken(userID, vendorID string) (string, error) {
-	return s.generateToken(userID, vendorID, RefreshToken, s.config.RefreshTokenSecret, s.config.RefreshTokenDuration)
+// GenerateRefreshToken issues a refresh token for the session and returns its
+// claims, whose ID and expiry the caller stores to rotate the token later.
+func (s *TokenService) GenerateRefreshToken(identity Identity) (string, *Claims, error) {
+	return s.generateToken(identity, RefreshToken, s.config.RefreshKeys.Signing, s.config.RefreshTokenDuration)
 }
 
-func (s *TokenService) generateToken(userID, vendorID string, tokenType TokenType, secret string, duration time.Duration) (string, error) {
+func (s *TokenService) generateToken(
+	identity Identity,
+	tokenType TokenType,
+	key Key,
+	duration time.Duration,
+) (string, *Claims, error) {
 	now := time.Now()
 
-	claims := Claims{
+	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    s.config.Issuer,
-			Subject:   userID,
+			Subject:   identity.UserID,
 		},
-		UserID:    userID,
-		VendorID:  vendorID,
+		UserID:    identity.UserID,
+		VendorID:  identity.VendorID,
+		SessionID: identity.SessionID,
+		Role:      identity.Role,
 		TokenType: tokenType,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
 
-	signedToken, err := token.SignedString([]byte(secret))
+	signedToken, err := token.SignedString(key.signKey)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		return "", nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
 	}
 
-	return signedToken, nil
+	return signedToken, claims, nil
 }
 
 func (s *TokenService) VerifyAccessToken(tokenString string) (*Claims, error) {
-	return s.verifyToken(tokenString, s.config.AccessTokenSecret)
+	return s.verifyToken(tokenString, AccessToken, s.config.AccessKeys)
 }
 
+// VerifyRefreshToken checks a refresh token's signature and type. Whether it
+// is still the session's current token is up to the caller.
 func (s *TokenService) VerifyRefreshToken(tokenString string) (*Claims, error) {
-	return s.verifyToken(tokenString, s.config.RefreshTokenSecret)
+	claims, err := s.verifyToken(tokenString, RefreshToken, s.config.RefreshKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, fmt.Errorf("%w: refresh token is not bound to a session", ErrInvalidToken)
+	}
+
+	return claims, nil
 }
 
-func (s *TokenService) verifyToken(tokenString, secret string) (*Claims, error) {
+// verifyToken checks the token against the key its kid header names and
+// rejects tokens of another type.
+func (s *TokenService) verifyToken(tokenString string, tokenType TokenType, keys KeySet) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := keys.key(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("%w: unexpected signing method: %v", ErrInvalidToken, token.Header["alg"])
 		}
 
-		return []byte(secret), nil
+		return key.verifyKey, nil
 	})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -127,28 +185,9 @@ func (s *TokenService) verifyToken(tokenString, secret string) (*Claims, error)
 		return nil, ErrInvalidClaims
 	}
 
-	return claims, nil
-}
-
-func (s *TokenService) RefreshTokens(refreshToken string) (accessToken string, newRefreshToken string, err error) {
-	claims, err := s.VerifyRefreshToken(refreshToken)
-	if err != nil {
-		return "", "", err
-	}
-
-	if claims.TokenType != RefreshToken {
-		return "", "", fmt.Errorf("%w: not a refresh token", ErrInvalidToken)
+	if claims.TokenType != tokenType {
+		return nil, fmt.Errorf("%w: expected a %s token", ErrInvalidToken, tokenType)
 	}
 
-	accessToken, err = s.GenerateAccessToken(claims.UserID, claims.VendorID)
-	if err != nil {
-		return "", "", err
-	}
-
-	newRefreshToken, err = s.GenerateRefreshToken(claims.UserID, claims.VendorID)
-	if err != nil {
-		return "", "", err
-	}
-
-	return accessToken, newRefreshToken, nil
+	return claims, nil
 }
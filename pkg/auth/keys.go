@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidKey      = errors.New("invalid signing key")
+	ErrKeysNotDistinct = errors.New("access and refresh tokens must be signed with different keys")
+)
+
+type Algorithm string
+
+const (
+	// AlgorithmHS256 signs with a shared secret.
+	AlgorithmHS256 Algorithm = "HS256"
+	// AlgorithmEdDSA signs with an Ed25519 private key.
+	AlgorithmEdDSA Algorithm = "EdDSA"
+)
+
+// minHMACSecretLength is the shortest secret HS256 keys accept, in bytes.
+const minHMACSecretLength = 32
+
+// Key is a signing key identified by the kid header of the tokens it signs.
+type Key struct {
+	ID        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	material  []byte
+}
+
+// NewKey builds a key for the algorithm. For HS256 material is the secret;
+// for EdDSA it is a base64-encoded 32-byte Ed25519 seed.
+func NewKey(id string, alg Algorithm, material string) (Key, error) {
+	switch alg {
+	case AlgorithmHS256:
+		return NewHMACKey(id, material)
+	case AlgorithmEdDSA:
+		seed, err := base64.StdEncoding.DecodeString(material)
+		if err != nil {
+			return Key{}, fmt.Errorf("%w: %s: seed is not base64: %v", ErrInvalidKey, id, err)
+		}
+		return NewEd25519Key(id, seed)
+	default:
+		return Key{}, fmt.Errorf("%w: %s: unsupported algorithm %q", ErrInvalidKey, id, alg)
+	}
+}
+
+func NewHMACKey(id, secret string) (Key, error) {
+	if id == "" {
+		return Key{}, fmt.Errorf("%w: key ID is empty", ErrInvalidKey)
+	}
+	if len(secret) < minHMACSecretLength {
+		return Key{}, fmt.Errorf("%w: %s: secret must be at least %d bytes", ErrInvalidKey, id, minHMACSecretLength)
+	}
+
+	return hmacKey(id, secret), nil
+}
+
+func hmacKey(id, secret string) Key {
+	return Key{
+		ID:        id,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+		material:  []byte(secret),
+	}
+}
+
+func NewEd25519Key(id string, seed []byte) (Key, error) {
+	if id == "" {
+		return Key{}, fmt.Errorf("%w: key ID is empty", ErrInvalidKey)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return Key{}, fmt.Errorf("%w: %s: seed must be %d bytes", ErrInvalidKey, id, ed25519.SeedSize)
+	}
+
+	private := ed25519.NewKeyFromSeed(seed)
+	public := private.Public().(ed25519.PublicKey)
+
+	return Key{
+		ID:        id,
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   private,
+		verifyKey: public,
+		material:  public,
+	}, nil
+}
+
+// KeySet holds the key new tokens are signed with and the keys being rotated
+// out, which still verify tokens they signed until those expire.
+type KeySet struct {
+	Signing  Key
+	Previous []Key
+}
+
+func NewKeySet(signing Key, previous ...Key) KeySet {
+	return KeySet{
+		Signing:  signing,
+		Previous: previous,
+	}
+}
+
+func (ks KeySet) key(id string) (Key, bool) {
+	if id == "" {
+		return Key{}, false
+	}
+	if ks.Signing.ID == id {
+		return ks.Signing, true
+	}
+	for _, k := range ks.Previous {
+		if k.ID == id {
+			return k, true
+		}
+	}
+
+	return Key{}, false
+}
+
+func (ks KeySet) validate() error {
+	if ks.Signing.method == nil {
+		return fmt.Errorf("%w: signing key is not set", ErrInvalidKey)
+	}
+
+	seen := map[string]struct{}{ks.Signing.ID: {}}
+	for _, k := range ks.Previous {
+		if k.method == nil {
+			return fmt.Errorf("%w: %s: key is not set", ErrInvalidKey, k.ID)
+		}
+		if _, ok := seen[k.ID]; ok {
+			return fmt.Errorf("%w: duplicate key ID %s", ErrInvalidKey, k.ID)
+		}
+		seen[k.ID] = struct{}{}
+	}
+
+	return nil
+}
+
+// shares reports whether any key of ks is also in other, by ID or material.
+func (ks KeySet) shares(other KeySet) bool {
+	for _, a := range append([]Key{ks.Signing}, ks.Previous...) {
+		for _, b := range append([]Key{other.Signing}, other.Previous...) {
+			if a.ID == b.ID || bytes.Equal(a.material, b.material) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
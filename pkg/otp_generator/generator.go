@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/pquerna/otp"
@@ -14,13 +15,17 @@ import (
 
 const (
 	DefaultIssuer = "Aroma"
+
+	// DefaultCodeAlphabet leaves out characters that are easy to confuse when typed by hand.
+	DefaultCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
 )
 
 var (
-	ErrGeneratingOTP = errors.New("error generating OTP code")
-	ErrValidatingOTP = errors.New("error validating OTP code")
-	ErrInvalidOTP    = errors.New("invalid OTP code")
-	ErrExpiredOTP    = errors.New("OTP code has expired")
+	ErrGeneratingOTP   = errors.New("error generating OTP code")
+	ErrValidatingOTP   = errors.New("error validating OTP code")
+	ErrInvalidOTP      = errors.New("invalid OTP code")
+	ErrExpiredOTP      = errors.New("OTP code has expired")
+	ErrInvalidAlphabet = errors.New("alphabet must contain at least two characters")
 )
 
 type Config struct {
@@ -91,6 +96,33 @@ func (g *Generator) GenerateNumericOTP() (string, error) {
 	return fmt.Sprintf("%06d", n.Int64()), nil
 }
 
+func (g *Generator) GenerateCode(prefix, alphabet string, length int) (string, error) {
+	if alphabet == "" {
+		alphabet = DefaultCodeAlphabet
+	}
+
+	symbols := []rune(alphabet)
+	if len(symbols) < 2 {
+		return "", ErrInvalidAlphabet
+	}
+
+	max := big.NewInt(int64(len(symbols)))
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrGeneratingOTP, err)
+		}
+
+		sb.WriteRune(symbols[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
 func (g *Generator) ValidateOTP(secret, code string) error {
 	valid, err := totp.ValidateCustom(
 		code,
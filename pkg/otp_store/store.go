@@ -0,0 +1,215 @@
+package otp_store
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidCode     = errors.New("invalid or expired code")
+	ErrLocked          = errors.New("too many failed attempts")
+	ErrTooManyAttempts = errors.New("too many attempts from this address")
+	ErrAccountRequired = errors.New("account is required")
+)
+
+type Config struct {
+	// CodeTTL is how long an issued code stays valid.
+	CodeTTL time.Duration
+	// MaxFailures is how many wrong codes an account may enter before it is
+	// locked for LockoutDuration. Issuing a new code does not reset the count.
+	MaxFailures     int
+	LockoutDuration time.Duration
+	// MaxIPAttempts is how many codes one address may try per IPWindow,
+	// whichever accounts they are for.
+	MaxIPAttempts int
+	IPWindow      time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		CodeTTL:         5 * time.Minute,
+		MaxFailures:     5,
+		LockoutDuration: 15 * time.Minute,
+		MaxIPAttempts:   20,
+		IPWindow:        15 * time.Minute,
+	}
+}
+
+type code struct {
+	hash      [sha256.Size]byte
+	expiresAt time.Time
+}
+
+type account struct {
+	failures    int
+	failedAt    time.Time
+	lockedUntil time.Time
+}
+
+type window struct {
+	attempts int
+	resetsAt time.Time
+}
+
+// Store keeps one-time login codes in memory, one per account. A code works
+// once, and wrong guesses lock the account and throttle the address they
+// come from.
+type Store struct {
+	mu       sync.Mutex
+	config   Config
+	clock    Clock
+	codes    map[string]code
+	accounts map[string]*account
+	ips      map[string]*window
+}
+
+func NewStore(config Config, clock Clock) *Store {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return &Store{
+		config:   config,
+		clock:    clock,
+		codes:    make(map[string]code),
+		accounts: make(map[string]*account),
+		ips:      make(map[string]*window),
+	}
+}
+
+func NewDefaultStore() *Store {
+	return NewStore(DefaultConfig(), SystemClock{})
+}
+
+// Issue makes value the account's only valid code, replacing any earlier
+// one. Locked accounts get no code.
+func (s *Store) Issue(accountID, value string) error {
+	if accountID == "" {
+		return ErrAccountRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.prune(now)
+
+	if err := s.checkLocked(accountID, now); err != nil {
+		return err
+	}
+
+	s.codes[accountID] = code{
+		hash:      sha256.Sum256([]byte(value)),
+		expiresAt: now.Add(s.config.CodeTTL),
+	}
+
+	return nil
+}
+
+// Verify consumes the account's code when value matches it. ip is the
+// address the attempt comes from.
+func (s *Store) Verify(accountID, ip, value string) error {
+	if accountID == "" {
+		return ErrAccountRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.prune(now)
+
+	if err := s.countIPAttempt(ip, now); err != nil {
+		return err
+	}
+	if err := s.checkLocked(accountID, now); err != nil {
+		return err
+	}
+
+	c, ok := s.codes[accountID]
+	hash := sha256.Sum256([]byte(value))
+	if !ok || !now.Before(c.expiresAt) || subtle.ConstantTimeCompare(hash[:], c.hash[:]) != 1 {
+		return s.fail(accountID, now)
+	}
+
+	delete(s.codes, accountID)
+	delete(s.accounts, accountID)
+
+	return nil
+}
+
+func (s *Store) countIPAttempt(ip string, now time.Time) error {
+	if s.config.MaxIPAttempts <= 0 {
+		return nil
+	}
+
+	w, ok := s.ips[ip]
+	if !ok || !now.Before(w.resetsAt) {
+		w = &window{resetsAt: now.Add(s.config.IPWindow)}
+		s.ips[ip] = w
+	}
+
+	if w.attempts >= s.config.MaxIPAttempts {
+		return fmt.Errorf("%w: retry in %s", ErrTooManyAttempts, w.resetsAt.Sub(now).Round(time.Second))
+	}
+	w.attempts++
+
+	return nil
+}
+
+func (s *Store) checkLocked(accountID string, now time.Time) error {
+	a, ok := s.accounts[accountID]
+	if ok && now.Before(a.lockedUntil) {
+		return fmt.Errorf("%w: retry in %s", ErrLocked, a.lockedUntil.Sub(now).Round(time.Second))
+	}
+
+	return nil
+}
+
+// fail counts a wrong code against the account and locks it, burning its
+// code, once it reaches MaxFailures.
+func (s *Store) fail(accountID string, now time.Time) error {
+	a, ok := s.accounts[accountID]
+	if !ok {
+		a = &account{}
+		s.accounts[accountID] = a
+	}
+
+	a.failures++
+	a.failedAt = now
+	if s.config.MaxFailures > 0 && a.failures >= s.config.MaxFailures {
+		a.failures = 0
+		a.lockedUntil = now.Add(s.config.LockoutDuration)
+		delete(s.codes, accountID)
+
+		return fmt.Errorf("%w: retry in %s", ErrLocked, s.config.LockoutDuration)
+	}
+
+	return ErrInvalidCode
+}
+
+// prune drops expired codes, lapsed lockouts and finished address windows so
+// the maps don't grow without bound.
+func (s *Store) prune(now time.Time) {
+	for id, c := range s.codes {
+		if !now.Before(c.expiresAt) {
+			delete(s.codes, id)
+		}
+	}
+	// Failures are forgotten once the account has gone a lockout period
+	// without one, so occasional typos never add up to a lockout.
+	for id, a := range s.accounts {
+		if !now.Before(a.lockedUntil) && !now.Before(a.failedAt.Add(s.config.LockoutDuration)) {
+			delete(s.accounts, id)
+		}
+	}
+	for ip, w := range s.ips {
+		if !now.Before(w.resetsAt) {
+			delete(s.ips, ip)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package otp_store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testIP = "203.0.113.7"
+
+func testConfig() Config {
+	return Config{
+		CodeTTL:         5 * time.Minute,
+		MaxFailures:     3,
+		LockoutDuration: 15 * time.Minute,
+		MaxIPAttempts:   10,
+		IPWindow:        15 * time.Minute,
+	}
+}
+
+func newTestStore(t *testing.T, cfg Config) (*Store, *FakeClock) {
+	t.Helper()
+
+	clock := NewFakeClock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+
+	return NewStore(cfg, clock), clock
+}
+
+func mustIssue(t *testing.T, s *Store, accountID, value string) {
+	t.Helper()
+
+	if err := s.Issue(accountID, value); err != nil {
+		t.Fatalf("Issue(%q): %v", accountID, err)
+	}
+}
+
+func TestCodeWorksOnce(t *testing.T) {
+	s, _ := newTestStore(t, testConfig())
+	mustIssue(t, s, "alice", "123456")
+
+	if err := s.Verify("alice", testIP, "123456"); err != nil {
+		t.Fatalf("first Verify: %v", err)
+	}
+	if err := s.Verify("alice", testIP, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("second Verify = %v, want %v", err, ErrInvalidCode)
+	}
+}
+
+func TestIssueReplacesEarlierCode(t *testing.T) {
+	s, _ := newTestStore(t, testConfig())
+	mustIssue(t, s, "alice", "111111")
+	mustIssue(t, s, "alice", "222222")
+
+	if err := s.Verify("alice", testIP, "111111"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Verify old code = %v, want %v", err, ErrInvalidCode)
+	}
+	if err := s.Verify("alice", testIP, "222222"); err != nil {
+		t.Fatalf("Verify new code: %v", err)
+	}
+}
+
+func TestCodeExpiresAfterTTL(t *testing.T) {
+	cfg := testConfig()
+
+	s, clock := newTestStore(t, cfg)
+	mustIssue(t, s, "alice", "123456")
+	clock.Advance(cfg.CodeTTL - time.Second)
+	if err := s.Verify("alice", testIP, "123456"); err != nil {
+		t.Fatalf("Verify before expiry: %v", err)
+	}
+
+	s, clock = newTestStore(t, cfg)
+	mustIssue(t, s, "alice", "123456")
+	clock.Advance(cfg.CodeTTL)
+	if err := s.Verify("alice", testIP, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Verify at expiry = %v, want %v", err, ErrInvalidCode)
+	}
+}
+
+func TestLockoutAfterMaxFailures(t *testing.T) {
+	cfg := testConfig()
+	s, clock := newTestStore(t, cfg)
+	mustIssue(t, s, "alice", "123456")
+
+	for i := 1; i < cfg.MaxFailures; i++ {
+		if err := s.Verify("alice", testIP, "000000"); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("failure %d = %v, want %v", i, err, ErrInvalidCode)
+		}
+	}
+	if err := s.Verify("alice", testIP, "000000"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("failure %d = %v, want %v", cfg.MaxFailures, err, ErrLocked)
+	}
+
+	// The right code does not help while locked, and no new one is issued.
+	if err := s.Verify("alice", testIP, "123456"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Verify while locked = %v, want %v", err, ErrLocked)
+	}
+	if err := s.Issue("alice", "654321"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Issue while locked = %v, want %v", err, ErrLocked)
+	}
+
+	clock.Advance(cfg.LockoutDuration - time.Second)
+	if err := s.Issue("alice", "654321"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Issue just before lockout ends = %v, want %v", err, ErrLocked)
+	}
+
+	clock.Advance(time.Second)
+	mustIssue(t, s, "alice", "654321")
+
+	// The lockout burnt the code issued before it.
+	if err := s.Verify("alice", testIP, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Verify burnt code = %v, want %v", err, ErrInvalidCode)
+	}
+	if err := s.Verify("alice", testIP, "654321"); err != nil {
+		t.Fatalf("Verify after lockout: %v", err)
+	}
+}
+
+func TestFailuresAreForgottenAfterQuietPeriod(t *testing.T) {
+	cfg := testConfig()
+	s, clock := newTestStore(t, cfg)
+
+	for i := 1; i < cfg.MaxFailures; i++ {
+		if err := s.Verify("alice", testIP, "000000"); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("failure %d = %v, want %v", i, err, ErrInvalidCode)
+		}
+	}
+
+	clock.Advance(cfg.LockoutDuration)
+	if err := s.Verify("alice", testIP, "000000"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("failure after quiet period = %v, want %v", err, ErrInvalidCode)
+	}
+}
+
+func TestIPWindow(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxFailures = 0
+	s, clock := newTestStore(t, cfg)
+
+	for i := range cfg.MaxIPAttempts {
+		if err := s.Verify("alice", testIP, "000000"); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("attempt %d = %v, want %v", i+1, err, ErrInvalidCode)
+		}
+	}
+
+	// The address is throttled whichever account it tries next.
+	mustIssue(t, s, "bob", "123456")
+	if err := s.Verify("bob", testIP, "123456"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("attempt over the limit = %v, want %v", err, ErrTooManyAttempts)
+	}
+
+	// Other addresses are not.
+	if err := s.Verify("bob", "198.51.100.1", "123456"); err != nil {
+		t.Fatalf("Verify from another address: %v", err)
+	}
+
+	clock.Advance(cfg.IPWindow)
+	mustIssue(t, s, "bob", "123456")
+	if err := s.Verify("bob", testIP, "123456"); err != nil {
+		t.Fatalf("Verify after the window: %v", err)
+	}
+}
+
+func TestAccountsDoNotCollide(t *testing.T) {
+	cfg := testConfig()
+	s, _ := newTestStore(t, cfg)
+	mustIssue(t, s, "alice", "111111")
+	mustIssue(t, s, "bob", "222222")
+
+	if err := s.Verify("alice", testIP, "222222"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Verify with another account's code = %v, want %v", err, ErrInvalidCode)
+	}
+
+	// Locking one account leaves the other alone.
+	for i := 1; i < cfg.MaxFailures; i++ {
+		_ = s.Verify("alice", testIP, "000000")
+	}
+	if err := s.Verify("alice", testIP, "000000"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("locking alice = %v, want %v", err, ErrLocked)
+	}
+
+	if err := s.Verify("bob", testIP, "222222"); err != nil {
+		t.Fatalf("Verify bob: %v", err)
+	}
+}
+
+func TestAccountRequired(t *testing.T) {
+	s, _ := newTestStore(t, testConfig())
+
+	if err := s.Issue("", "123456"); !errors.Is(err, ErrAccountRequired) {
+		t.Fatalf("Issue = %v, want %v", err, ErrAccountRequired)
+	}
+	if err := s.Verify("", testIP, "123456"); !errors.Is(err, ErrAccountRequired) {
+		t.Fatalf("Verify = %v, want %v", err, ErrAccountRequired)
+	}
+}
@@ -0,0 +1,264 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Document is a minimal PDF writer: A4 pages with text in one embedded
+// TrueType font and straight lines. Coordinates are in points from the top
+// left corner of the page.
+type Document struct {
+	font  *Font
+	pages []*bytes.Buffer
+	used  map[uint16]rune
+}
+
+func New(font *Font) *Document {
+	return &Document{
+		font: font,
+		used: make(map[uint16]rune),
+	}
+}
+
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y, size float64, s string) {
+	var hex strings.Builder
+	for _, r := range s {
+		g := d.font.glyph(r)
+		if _, ok := d.used[g]; !ok {
+			d.used[g] = r
+		}
+		fmt.Fprintf(&hex, "%04X", g)
+	}
+
+	fmt.Fprintf(d.page(), "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, PageHeight-y, hex.String())
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.Text(x-d.font.TextWidth(s, size), y, size, s)
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+func (d *Document) TextWidth(s string, size float64) float64 {
+	return d.font.TextWidth(s, size)
+}
+
+// Truncate shortens s with an ellipsis so it fits in width at size points.
+func (d *Document) Truncate(s string, size, width float64) string {
+	if d.font.TextWidth(s, size) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if d.font.TextWidth(candidate, size) <= width {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// Bytes serialises the document.
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed object numbers; pages and their contents follow.
+	const (
+		catalogObj = iota + 1
+		pagesObj
+		fontObj
+		cidFontObj
+		descriptorObj
+		fontFileObj
+		toUnicodeObj
+		firstPageObj
+	)
+
+	glyphs := d.sortedGlyphs()
+	fontFile := d.font.subset(glyphs)
+	fontName := subsetTag(glyphs) + "+EmbeddedFont"
+
+	w.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+	w.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	w.object(fontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		fontName, cidFontObj, toUnicodeObj,
+	))
+	w.object(cidFontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		fontName, descriptorObj, d.widths(),
+	))
+	w.object(descriptorObj, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] "+
+			"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		fontName, d.font.scale(d.font.bbox[0]), d.font.scale(d.font.bbox[1]),
+		d.font.scale(d.font.bbox[2]), d.font.scale(d.font.bbox[3]),
+		d.font.scale(d.font.ascent), d.font.scale(d.font.descent), d.font.scale(d.font.ascent),
+		fontFileObj,
+	))
+	if err := w.stream(fontFileObj, fmt.Sprintf("/Length1 %d", len(fontFile)), fontFile); err != nil {
+		return nil, err
+	}
+	if err := w.stream(toUnicodeObj, "", []byte(d.toUnicode())); err != nil {
+		return nil, err
+	}
+
+	for i, content := range d.pages {
+		pageObj := firstPageObj + i*2
+		w.object(pageObj, fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, PageWidth, PageHeight, fontObj, pageObj+1,
+		))
+		if err := w.stream(pageObj+1, "", content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	w.trailer(catalogObj)
+
+	return w.buf.Bytes(), nil
+}
+
+func (d *Document) sortedGlyphs() []uint16 {
+	glyphs := make([]uint16, 0, len(d.used))
+	for g := range d.used {
+		glyphs = append(glyphs, g)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	return glyphs
+}
+
+func (d *Document) widths() string {
+	var sb strings.Builder
+	for _, g := range d.sortedGlyphs() {
+		fmt.Fprintf(&sb, "%d [%d] ", g, d.font.advance(g))
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// toUnicode maps glyphs back to text so it can be searched and copied.
+func (d *Document) toUnicode() string {
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	sb.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	sb.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	sb.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	glyphs := d.sortedGlyphs()
+	for start := 0; start < len(glyphs); start += 100 {
+		chunk := glyphs[start:min(start+100, len(glyphs))]
+
+		fmt.Fprintf(&sb, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&sb, "<%04X> <%s>\n", g, utf16Hex(d.used[g]))
+		}
+		sb.WriteString("endbfchar\n")
+	}
+
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return sb.String()
+}
+
+func utf16Hex(r rune) string {
+	if r < 0x10000 {
+		return fmt.Sprintf("%04X", r)
+	}
+
+	r -= 0x10000
+	return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+}
+
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) begin(num int) {
+	for len(w.offsets) < num {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[num-1] = w.buf.Len()
+
+	fmt.Fprintf(&w.buf, "%d 0 obj\n", num)
+}
+
+func (w *writer) object(num int, body string) {
+	w.begin(num)
+	w.buf.WriteString(body)
+	w.buf.WriteString("\nendobj\n")
+}
+
+// stream writes data deflated, with extra entries added to its dictionary.
+func (w *writer) stream(num int, extra string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("compressing pdf stream: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compressing pdf stream: %w", err)
+	}
+
+	w.begin(num)
+	fmt.Fprintf(&w.buf, "<< /Length %d /Filter /FlateDecode %s >>\nstream\n", compressed.Len(), extra)
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+
+	return nil
+}
+
+func (w *writer) trailer(root int) {
+	xref := w.buf.Len()
+
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, xref)
+}
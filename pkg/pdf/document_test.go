@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var streamRe = regexp.MustCompile(`/Length (\d+) /Filter /FlateDecode [^>]*>>\nstream\n`)
+
+// streams inflates every stream in a document.
+func streams(t *testing.T, doc []byte) [][]byte {
+	t.Helper()
+
+	var out [][]byte
+	for _, m := range streamRe.FindAllSubmatchIndex(doc, -1) {
+		length, err := strconv.Atoi(string(doc[m[2]:m[3]]))
+		if err != nil {
+			t.Fatalf("stream length: %v", err)
+		}
+
+		zr, err := zlib.NewReader(bytes.NewReader(doc[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("opening stream: %v", err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("inflating stream: %v", err)
+		}
+
+		out = append(out, data)
+	}
+
+	return out
+}
+
+func TestDocumentBytes(t *testing.T) {
+	doc := New(testFont(t))
+	doc.Text(40, 60, 12, "AЖ")
+	doc.TextRight(200, 80, 10, "A")
+	doc.Line(40, 90, 200, 90, 0.5)
+	doc.AddPage()
+	doc.Text(40, 60, 12, "Ä")
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("document is not framed as a PDF file")
+	}
+	if !bytes.Contains(data, []byte("/Count 2")) {
+		t.Error("document does not have two pages")
+	}
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+EmbeddedFont`).Match(data) {
+		t.Error("embedded font is not named as a subset")
+	}
+
+	// Every cross-reference entry points at the object it numbers.
+	xref := bytes.LastIndex(data, []byte("\nxref\n")) + 1
+	if !bytes.Contains(data, []byte("startxref\n"+strconv.Itoa(xref)+"\n")) {
+		t.Fatal("startxref does not point at the xref table")
+	}
+	lines := strings.Split(string(data[xref:]), "\n")
+	for i, line := range lines[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+
+		offset, err := strconv.Atoi(line[:10])
+		if err != nil {
+			t.Fatalf("xref entry %q: %v", line, err)
+		}
+		if header := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[offset:offset+10])
+		}
+	}
+
+	var content, toUnicode string
+	var fontFile []byte
+	for _, s := range streams(t, data) {
+		switch {
+		case bytes.Contains(s, []byte(" Tj ")) && content == "":
+			content = string(s)
+		case bytes.Contains(s, []byte("beginbfchar")):
+			toUnicode = string(s)
+		case bytes.HasPrefix(s, []byte{0, 1, 0, 0}):
+			fontFile = s
+		}
+	}
+
+	if !strings.Contains(content, "BT /F1 12.00 Tf 40.00 781.89 Td <00010002> Tj ET") {
+		t.Errorf("first page does not draw AЖ:\n%s", content)
+	}
+	if !strings.Contains(content, "0.50 w 40.00 751.89 m 200.00 751.89 l S") {
+		t.Errorf("first page does not draw the line:\n%s", content)
+	}
+	for _, m := range []string{"<0001> <0041>", "<0002> <0416>", "<0003> <00C4>"} {
+		if !strings.Contains(toUnicode, m) {
+			t.Errorf("ToUnicode map lacks %s:\n%s", m, toUnicode)
+		}
+	}
+
+	font, err := ParseTrueType(fontFile)
+	if err != nil {
+		t.Fatalf("parsing the embedded font: %v", err)
+	}
+	if len(font.glyphData(glyphDiaeresis)) == 0 {
+		t.Error("embedded font lost a component of Ä")
+	}
+	if len(font.glyphData(glyphZ)) != 0 {
+		t.Error("embedded font has a glyph no text uses")
+	}
+}
+
+func TestDocumentWidths(t *testing.T) {
+	doc := New(testFont(t))
+	doc.Text(0, 0, 10, "ЖA")
+
+	if got, want := doc.widths(), "1 [600] 2 [700]"; got != want {
+		t.Fatalf("widths = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	doc := New(testFont(t))
+
+	// At 10 points A is 6 wide and the ellipsis, a missing glyph, is 5.
+	tests := []struct {
+		width float64
+		want  string
+	}{
+		{24, "AAAA"},
+		{20, "AA…"},
+		{4, ""},
+	}
+
+	for _, tt := range tests {
+		if got := doc.Truncate("AAAA", 10, tt.width); got != tt.want {
+			t.Errorf("Truncate to %v = %q, want %q", tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidFont     = errors.New("invalid TrueType font")
+	ErrNoUnicodeCmap   = errors.New("font has no Unicode character map")
+	ErrUnsupportedCmap = errors.New("unsupported character map format")
+)
+
+// Font is a TrueType font parsed just enough to lay out and embed text:
+// the Unicode character map, glyph advances, outline locations and a few
+// metrics.
+type Font struct {
+	data       []byte
+	tables     map[string]tableRecord
+	unitsPerEm uint16
+	ascent     int16
+	descent    int16
+	bbox       [4]int16
+	advances   []uint16
+	glyphs     map[rune]uint16
+	numGlyphs  int
+	glyf       []byte
+	loca       []uint32
+}
+
+type tableRecord struct {
+	offset uint32
+	length uint32
+}
+
+// ParseTrueType reads a .ttf file with TrueType outlines. Documents embed a
+// subset of it with only the glyphs they use.
+func ParseTrueType(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+numTables*16 {
+		return nil, ErrInvalidFont
+	}
+
+	tables := make(map[string]tableRecord, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := data[12+i*16:]
+		t := tableRecord{
+			offset: binary.BigEndian.Uint32(rec[8:]),
+			length: binary.BigEndian.Uint32(rec[12:]),
+		}
+		if uint64(t.offset)+uint64(t.length) > uint64(len(data)) {
+			return nil, ErrInvalidFont
+		}
+
+		tables[string(rec[:4])] = t
+	}
+
+	table := func(tag string, minLen int) ([]byte, error) {
+		t, ok := tables[tag]
+		if !ok || int(t.length) < minLen {
+			return nil, fmt.Errorf("%w: missing or short %q table", ErrInvalidFont, tag)
+		}
+
+		return data[t.offset : t.offset+t.length], nil
+	}
+
+	head, err := table("head", 54)
+	if err != nil {
+		return nil, err
+	}
+	hhea, err := table("hhea", 36)
+	if err != nil {
+		return nil, err
+	}
+	hmtx, err := table("hmtx", 0)
+	if err != nil {
+		return nil, err
+	}
+	cmap, err := table("cmap", 4)
+	if err != nil {
+		return nil, err
+	}
+	maxp, err := table("maxp", 6)
+	if err != nil {
+		return nil, err
+	}
+	loca, err := table("loca", 0)
+	if err != nil {
+		return nil, err
+	}
+	glyf, err := table("glyf", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Font{
+		data:       data,
+		tables:     tables,
+		numGlyphs:  int(binary.BigEndian.Uint16(maxp[4:])),
+		glyf:       glyf,
+		unitsPerEm: binary.BigEndian.Uint16(head[18:]),
+		ascent:     int16(binary.BigEndian.Uint16(hhea[4:])),
+		descent:    int16(binary.BigEndian.Uint16(hhea[6:])),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("%w: zero units per em", ErrInvalidFont)
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+i*2:]))
+	}
+
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	if len(hmtx) < numHMetrics*4 {
+		return nil, fmt.Errorf("%w: short hmtx table", ErrInvalidFont)
+	}
+	f.advances = make([]uint16, numHMetrics)
+	for i := range f.advances {
+		f.advances[i] = binary.BigEndian.Uint16(hmtx[i*4:])
+	}
+
+	f.glyphs, err = parseCmap(cmap)
+	if err != nil {
+		return nil, err
+	}
+
+	locaFormat := int16(binary.BigEndian.Uint16(head[50:]))
+	f.loca, err = parseLoca(loca, locaFormat, f.numGlyphs, len(glyf))
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// parseLoca reads where each glyph's outline starts in the glyf table; the
+// extra last entry is where the last outline ends.
+func parseLoca(loca []byte, format int16, numGlyphs, glyfLen int) ([]uint32, error) {
+	offsets := make([]uint32, numGlyphs+1)
+	for i := range offsets {
+		switch format {
+		case 0:
+			if len(loca) < (i+1)*2 {
+				return nil, fmt.Errorf("%w: short loca table", ErrInvalidFont)
+			}
+			offsets[i] = uint32(binary.BigEndian.Uint16(loca[i*2:])) * 2
+		case 1:
+			if len(loca) < (i+1)*4 {
+				return nil, fmt.Errorf("%w: short loca table", ErrInvalidFont)
+			}
+			offsets[i] = binary.BigEndian.Uint32(loca[i*4:])
+		default:
+			return nil, fmt.Errorf("%w: unknown loca format %d", ErrInvalidFont, format)
+		}
+
+		if offsets[i] > uint32(glyfLen) || (i > 0 && offsets[i] < offsets[i-1]) {
+			return nil, fmt.Errorf("%w: glyph %d is out of the glyf table", ErrInvalidFont, i)
+		}
+	}
+
+	return offsets, nil
+}
+
+// parseCmap picks the best Unicode subtable: full repertoire (format 12)
+// first, then the Basic Multilingual Plane (format 4).
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	numSubtables := int(binary.BigEndian.Uint16(cmap[2:]))
+	if len(cmap) < 4+numSubtables*8 {
+		return nil, ErrInvalidFont
+	}
+
+	var bmp, full int = -1, -1
+	for i := 0; i < numSubtables; i++ {
+		rec := cmap[4+i*8:]
+		platform := binary.BigEndian.Uint16(rec)
+		encoding := binary.BigEndian.Uint16(rec[2:])
+		offset := int(binary.BigEndian.Uint32(rec[4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+
+		format := binary.BigEndian.Uint16(cmap[offset:])
+		switch {
+		case format == 12 && (platform == 0 || (platform == 3 && encoding == 10)):
+			full = offset
+		case format == 4 && (platform == 0 || (platform == 3 && (encoding == 1 || encoding == 0))):
+			bmp = offset
+		}
+	}
+
+	switch {
+	case full >= 0:
+		return parseCmapFormat12(cmap[full:])
+	case bmp >= 0:
+		return parseCmapFormat4(cmap[bmp:])
+	default:
+		return nil, ErrNoUnicodeCmap
+	}
+}
+
+func parseCmapFormat4(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 14 {
+		return nil, ErrUnsupportedCmap
+	}
+
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + segCount*2 + 2
+	idDeltas := startCodes + segCount*2
+	idRangeOffsets := idDeltas + segCount*2
+	if len(sub) < idRangeOffsets+segCount*2 {
+		return nil, ErrUnsupportedCmap
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := int(binary.BigEndian.Uint16(sub[endCodes+i*2:]))
+		start := int(binary.BigEndian.Uint16(sub[startCodes+i*2:]))
+		delta := binary.BigEndian.Uint16(sub[idDeltas+i*2:])
+		rangeOffsetPos := idRangeOffsets + i*2
+		rangeOffset := int(binary.BigEndian.Uint16(sub[rangeOffsetPos:]))
+
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var g uint16
+			if rangeOffset == 0 {
+				g = uint16(c) + delta
+			} else {
+				addr := rangeOffsetPos + rangeOffset + (c-start)*2
+				if addr+2 > len(sub) {
+					continue
+				}
+				g = binary.BigEndian.Uint16(sub[addr:])
+				if g != 0 {
+					g += delta
+				}
+			}
+
+			if g != 0 {
+				glyphs[rune(c)] = g
+			}
+		}
+	}
+
+	return glyphs, nil
+}
+
+func parseCmapFormat12(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 16 {
+		return nil, ErrUnsupportedCmap
+	}
+
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	if len(sub) < 16+numGroups*12 {
+		return nil, ErrUnsupportedCmap
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < numGroups; i++ {
+		group := sub[16+i*12:]
+		start := binary.BigEndian.Uint32(group)
+		end := binary.BigEndian.Uint32(group[4:])
+		startGlyph := binary.BigEndian.Uint32(group[8:])
+
+		if end < start || end-start > 0x10000 {
+			continue
+		}
+		for c := start; c <= end; c++ {
+			glyphs[rune(c)] = uint16(startGlyph + (c - start))
+		}
+	}
+
+	return glyphs, nil
+}
+
+// glyph returns the glyph for r, falling back to the missing-glyph box.
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// advance returns the glyph's advance width in thousandths of the font size.
+func (f *Font) advance(g uint16) int {
+	if len(f.advances) == 0 {
+		return 0
+	}
+
+	idx := int(g)
+	if idx >= len(f.advances) {
+		idx = len(f.advances) - 1
+	}
+
+	return int(f.advances[idx]) * 1000 / int(f.unitsPerEm)
+}
+
+func (f *Font) scale(v int16) int {
+	return int(v) * 1000 / int(f.unitsPerEm)
+}
+
+// TextWidth returns the width of s set at size points.
+func (f *Font) TextWidth(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		total += f.advance(f.glyph(r))
+	}
+
+	return float64(total) * size / 1000
+}
@@ -0,0 +1,279 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// The test font has these glyphs; Ä is a composite of A and a diaeresis
+// that no character maps to, and Z is never drawn.
+const (
+	glyphNotdef = iota
+	glyphA
+	glyphZhe
+	glyphAUmlaut
+	glyphDiaeresis
+	glyphZ
+	testGlyphCount
+)
+
+var testCmap = map[rune]uint16{
+	'A': glyphA,
+	'Z': glyphZ,
+	'Ä': glyphAUmlaut,
+	'Ж': glyphZhe,
+}
+
+func simpleOutline(fill byte) []byte {
+	outline := make([]byte, 16)
+	binary.BigEndian.PutUint16(outline, 1)
+	for i := 10; i < len(outline); i++ {
+		outline[i] = fill
+	}
+
+	return outline
+}
+
+// compositeOutline references base with word arguments and a scale, then
+// mark with byte arguments, so both record lengths are walked.
+func compositeOutline(base, mark uint16) []byte {
+	outline := make([]byte, 10)
+	binary.BigEndian.PutUint16(outline, 0xFFFF)
+
+	outline = binary.BigEndian.AppendUint16(outline, argsAreWords|haveScale|moreComponents)
+	outline = binary.BigEndian.AppendUint16(outline, base)
+	outline = append(outline, 0, 0, 0, 0, 0x40, 0)
+
+	outline = binary.BigEndian.AppendUint16(outline, 0)
+	outline = binary.BigEndian.AppendUint16(outline, mark)
+	outline = append(outline, 0, 0)
+
+	return outline
+}
+
+// sameOutline reports whether got is want, padded to a four-byte boundary
+// as subsets lay outlines out.
+func sameOutline(got, want []byte) bool {
+	return bytes.HasPrefix(got, want) && len(got)-len(want) < 4 &&
+		len(bytes.Trim(got[len(want):], "\x00")) == 0
+}
+
+func testOutlines() [][]byte {
+	return [][]byte{
+		glyphNotdef:    simpleOutline(0x10),
+		glyphA:         simpleOutline(0x20),
+		glyphZhe:       simpleOutline(0x30),
+		glyphAUmlaut:   compositeOutline(glyphA, glyphDiaeresis),
+		glyphDiaeresis: simpleOutline(0x40),
+		glyphZ:         simpleOutline(0x50),
+	}
+}
+
+// testAdvance is glyph g's advance width in font units (1000 per em).
+func testAdvance(g uint16) uint16 {
+	return 500 + 100*g
+}
+
+// buildCmap writes a format 4 subtable with a segment per character.
+func buildCmap(platform, encoding uint16, cmap map[rune]uint16) []byte {
+	chars := []rune{'A', 'Z', 'Ä', 'Ж'}
+	segCount := len(chars) + 1
+
+	sub := binary.BigEndian.AppendUint16(nil, 4)
+	sub = binary.BigEndian.AppendUint16(sub, uint16(16+segCount*8))
+	sub = binary.BigEndian.AppendUint16(sub, 0)
+	sub = binary.BigEndian.AppendUint16(sub, uint16(segCount*2))
+	sub = append(sub, make([]byte, 6)...)
+	for _, c := range chars {
+		sub = binary.BigEndian.AppendUint16(sub, uint16(c))
+	}
+	sub = binary.BigEndian.AppendUint16(sub, 0xFFFF)
+	sub = binary.BigEndian.AppendUint16(sub, 0)
+	for _, c := range chars {
+		sub = binary.BigEndian.AppendUint16(sub, uint16(c))
+	}
+	sub = binary.BigEndian.AppendUint16(sub, 0xFFFF)
+	for _, c := range chars {
+		sub = binary.BigEndian.AppendUint16(sub, cmap[c]-uint16(c))
+	}
+	sub = binary.BigEndian.AppendUint16(sub, 1)
+	sub = append(sub, make([]byte, segCount*2)...)
+
+	table := binary.BigEndian.AppendUint16(nil, 0)
+	table = binary.BigEndian.AppendUint16(table, 1)
+	table = binary.BigEndian.AppendUint16(table, platform)
+	table = binary.BigEndian.AppendUint16(table, encoding)
+	table = binary.BigEndian.AppendUint32(table, 12)
+
+	return append(table, sub...)
+}
+
+// testTables returns the tables of a small font with short loca offsets.
+func testTables() map[string][]byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head[12:], 0x5F0F3CF5)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	for i, v := range []int16{0, -200, 1000, 800} {
+		binary.BigEndian.PutUint16(head[36+i*2:], uint16(v))
+	}
+
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint16(hhea[4:], 800)
+	binary.BigEndian.PutUint16(hhea[6:], uint16(0xFFFF-200+1))
+	binary.BigEndian.PutUint16(hhea[34:], testGlyphCount)
+
+	maxp := binary.BigEndian.AppendUint32(nil, 0x00005000)
+	maxp = binary.BigEndian.AppendUint16(maxp, testGlyphCount)
+
+	var hmtx, glyf, loca []byte
+	for g, outline := range testOutlines() {
+		hmtx = binary.BigEndian.AppendUint16(hmtx, testAdvance(uint16(g)))
+		hmtx = binary.BigEndian.AppendUint16(hmtx, 0)
+		loca = binary.BigEndian.AppendUint16(loca, uint16(len(glyf)/2))
+		glyf = append(glyf, outline...)
+	}
+	loca = binary.BigEndian.AppendUint16(loca, uint16(len(glyf)/2))
+
+	return map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"maxp": maxp,
+		"hmtx": hmtx,
+		"cmap": buildCmap(3, 1, testCmap),
+		"loca": loca,
+		"glyf": glyf,
+		"name": bytes.Repeat([]byte("not embedded "), 20),
+	}
+}
+
+func testFontData(t *testing.T) []byte {
+	t.Helper()
+
+	data, _ := writeFont(testTables())
+	return data
+}
+
+func testFont(t *testing.T) *Font {
+	t.Helper()
+
+	font, err := ParseTrueType(testFontData(t))
+	if err != nil {
+		t.Fatalf("ParseTrueType: %v", err)
+	}
+
+	return font
+}
+
+func TestParseTrueType(t *testing.T) {
+	font := testFont(t)
+
+	for r, want := range testCmap {
+		if g := font.glyph(r); g != want {
+			t.Errorf("glyph(%q) = %d, want %d", r, g, want)
+		}
+	}
+	if g := font.glyph('?'); g != glyphNotdef {
+		t.Errorf("glyph of an unmapped rune = %d, want the missing-glyph box", g)
+	}
+
+	if adv := font.advance(glyphA); adv != int(testAdvance(glyphA)) {
+		t.Errorf("advance(A) = %d, want %d", adv, testAdvance(glyphA))
+	}
+	if w := font.TextWidth("AЖ", 10); w != 13 {
+		t.Errorf("TextWidth = %v, want 13", w)
+	}
+	if font.scale(font.ascent) != 800 || font.scale(font.descent) != -200 {
+		t.Errorf("ascent, descent = %d, %d, want 800, -200", font.scale(font.ascent), font.scale(font.descent))
+	}
+
+	for g, outline := range testOutlines() {
+		if got := font.glyphData(uint16(g)); !bytes.Equal(got, outline) {
+			t.Errorf("outline of glyph %d = %x, want %x", g, got, outline)
+		}
+	}
+}
+
+func TestParseTrueTypeRejectsBrokenFonts(t *testing.T) {
+	withTables := func(change func(map[string][]byte)) []byte {
+		tables := testTables()
+		change(tables)
+
+		data, _ := writeFont(tables)
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrInvalidFont},
+		{"truncated directory", testFontData(t)[:20], ErrInvalidFont},
+		{"no outlines", withTables(func(m map[string][]byte) { delete(m, "glyf") }), ErrInvalidFont},
+		{"outline past glyf", withTables(func(m map[string][]byte) { m["glyf"] = m["glyf"][:20] }), ErrInvalidFont},
+		{"unknown loca format", withTables(func(m map[string][]byte) { m["head"][headLocaFormat+1] = 7 }), ErrInvalidFont},
+		{"no unicode cmap", withTables(func(m map[string][]byte) { m["cmap"] = buildCmap(1, 0, testCmap) }), ErrNoUnicodeCmap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTrueType(tt.data); !errors.Is(err, tt.want) {
+				t.Fatalf("ParseTrueType = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubset(t *testing.T) {
+	font := testFont(t)
+	original := testFontData(t)
+
+	data := font.subset([]uint16{glyphAUmlaut})
+
+	if sum := checksum(data); sum != checksumMagic {
+		t.Errorf("font checksum = %#x, want %#x", sum, uint32(checksumMagic))
+	}
+	if len(data) >= len(original) {
+		t.Errorf("subset is %d bytes, the font %d", len(data), len(original))
+	}
+
+	sub, err := ParseTrueType(data)
+	if err != nil {
+		t.Fatalf("parsing the subset: %v", err)
+	}
+	if _, ok := sub.tables["name"]; ok {
+		t.Error("subset kept the name table")
+	}
+
+	// The composite keeps its components and the missing-glyph box is
+	// always there; glyph IDs do not move.
+	outlines := testOutlines()
+	for _, g := range []uint16{glyphNotdef, glyphA, glyphAUmlaut, glyphDiaeresis} {
+		if got := sub.glyphData(g); !sameOutline(got, outlines[g]) {
+			t.Errorf("outline of glyph %d = %x, want %x", g, got, outlines[g])
+		}
+	}
+	for _, g := range []uint16{glyphZhe, glyphZ} {
+		if got := sub.glyphData(g); len(got) != 0 {
+			t.Errorf("unused glyph %d kept its outline %x", g, got)
+		}
+	}
+
+	if sub.glyph('Ж') != glyphZhe || sub.advance(glyphZ) != int(testAdvance(glyphZ)) {
+		t.Error("subset changed the character map or the advances")
+	}
+}
+
+func TestSubsetIgnoresGlyphsOutOfFont(t *testing.T) {
+	font := testFont(t)
+
+	sub, err := ParseTrueType(font.subset([]uint16{glyphA, testGlyphCount + 10}))
+	if err != nil {
+		t.Fatalf("parsing the subset: %v", err)
+	}
+	if sub.numGlyphs != testGlyphCount {
+		t.Fatalf("subset has %d glyphs, want %d", sub.numGlyphs, testGlyphCount)
+	}
+}
@@ -0,0 +1,203 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+)
+
+// Composite glyph flags that decide how long each component record is.
+const (
+	argsAreWords   = 0x0001
+	haveScale      = 0x0008
+	moreComponents = 0x0020
+	haveXYScale    = 0x0040
+	haveTwoByTwo   = 0x0080
+)
+
+const (
+	tableRecordSize = 16
+
+	// Offsets into the head table.
+	headAdjustment = 8
+	headLocaFormat = 50
+
+	// The whole font must sum to this once head's adjustment is set.
+	checksumMagic = 0xB1B0AFBA
+)
+
+// subsetTables are copied into a subset as they are; head, loca and glyf
+// are rebuilt. Hinting programs are kept so small text still renders well.
+var subsetTables = []string{"cmap", "cvt ", "fpgm", "hhea", "hmtx", "maxp", "prep"}
+
+// glyphData returns the outline of glyph g; blank glyphs have none.
+func (f *Font) glyphData(g uint16) []byte {
+	if int(g) >= f.numGlyphs {
+		return nil
+	}
+
+	return f.glyf[f.loca[g]:f.loca[g+1]]
+}
+
+// componentGlyphs lists the glyphs a composite outline is built from.
+func componentGlyphs(outline []byte) []uint16 {
+	if len(outline) < 10 || int16(binary.BigEndian.Uint16(outline)) >= 0 {
+		return nil
+	}
+
+	var components []uint16
+	for pos := 10; pos+4 <= len(outline); {
+		flags := binary.BigEndian.Uint16(outline[pos:])
+		components = append(components, binary.BigEndian.Uint16(outline[pos+2:]))
+
+		pos += 4
+		if flags&argsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			pos += 2
+		case flags&haveXYScale != 0:
+			pos += 4
+		case flags&haveTwoByTwo != 0:
+			pos += 8
+		}
+
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+
+	return components
+}
+
+// subset returns the font with outlines only for the given glyphs, the
+// missing-glyph box and the components they are built from. Glyph IDs stay
+// the same, so text drawn with the full font shows with the subset.
+func (f *Font) subset(glyphs []uint16) []byte {
+	keep := make(map[uint16]bool, len(glyphs)+1)
+	queue := append([]uint16{0}, glyphs...)
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		if keep[g] || int(g) >= f.numGlyphs {
+			continue
+		}
+
+		keep[g] = true
+		queue = append(queue, componentGlyphs(f.glyphData(g))...)
+	}
+
+	var glyf bytes.Buffer
+	loca := make([]byte, (f.numGlyphs+1)*4)
+	for g := 0; g < f.numGlyphs; g++ {
+		binary.BigEndian.PutUint32(loca[g*4:], uint32(glyf.Len()))
+		if keep[uint16(g)] {
+			glyf.Write(f.glyphData(uint16(g)))
+			for glyf.Len()%4 != 0 {
+				glyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[f.numGlyphs*4:], uint32(glyf.Len()))
+
+	headRec := f.tables["head"]
+	head := bytes.Clone(f.data[headRec.offset : headRec.offset+headRec.length])
+	binary.BigEndian.PutUint32(head[headAdjustment:], 0)
+	binary.BigEndian.PutUint16(head[headLocaFormat:], 1)
+
+	tables := map[string][]byte{
+		"head": head,
+		"loca": loca,
+		"glyf": glyf.Bytes(),
+	}
+	for _, tag := range subsetTables {
+		if t, ok := f.tables[tag]; ok {
+			tables[tag] = f.data[t.offset : t.offset+t.length]
+		}
+	}
+
+	font, headOffset := writeFont(tables)
+	binary.BigEndian.PutUint32(font[headOffset+headAdjustment:], checksumMagic-checksum(font))
+
+	return font
+}
+
+// writeFont lays tables out in a TrueType file and returns it with the
+// offset of the head table.
+func writeFont(tables map[string][]byte) ([]byte, int) {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(tags) {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * tableRecordSize
+
+	header := make([]byte, 12+len(tags)*tableRecordSize)
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(len(tags)))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(len(tags)*tableRecordSize-searchRange))
+
+	var body bytes.Buffer
+	headOffset := 0
+	for i, tag := range tags {
+		data := tables[tag]
+		offset := len(header) + body.Len()
+		if tag == "head" {
+			headOffset = offset
+		}
+
+		rec := header[12+i*tableRecordSize:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(offset))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(data)))
+
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	return append(header, body.Bytes()...), headOffset
+}
+
+// checksum sums data as big-endian 32-bit words, zero padding the tail.
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+
+	return sum
+}
+
+// subsetTag names a subset after the glyphs in it, as PDF readers expect
+// embedded subsets to be prefixed with six capital letters.
+func subsetTag(glyphs []uint16) string {
+	h := fnv.New32a()
+	for _, g := range glyphs {
+		_ = binary.Write(h, binary.BigEndian, g)
+	}
+	sum := h.Sum32()
+
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(sum%26)
+		sum /= 26
+	}
+
+	return string(tag)
+}